@@ -0,0 +1,31 @@
+package openapi
+
+import "strings"
+
+// AutoTag tags the operations without tags by the first depth segments of their path
+// during Compile, path params are skipped. A depth of 0 disables the tagging.
+//
+//	/users/{id}        → users
+//	/users/{id}/orders → users/orders with a depth of 2
+func (o *OpenAPI) AutoTag(depth int) {
+	o.autoTag = depth
+	o.invalidate()
+}
+
+// pathTag joins the first depth segments of the path that are not params
+func pathTag(path string, depth int) []string {
+	var segments []string
+	for _, s := range strings.Split(path, "/") {
+		if s == "" || regexPathParam.MatchString(s) {
+			continue
+		}
+		segments = append(segments, s)
+		if len(segments) == depth {
+			break
+		}
+	}
+	if len(segments) == 0 {
+		return nil
+	}
+	return []string{strings.Join(segments, "/")}
+}
@@ -0,0 +1,54 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestAutoTag(t *testing.T) {
+	type input struct {
+		path  string
+		depth int
+	}
+	fn := func(in input) ([]string, error) {
+		return pathTag(in.path, in.depth), nil
+	}
+	cases := trial.Cases[input, []string]{
+		"first segment": {
+			Input:    input{"/users/{id}", 1},
+			Expected: []string{"users"},
+		},
+		"depth 2": {
+			Input:    input{"/users/{id}/orders/{orderID}", 2},
+			Expected: []string{"users/orders"},
+		},
+		"shorter path": {
+			Input:    input{"/users", 3},
+			Expected: []string{"users"},
+		},
+		"root": {
+			Input:    input{"/", 1},
+			Expected: nil,
+		},
+		"param only": {
+			Input:    input{"/{id}", 1},
+			Expected: nil,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	doc := New("", "", "")
+	doc.GetRoute("/users/{id}", "get")
+	doc.GetRoute("/orders", "get").Tags("sales")
+	doc.AutoTag(1)
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(doc.Paths["/users/{id}|get"].Tag, []string{"users"}); !eq {
+		t.Error(diff)
+	}
+	if eq, diff := trial.Equal(doc.Paths["/orders|get"].Tag, []string{"sales"}); !eq {
+		t.Error(diff)
+	}
+}
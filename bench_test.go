@@ -0,0 +1,208 @@
+package openapi
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// profile is the prefix of the cpu and heap profiles written by TestProfile
+//
+//	go test -run TestProfile -profile huge && go tool pprof huge.heap.pprof
+var profile = flag.String("profile", "", "write the cpu and heap profiles of building and compiling the huge document")
+
+type benchItem struct {
+	ID      int               `json:"id" desc:"unique identifier"`
+	Name    string            `json:"name"`
+	Price   float64           `json:"price"`
+	Created time.Time         `json:"created"`
+	Tags    []string          `json:"tags"`
+	Attrs   map[string]string `json:"attrs"`
+	Parts   []benchPart       `json:"parts"`
+}
+
+type benchPart struct {
+	SKU   string `json:"sku"`
+	Count int    `json:"count"`
+}
+
+var benchSizes = []struct {
+	name   string
+	routes int
+}{
+	{"small", 10},
+	{"medium", 100},
+	{"huge", 2000},
+}
+
+// benchDoc creates a document with n routes each with a request, two responses and a param.
+func benchDoc(n int) *OpenAPI {
+	doc := New("bench", "v1", "")
+	for i := 0; i < n; i++ {
+		item := benchItem{
+			ID:    i,
+			Name:  "item",
+			Tags:  []string{"a", "b"},
+			Attrs: map[string]string{"color": "red"},
+			Parts: []benchPart{{SKU: "abc", Count: i}},
+		}
+		doc.GetRoute(fmt.Sprintf("/items/%d/{id}", i), "post").
+			PathParam("id", i, "item id").
+			AddRequest(RequestBody{}.WithExample(item)).
+			AddResponse(Response{Status: 200}.WithExample(item)).
+			AddResponse(Response{Status: 400}.WithJSONString(`{"error":"bad request","code":400}`))
+	}
+	return doc
+}
+
+func BenchmarkBuildSchema(b *testing.B) {
+	item := benchItem{Parts: []benchPart{{}}}
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buildSchema([]benchPart{})
+		}
+	})
+	b.Run("struct", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buildSchema(item)
+		}
+	})
+	b.Run("map", func(b *testing.B) {
+		m := map[string]any{"id": 1, "name": "item", "tags": []any{"a"}, "attrs": map[string]any{"color": "red"}}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buildSchema(m)
+		}
+	})
+}
+
+// BenchmarkAddExamples adds n examples to a single response, the time per example
+// stays the same as n grows since each example is added to the Media in place.
+func BenchmarkAddExamples(b *testing.B) {
+	for _, n := range []int{100, 1000, 10000} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				resp := Response{Status: 200}
+				for j := 0; j < n; j++ {
+					resp = resp.WithNamedExample("item", benchPart{Count: j})
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*n), "ns/example")
+		})
+	}
+}
+
+func BenchmarkCompile(b *testing.B) {
+	for _, size := range benchSizes {
+		b.Run(size.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				doc := benchDoc(size.routes)
+				b.StartTimer()
+				if err := doc.Compile(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkRecompile compiles the huge document again without changes,
+// the routes are only checksummed with DetectFieldChanges.
+func BenchmarkRecompile(b *testing.B) {
+	for _, detect := range []bool{false, true} {
+		b.Run(fmt.Sprintf("detect=%v", detect), func(b *testing.B) {
+			doc := benchDoc(benchSizes[len(benchSizes)-1].routes)
+			doc.DetectFieldChanges(detect)
+			if err := doc.Compile(); err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := doc.Compile(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkWriteJSON(b *testing.B) {
+	for _, size := range benchSizes {
+		doc := benchDoc(size.routes)
+		if err := doc.Compile(); err != nil {
+			b.Fatal(err)
+		}
+		b.Run(size.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(doc.Stats().Bytes))
+			for i := 0; i < b.N; i++ {
+				if err := doc.WriteJSON(io.Discard); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkJSONBytes(b *testing.B) {
+	for _, size := range benchSizes {
+		doc := benchDoc(size.routes)
+		if err := doc.Compile(); err != nil {
+			b.Fatal(err)
+		}
+		b.Run(size.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				doc.JSONBytes()
+			}
+		})
+	}
+}
+
+// TestProfile writes the profiles of building, compiling and writing the huge document
+// to the files prefixed with the -profile flag, it is skipped without the flag.
+func TestProfile(t *testing.T) {
+	if *profile == "" {
+		t.Skip("set -profile to write the profiles")
+	}
+	cpu, err := os.Create(*profile + ".cpu.pprof")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cpu.Close()
+	if err := pprof.StartCPUProfile(cpu); err != nil {
+		t.Fatal(err)
+	}
+	doc := benchDoc(benchSizes[len(benchSizes)-1].routes)
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.WriteJSON(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	pprof.StopCPUProfile()
+
+	heap, err := os.Create(*profile + ".heap.pprof")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer heap.Close()
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(heap); err != nil {
+		t.Fatal(err)
+	}
+	stats := doc.Stats()
+	t.Logf("routes %d schemas %d examples %d bytes %d", stats.Routes, stats.Schemas, stats.Examples, stats.Bytes)
+}
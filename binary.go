@@ -0,0 +1,13 @@
+package openapi
+
+// WithBinary adds a binary content of the media type to the Response, such as a zip, pdf or image download.
+// The schema is a binary string described by desc, binary content has no example.
+//
+//	Response{Status: 200, Desc: "the invoice"}.WithBinary("application/pdf", "the invoice as a pdf")
+func (r Response) WithBinary(mime MIMEType, desc string) Response {
+	if r.Content == nil {
+		r.Content = make(Content)
+	}
+	r.Content[mime] = Media{Schema: Schema{Type: String, Format: Binary, Desc: desc}}
+	return r
+}
@@ -0,0 +1,26 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestWithBinary(t *testing.T) {
+	fn := func(r Response) (string, error) {
+		b, err := json.Marshal(r.Content)
+		return string(b), err
+	}
+	cases := trial.Cases[Response, string]{
+		"pdf": {
+			Input:    Response{Status: 200}.WithBinary("application/pdf", "the invoice"),
+			Expected: `{"application/pdf":{"schema":{"type":"string","format":"binary","description":"the invoice"}}}`,
+		},
+		"several types": {
+			Input:    Response{Status: 200}.WithBinary("image/png", "").WithBinary("image/jpeg", ""),
+			Expected: `{"image/jpeg":{"schema":{"type":"string","format":"binary"}},"image/png":{"schema":{"type":"string","format":"binary"}}}`,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
@@ -4,6 +4,7 @@ package openapi
 // this needs to be put into open source so anyone can use these sdk tools to generate the openapi document
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -17,7 +18,8 @@ import (
 
 func NewFromJson(spec string) (api *OpenAPI, err error) {
 	api = &OpenAPI{
-		Paths: make(Router),
+		Paths:   make(Router),
+		schemas: newSchemaBuilder(),
 	}
 	err = json.Unmarshal([]byte(spec), &api)
 	if err != nil {
@@ -34,8 +36,9 @@ func New(title, version, description string) *OpenAPI {
 			Version: version,
 			Desc:    description,
 		},
-		Tags:  make([]Tag, 0),
-		Paths: make(Router),
+		Tags:    make([]Tag, 0),
+		Paths:   make(Router),
+		schemas: newSchemaBuilder(),
 		//ExternalDocs: &ExternalDocs{},
 	}
 }
@@ -64,15 +67,20 @@ const (
 	Array   Type = "array"
 )
 
-/*
-type Format string
+// formats of the schema types, the format is inferred from the go type
+// and can be set with the format struct tag.
 const (
-	Int32 Format = "int32"
-	Int64 Format = "int64"
-	Date   Format = "date"  // full-date - https://www.rfc-editor.org/rfc/rfc3339#section-5.6
-	DateTime Format = "datetime" // date-time - https://www.rfc-editor.org/rfc/rfc3339#section-5.6
-	Password Format = "password"
-) */
+	Int32    = "int32"
+	Int64    = "int64"
+	Float    = "float"
+	Date     = "date"      // full-date - https://www.rfc-editor.org/rfc/rfc3339#section-5.6
+	DateTime = "date-time" // date-time - https://www.rfc-editor.org/rfc/rfc3339#section-5.6
+	Password = "password"
+	UUID     = "uuid"
+	Email    = "email"
+	Byte     = "byte"   // base64 encoded characters, the encoding/json format of []byte
+	Binary   = "binary" // any sequence of octets
+)
 
 // common media types
 const (
@@ -84,19 +92,150 @@ const (
 	XForm   MIMEType = "application/x-www-form-urlencoded"
 	Jscript MIMEType = "application/javascript"
 	Form    MIMEType = "multipart/form-data"
+	Events  MIMEType = "text/event-stream"
+	NDJSON  MIMEType = "application/x-ndjson"
+	CSV     MIMEType = "text/csv"
 )
 
 func (o *OpenAPI) AddTags(t ...Tag) {
 	o.Tags = append(o.Tags, t...)
 }
 
+// DescribeTag sets the description of the tag, tags used by the operations
+// are added to the document during Compile with their description.
+func (o *OpenAPI) DescribeTag(name, desc string) {
+	for i, t := range o.Tags {
+		if t.Name == name {
+			o.Tags[i].Desc = desc
+			return
+		}
+	}
+	if o.tagDescs == nil {
+		o.tagDescs = make(map[string]string)
+	}
+	o.tagDescs[name] = desc
+}
+
+// registerTags adds the tags of the operations missing from the document tags sorted by name
+func (o *OpenAPI) registerTags() {
+	known := make(map[string]bool, len(o.Tags))
+	for _, t := range o.Tags {
+		known[t.Name] = true
+	}
+	missing := make(map[string]bool)
+	for _, routes := range []Router{o.Paths, o.Webhooks} {
+		for _, r := range routes {
+			for _, t := range r.Tag {
+				if !known[t] {
+					missing[t] = true
+				}
+			}
+		}
+	}
+	for _, name := range sortedKeys(missing) {
+		o.Tags = append(o.Tags, Tag{Name: name, Desc: o.tagDescs[name]})
+	}
+}
+
+// SchemaRef is the $ref of a schema in the components, #/components/schemas/{name}
+type SchemaRef string
+
+// RegisterSchema adds the schema built from the example to the components with the given name.
+// The returned SchemaRef can be used by responses and requests to reference the schema.
+func (o *OpenAPI) RegisterSchema(name string, example any) SchemaRef {
+	if o.Components.Schemas == nil {
+		o.Components.Schemas = make(map[string]Schema)
+	}
+	s := o.buildSchema(example)
+	s.Title = name
+	o.Components.Schemas[name] = s
+	return SchemaRef("#/components/schemas/" + name)
+}
+
 // BuildSchema will create a schema object based on a given example object interface
 // struct tag can be used for additional info
+// The schema is built with the default settings, see schemaBuilder.build.
 func buildSchema(body any) (s Schema) {
+	return defaultSchemas.build(body)
+}
+
+// build creates the schema of the body with the settings of the builder.
+// Schemas of types that do not depend on their value are cached by type.
+// Types set with OverrideSchema use the override instead of reflection
+// and a Composition is the oneOf, anyOf or allOf of the schemas of its values.
+// The schema of a json.RawMessage is built from its value, a free-form object when empty.
+// A struct that contains itself references its component schema, Compile adds the component.
+func (b *schemaBuilder) build(body any) Schema {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.schemaOf(body, nil)
+}
+
+// schemaOf creates the schema of the body, seen holds the structs being built to detect cycles.
+func (b *schemaBuilder) schemaOf(body any, seen map[reflect.Type]bool) (s Schema) {
 	if body == nil {
 		return s
 	}
+	if c, ok := body.(Composition); ok {
+		return c.schema(b)
+	}
+	if raw, ok := body.(json.RawMessage); ok {
+		return b.rawSchema(raw, seen)
+	}
+	typ := reflect.TypeOf(body)
+	if s, found := b.override(typ); found {
+		return s
+	}
+	if s, found := b.cache.load(typ); found {
+		return s
+	}
+	s = b.reflectSchema(body, seen)
+	b.cache.store(typ, s)
+	return s
+}
 
+// anyItems returns the schema of the elements of a []any, the anyOf of the distinct schemas
+// of the elements when they differ. An empty slice has an empty schema that allows any value.
+func (b *schemaBuilder) anyItems(value reflect.Value, seen map[reflect.Type]bool) Schema {
+	var schemas []Schema
+	found := make(map[string]bool)
+	for i := 0; i < value.Len(); i++ {
+		if value.Index(i).IsNil() {
+			continue
+		}
+		s := b.schemaOf(value.Index(i).Interface(), seen)
+		raw, err := json.Marshal(s)
+		if err != nil {
+			// a schema that can't be compared is kept
+			schemas = append(schemas, s)
+			continue
+		}
+		if found[string(raw)] {
+			continue
+		}
+		found[string(raw)] = true
+		schemas = append(schemas, s)
+	}
+	switch len(schemas) {
+	case 0:
+		return Schema{}
+	case 1:
+		return schemas[0]
+	}
+	return Schema{AnyOf: schemas}
+}
+
+// rawSchema creates the schema of the json value, an empty or invalid value is a free-form object.
+func (b *schemaBuilder) rawSchema(raw json.RawMessage, seen map[reflect.Type]bool) Schema {
+	var v any
+	if len(raw) == 0 || json.Unmarshal(raw, &v) != nil || v == nil {
+		return Schema{Type: Object, AdditionalProperties: true}
+	}
+	return b.schemaOf(v, seen)
+}
+
+// reflectSchema creates the schema of the body by walking through its type and value.
+func (b *schemaBuilder) reflectSchema(body any, seen map[reflect.Type]bool) (s Schema) {
 	value := reflect.ValueOf(body)
 	typ := reflect.TypeOf(body)
 	kind := typ.Kind()
@@ -110,7 +249,7 @@ func buildSchema(body any) (s Schema) {
 		kind = value.Kind()
 	}
 
-	s.Title = typ.String()
+	s.Title = b.schemaName(typ, nil)
 
 	switch kind {
 	case reflect.Map:
@@ -125,26 +264,36 @@ func buildSchema(body any) (s Schema) {
 		sKeys := make([]string, 0, len(keys))
 		for _, k := range keys {
 			sKeys = append(sKeys, k.String())
-			s.Properties[k.String()] = buildSchema(value.MapIndex(k).Interface())
+			s.Properties[k.String()] = b.schemaOf(value.MapIndex(k).Interface(), seen)
 		}
 		sort.Strings(sKeys)
-		// create a unique short, somewhat readable title
-		s.Title = hash16(strings.Join(sKeys, ""))
+		s.Title = b.schemaName(typ, sKeys)
 
 	case reflect.Struct:
 		// these are special cases for time strings
 		// that may have formatting (time.Time default is RFC3339)
-		switch value.Interface().(type) {
+		switch t := value.Interface().(type) {
 		case time.Time:
-			s.Type = String
+			s.Type, s.Format = String, DateTime
 			return s
 		case Time:
-			s.Type = String
+			s.Type, s.Format = String, timeFormat(t.Format)
 			return s
 		}
 
+		if seen[typ] {
+			// a cycle references the component schema of the struct
+			return Schema{Ref: "#/components/schemas/" + b.schemaName(typ, nil)}
+		}
+		if seen == nil {
+			seen = make(map[reflect.Type]bool)
+		}
+		seen[typ] = true
+		defer delete(seen, typ)
+
 		s.Type = Object
 		numFields := typ.NumField()
+		order := make([]string, 0, numFields)
 		if s.Properties == nil {
 			s.Properties = make(Properties)
 		}
@@ -154,7 +303,9 @@ func buildSchema(body any) (s Schema) {
 
 			jsonTag := strings.Replace(field.Tag.Get("json"), ",omitempty", "", 1)
 			desc := field.Tag.Get("desc")
-			//format := field.Tag.Get("format") // used for time string formats
+			if desc == "" {
+				desc = fieldDoc(typ, field.Name)
+			}
 
 			// skip any fields that are not exported
 			if !value.Field(i).CanInterface() || jsonTag == "-" {
@@ -167,29 +318,75 @@ func buildSchema(body any) (s Schema) {
 				varName = jsonTag
 			}
 
-			prop := buildSchema(val.Interface())
-			prop.Desc = desc
+			prop := b.schemaOf(val.Interface(), seen)
+			if desc != "" {
+				prop.Desc = desc
+			}
+			if t := localizedTag(field.Tag, "desc"); t != nil {
+				prop.Translations = t
+			}
+			if format, found := field.Tag.Lookup("format"); found {
+				prop.Format = format
+				if isTime(field.Type) { // the go layout of the time
+					prop.Format = timeFormat(format)
+				}
+			}
+			if values, found := field.Tag.Lookup("enum"); found {
+				prop.applyEnum(values)
+			}
+			tag := parseTag(field.Tag.Get("openapi"))
+			for _, k := range []string{"deprecated", "title"} {
+				if v, found := field.Tag.Lookup(k); found {
+					tag[k] = v
+				}
+			}
+			for _, k := range constraintTags {
+				if v, found := field.Tag.Lookup(k); found {
+					tag[k] = v
+				}
+			}
+			if prop.apply(tag) {
+				s.Required = append(s.Required, varName)
+			}
 			s.Properties[varName] = prop
+			order = append(order, varName)
 
 		}
+		sort.Strings(s.Required)
+		if !sort.StringsAreSorted(order) {
+			s.Order = order
+		}
+		s.Desc = typeDoc(typ)
+		s.Title = b.schemaName(typ, sortedKeys(s.Properties))
 	case reflect.Int32, reflect.Uint32:
+		return Schema{Type: Integer, Format: Int32}
+	case reflect.Int64, reflect.Uint64:
+		return Schema{Type: Integer, Format: Int64}
+	case reflect.Int, reflect.Uint, reflect.Int8, reflect.Uint8, reflect.Int16, reflect.Uint16:
 		return Schema{Type: Integer}
-	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
-		return Schema{Type: Integer}
-	case reflect.Float32, reflect.Float64:
+	case reflect.Float32:
+		return Schema{Type: Number, Format: Float}
+	case reflect.Float64:
 		return Schema{Type: Number}
 	case reflect.Bool:
 		return Schema{Type: Boolean}
 	case reflect.String:
 		return Schema{Type: String}
 	case reflect.Slice, reflect.Array:
+		if kind == reflect.Slice && typ.Elem().Kind() == reflect.Uint8 {
+			return Schema{Type: String, Format: b.bytesFormat()}
+		}
 		if k := typ.Elem().Kind(); k == reflect.Interface {
-			// todo: We have a anyOf array
+			items := b.anyItems(value, seen)
+			return Schema{
+				Type:  Array,
+				Items: &items,
+			}
 		} else if k == reflect.Map || k == reflect.Struct ||
 			k == reflect.Array || k == reflect.Slice {
 			// check the type of the first element of the array if it exists
 			if value.Len() > 0 && value.IsValid() {
-				prop := buildSchema(value.Index(0).Interface())
+				prop := b.schemaOf(value.Index(0).Interface(), seen)
 				return Schema{
 					Type:  Array,
 					Items: &prop,
@@ -199,7 +396,7 @@ func buildSchema(body any) (s Schema) {
 
 		// since the slice may be empty, create the child object to determine its type.
 		child := reflect.New(typ.Elem()).Elem().Interface()
-		prop := buildSchema(child)
+		prop := b.schemaOf(child, seen)
 		return Schema{
 			Type:  Array,
 			Items: &prop,
@@ -211,63 +408,515 @@ func buildSchema(body any) (s Schema) {
 	return s
 }
 
+// SetBytesFormat sets the format of the string schema of the []byte values of the document,
+// Byte (base64) by default as written by encoding/json or Binary for raw octets.
+// It must be set before the routes are added.
+func (o *OpenAPI) SetBytesFormat(format string) {
+	b := o.schemaBuilder()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bytes = format
+	b.cache.clear()
+}
+
+// bytesFormat returns the format of the []byte values
+func (b *schemaBuilder) bytesFormat() string {
+	if b.bytes == "" {
+		return Byte
+	}
+	return b.bytes
+}
+
+// timeFormat returns the format of a time written with the go layout,
+// layouts other than a date or RFC 3339 have no format.
+func timeFormat(layout string) string {
+	switch layout {
+	case time.DateOnly:
+		return Date
+	case "", time.RFC3339, time.RFC3339Nano:
+		return DateTime
+	}
+	return ""
+}
+
+// isTime reports if t is a time.Time or Time
+func isTime(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t == reflect.TypeOf(time.Time{}) || t == reflect.TypeOf(Time{})
+}
+
+// NamingFunc creates the title of an object schema from its type and sorted property keys,
+// the title is used as the name of the schema in the components.
+type NamingFunc func(t reflect.Type, keys []string) string
+
+// DefaultNaming is the default NamingFunc. Types are named by their package and name
+// (openapi.User), maps by a hash of their keys.
+func DefaultNaming(t reflect.Type, keys []string) string {
+	if t.Kind() == reflect.Map && len(keys) > 0 {
+		// create a unique short, somewhat readable title
+		return hash16(strings.Join(keys, ""))
+	}
+	return t.String()
+}
+
+// ShortNaming is a NamingFunc that names types without their package (User),
+// other schemas are named by DefaultNaming.
+func ShortNaming(t reflect.Type, keys []string) string {
+	if t.Name() != "" {
+		return t.Name()
+	}
+	return DefaultNaming(t, keys)
+}
+
+// SetNamingFunc replaces the NamingFunc used to title the schemas of the document.
+// A route builds the schemas of its examples with it when they are added,
+// so it must be set before the routes are added. A nil NamingFunc restores DefaultNaming.
+//
+//	doc.SetNamingFunc(openapi.ShortNaming)
+func (o *OpenAPI) SetNamingFunc(fn NamingFunc) {
+	b := o.schemaBuilder()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.naming = fn
+	b.cache.clear()
+}
+
+// SchemaNamer is implemented by types that name their own schema,
+// the name is used instead of the NamingFunc as the title and component name.
+//
+//	func (createUser) SchemaName() string { return "UserRequest" }
+type SchemaNamer interface {
+	SchemaName() string
+}
+
+// schemaName returns the title of the schema of type t with the sorted property keys
+func (b *schemaBuilder) schemaName(t reflect.Type, keys []string) string {
+	if t.Kind() == reflect.Struct || t.Kind() == reflect.Map {
+		if n, ok := reflect.New(t).Interface().(SchemaNamer); ok {
+			return n.SchemaName()
+		}
+	}
+	if b.naming != nil {
+		return b.naming(t, keys)
+	}
+	if t.Kind() == reflect.Map && len(keys) > 0 {
+		return b.hash(t, keys)
+	}
+	return t.String()
+}
+
+// Hasher creates a checksum of the data provided.
+// It is used to create the title of map schemas from their sorted keys.
+type Hasher func(data []byte) uint64
+
+var crcTable = crc64.MakeTable(crc64.ISO)
+
+// crcHash is the default Hasher using crc64 with the ISO polynomial
+func crcHash(data []byte) uint64 {
+	return crc64.Checksum(data, crcTable)
+}
+
+// SetHasher replaces the Hasher used to title the map schemas of the document (crc64 by default)
+// when they are named by the default NamingFunc. A faster non-cryptographic hash such as xxhash
+// can be used for large documents. It must be set before the routes are added, a nil Hasher restores the default.
+func (o *OpenAPI) SetHasher(h Hasher) {
+	b := o.schemaBuilder()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.hasher = h
+	b.cache.clear()
+	b.titleMu.Lock()
+	b.titles = make(map[hashKey]string)
+	b.titleMu.Unlock()
+}
+
+// hashKey is a map type with its sorted keys joined together
+type hashKey struct {
+	t    reflect.Type
+	keys string
+}
+
+// hash returns the title of the map type t with the sorted keys. A document keeps the titles
+// by type and keys so the keys of a type are only hashed once.
+func (b *schemaBuilder) hash(t reflect.Type, keys []string) string {
+	k := hashKey{t: t, keys: strings.Join(keys, "")}
+	if b.titles == nil { // the default builder does not keep the titles
+		return hash16(k.keys)
+	}
+	b.titleMu.Lock()
+	defer b.titleMu.Unlock()
+	if title, found := b.titles[k]; found {
+		return title
+	}
+	h := b.hasher
+	if h == nil {
+		h = crcHash
+	}
+	title := fmt.Sprintf("%x", h([]byte(k.keys)))
+	b.titles[k] = title
+	return title
+}
+
 // hash16 creates 16 character checksum on the string provided.
 func hash16(s string) string {
-	hasher := crc64.New(crc64.MakeTable(crc64.ISO))
-	hasher.Write([]byte(s))
-	return fmt.Sprintf("%x", hasher.Sum64())
+	return fmt.Sprintf("%x", crcHash([]byte(s)))
 }
 
 // Compile the OpenAPI object by going through all
 // objects and consolidating schemas and return a
-// error of issues found
-func (o *OpenAPI) Compile() error {
+// error of issues found.
+// Only routes that changed since the last call to Compile are processed,
+// routes with errors are processed again on the next call. A direct write to the fields
+// of a route is only found with DetectFieldChanges.
+// The examples are only sanitized once so compiling again does not change them.
+// Issues that do not make the document invalid, such as routes without a success
+// or default response, are not errors and are returned by Warnings.
+// The processors registered with Use run after the routes are compiled
+// and the tags used by the operations are added to the document tags.
+// Any options are run in order on the whole document after the routes are compiled.
+func (o *OpenAPI) Compile(opts ...CompileOption) error {
 	if o.Components.Schemas == nil {
 		o.Components.Schemas = make(map[string]Schema)
 	}
+	if o.fieldChanges {
+		o.detectFieldChanges()
+	}
+	o.applySchemaNames()
+	if o.headOptions {
+		o.generateHeadOptions()
+	}
+	if len(o.globalParams) > 0 {
+		o.applyGlobalParams()
+	}
+	if len(o.defaultHeaders) > 0 {
+		o.applyDefaultHeaders()
+	}
+	if o.autoOperationIDs {
+		o.generateOperationIDs()
+	}
+	errs := o.compileRoutes()
+	if len(o.processors) > 0 {
+		for _, p := range o.processors {
+			errs = errors.Join(errs, p.Process(o))
+		}
+		// routes added or changed by the processors
+		errs = errors.Join(errs, o.compileRoutes())
+	}
+	if o.resolved {
+		o.compactRefs()
+	}
+	o.registerTags()
+	o.warnings = o.checkWarnings()
+	o.sanitizeExamples("", "", o.Components.Examples)
+	o.redactExamples(o.Components.Examples)
+	for _, opt := range opts {
+		errs = errors.Join(errs, opt(o))
+	}
+	return errs
+}
+
+// compileRoutes compiles the routes and webhooks that changed since the last call
+func (o *OpenAPI) compileRoutes() error {
 	var errs error
+	// in order so the first route using a schema is the same on every run
+	for _, k := range sortedKeys(o.Paths) {
+		r := o.Paths[k]
+		if !r.pending() {
+			continue
+		}
+		if o.autoTag > 0 && len(r.Tag) == 0 {
+			r.Tag = pathTag(r.path, o.autoTag)
+		}
+		err := o.compileRoute(r)
+		o.setCompiled(r, err)
+		errs = errors.Join(errs, err)
+	}
+	for _, k := range sortedKeys(o.Webhooks) {
+		r := o.Webhooks[k]
+		if !r.pending() {
+			continue
+		}
+		err := o.compileRoute(r)
+		o.setCompiled(r, err)
+		errs = errors.Join(errs, err)
+	}
+	return errs
+}
+
+// CompileOption is an optional step of Compile that is applied to the whole document.
+type CompileOption func(o *OpenAPI) error
+
+// Warnings returns the issues found by the last call to Compile that do not make
+// the document invalid, such as an operation without a success or default response
+// which UIs render empty.
+//
+//	if err := doc.Compile(); err != nil {
+//		return err
+//	}
+//	for _, w := range doc.Warnings() {
+//		log.Println("warning:", w)
+//	}
+func (o *OpenAPI) Warnings() []string {
+	return o.warnings
+}
+
+// ReportWarnings is a CompileOption that calls fn with each of the Warnings of Compile.
+//
+//	doc.Compile(openapi.ReportWarnings(func(w string) { log.Println("warning:", w) }))
+func ReportWarnings(fn func(warning string)) CompileOption {
+	return func(o *OpenAPI) error {
+		for _, w := range o.warnings {
+			fn(w)
+		}
+		return nil
+	}
+}
+
+// checkWarnings returns the issues of the document that do not make it invalid
+func (o *OpenAPI) checkWarnings() []string {
+	var warnings []string
+	for _, k := range sortedKeys(o.Paths) {
+		r := o.Paths[k]
+		if !hasSuccess(r.Responses) {
+			warnings = append(warnings, fmt.Sprintf("%v %v has no success or default response", r.method, r.path))
+		}
+	}
+	return warnings
+}
+
+// invalidate marks all routes to be processed on the next Compile,
+// it is used when a setting of the document changes.
+func (o *OpenAPI) invalidate() {
 	for _, r := range o.Paths {
-		if r.Requests != nil {
-			for k, c := range r.Requests.Content {
-				if k == "invalid/json" {
-					errs = errors.Join(errs, fmt.Errorf("invalid json %v request at %v: %q", r.method, r.path, c.Examples["invalid"].Value))
-					continue
-				}
-				if c.Schema.Type != Object {
-					continue
-				}
-				if _, found := o.Components.Schemas[c.Schema.Title]; !found {
-					o.Components.Schemas[c.Schema.Title] = c.Schema
-				}
-				c.Schema = Schema{Ref: "#/components/schemas/" + c.Schema.Title}
-				r.Requests.Content[k] = c
+		r.compiled = false
+	}
+	for _, r := range o.Webhooks {
+		r.compiled = false
+	}
+}
+
+// hasSuccess reports if a 1xx, 2xx, 3xx or default response is documented,
+// operations without one render empty in UIs.
+func hasSuccess(resps map[Code]Response) bool {
+	for code := range resps {
+		if code < 400 {
+			return true
+		}
+	}
+	return false
+}
+
+// compileRoute moves the object schemas of the route into the
+// components and returns any issues found on the route.
+func (o *OpenAPI) compileRoute(r *Route) error {
+	var errs error
+	if r.Requests != nil {
+		use := SchemaUse{Path: r.path, Method: r.method, Request: true}
+		errs = errors.Join(errs, o.compileContent(use, r.Requests.Content, fmt.Sprintf("%v request at %v", r.method, r.path)))
+	}
+	for _, code := range sortedCodes(r.Responses) {
+		resp := r.Responses[code]
+		use := SchemaUse{Path: r.path, Method: r.method, Status: resp.Status}
+		errs = errors.Join(errs, o.compileContent(use, resp.Content, fmt.Sprintf("%v response at %v", r.method, r.path)))
+		for name, h := range resp.Headers {
+			if strings.Contains(h.Desc, "err:") {
+				errs = errors.Join(errs, fmt.Errorf("%v response at %v header %v| %v", r.method, r.path, name, h.Desc))
+			}
+			if err := o.checkHeaderRef(h); err != nil {
+				errs = errors.Join(errs, fmt.Errorf("%v response at %v: %w", r.method, r.path, err))
 			}
 		}
-		for _, resp := range r.Responses {
-			for k, c := range resp.Content {
-				if k == "invalid/json" {
-					errs = errors.Join(errs, fmt.Errorf("invalid json %v response at %v: %q", r.method, r.path, c.Examples["invalid"].Value))
-					continue
-				}
-				if c.Schema.Type != Object {
-					continue
-				}
-				if _, found := o.Components.Schemas[c.Schema.Title]; !found {
-					o.Components.Schemas[c.Schema.Title] = c.Schema
+	}
+
+	for _, name := range sortedKeys(r.Callbacks) {
+		for _, cb := range r.Callbacks[name] {
+			err := o.compileRoute(cb)
+			o.setCompiled(cb, err)
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	for k, p := range r.Params {
+		if o.exampleSanitizer != nil && len(p.Examples) > 0 {
+			o.sanitizeExamples(r.path, p.Name, p.Examples)
+			r.Params[k] = p
+		}
+		for mime, m := range p.Content {
+			o.sanitizeExamples(r.path, p.Name, m.Examples)
+			o.redactExamples(m.Examples)
+			o.redactSchema(&m.Schema, "")
+			p.Content[mime] = m
+		}
+		if strings.Contains(p.Desc, "err:") {
+			errs = errors.Join(errs, fmt.Errorf("%v param %v| %v", p.In, p.Name, p.Desc))
+		}
+		if err := o.checkParamRef(p); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("%v %v: %w", r.method, r.path, err))
+		}
+	}
+	return errs
+}
+
+// compileContent updates each Media of the content and moves object schemas into the components
+// named by the NamingStrategy. use is the route of the content and at describes it in errors.
+func (o *OpenAPI) compileContent(use SchemaUse, content Content, at string) error {
+	var errs error
+	for k, c := range content {
+		if k == "invalid/json" {
+			errs = errors.Join(errs, fmt.Errorf("invalid json %v: %q", at, c.Examples["invalid"].Value))
+			continue
+		}
+		if err := o.resolveExampleRefs(&c); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("%v: %w", at, err))
+		}
+		o.sanitizeExamples(use.Path, "", c.Examples)
+		o.redactExamples(c.Examples)
+		o.redactSchema(&c.Schema, "")
+		o.limitExamples(&c)
+		c.Schema = o.cycleComponents(c.Schema)
+		if len(o.resolvedSchemas) > 0 {
+			var err error
+			if c.Schema, err = o.resolvedRefs(c.Schema, at); err != nil {
+				errs = errors.Join(errs, err)
+			}
+		}
+		if c.Schema.Type == Object && o.namingStrategy != nil && !o.resolvedSchemas[c.Schema.Title] {
+			// names set with SetSchemaName take precedence
+			if _, found := o.schemaName(c.Schema.Title); !found {
+				use.MIME, use.Schema = k, c.Schema
+				c.Schema.Title = o.namingStrategy(use)
+			}
+		}
+		var err error
+		if c.Schema, err = o.componentSchema(c.Schema, at); err != nil {
+			errs = errors.Join(errs, err)
+		}
+		// the object schemas of a composition are moved to the components as well
+		for _, l := range []*[]Schema{&c.Schema.OneOf, &c.Schema.AnyOf, &c.Schema.AllOf} {
+			if *l == nil {
+				continue
+			}
+			schemas := make([]Schema, len(*l))
+			for i, s := range *l {
+				if schemas[i], err = o.componentSchema(s, at); err != nil {
+					errs = errors.Join(errs, err)
 				}
-				c.Schema = Schema{Ref: "#/components/schemas/" + c.Schema.Title}
-				resp.Content[k] = c
 			}
+			*l = schemas
 		}
+		content[k] = c
+	}
+	return errs
+}
 
-		for _, p := range r.Params {
-			if strings.Contains(p.Desc, "err:") {
-				errs = errors.Join(errs, fmt.Errorf("%v param %v| %v", p.In, p.Name, p.Desc))
+// cycleComponents adds the structs that reference themselves to the components,
+// buildSchema writes a cycle as a $ref to the component of the struct.
+// The components and the refs use the names set with SetSchemaName.
+func (o *OpenAPI) cycleComponents(schema Schema) Schema {
+	refs := make(map[string]bool)
+	nested := make(map[string]Schema)
+	var walk func(s Schema)
+	walk = func(s Schema) {
+		if name, found := strings.CutPrefix(s.Ref, "#/components/schemas/"); found {
+			refs[name] = true
+		}
+		if s.Type == Object && s.Title != "" {
+			if _, found := nested[s.Title]; !found {
+				nested[s.Title] = s
 			}
+		}
+		if s.Items != nil {
+			walk(*s.Items)
+		}
+		for _, k := range sortedKeys(s.Properties) {
+			walk(s.Properties[k])
+		}
+		for _, l := range [][]Schema{s.OneOf, s.AnyOf, s.AllOf} {
+			for _, v := range l {
+				walk(v)
+			}
+		}
+	}
+	walk(schema)
+	renames := make(map[string]string)
+	for title := range refs {
+		if name, found := o.schemaName(title); found {
+			renames[title] = name
+		}
+	}
+	for title := range refs {
+		name := title
+		if n, found := renames[title]; found {
+			name = n
+		}
+		if _, found := o.Components.Schemas[name]; found {
+			continue
+		}
+		if s, found := nested[title]; found {
+			s = s.clone()
+			s.Title = name
+			renameRefs(&s, renames)
+			o.Components.Schemas[name] = s
+		}
+	}
+	if len(renames) > 0 {
+		schema = schema.clone()
+		renameRefs(&schema, renames)
+	}
+	return schema
+}
 
+// componentSchema adds the object schema to the components by its title and returns a $ref to it,
+// other schemas are returned unchanged. A different object with the title of a component,
+// such as two structs with the same name in different packages, is an error.
+func (o *OpenAPI) componentSchema(schema Schema, at string) (Schema, error) {
+	if name, found := o.schemaName(schema.Title); found {
+		schema.Title = name
+	}
+	resolved := o.resolvedSchemas[schema.Title]
+	// objects without a name, such as a multipart body, are kept inline
+	if (schema.Type != Object || schema.Title == "") && !resolved {
+		return schema, nil
+	}
+	ref := Schema{Ref: "#/components/schemas/" + schema.Title}
+	if o.schemaOrigins == nil {
+		o.schemaOrigins = make(map[string]string)
+	}
+	if s, found := o.Components.Schemas[schema.Title]; !found {
+		o.Components.Schemas[schema.Title] = schema
+		o.schemaOrigins[schema.Title] = at
+	} else if resolved && !sameSchema(s, schema) {
+		// the schema inlined by Resolve was changed
+		o.Components.Schemas[schema.Title] = schema
+	} else if !resolved && !sameShape(s, schema) {
+		origin := o.schemaOrigins[schema.Title]
+		if origin == "" {
+			origin = "the components"
 		}
+		return ref, fmt.Errorf("schema %q of %v conflicts with the schema of %v", schema.Title, at, origin)
+	} else if s.XML == nil && schema.XML != nil {
+		// the schema of an xml representation names the root element
+		s.XML = schema.XML
+		o.Components.Schemas[schema.Title] = s
 	}
-	return errs
+	return ref, nil
+}
+
+// sameShape reports if the object schemas have the same properties with the same types,
+// the descriptions, examples and the properties of nested objects are not compared.
+func sameShape(a, b Schema) bool {
+	if a.Type != b.Type || len(a.Properties) != len(b.Properties) {
+		return false
+	}
+	for k, p := range a.Properties {
+		q, found := b.Properties[k]
+		if !found || p.Type != q.Type || p.Ref != q.Ref {
+			return false
+		}
+	}
+	return true
 }
 
 // JSON returns the json string value for the OpenAPI object
@@ -275,10 +924,11 @@ func (o *OpenAPI) JSON() string {
 	return string(o.JSONBytes())
 }
 
+// JSONBytes returns the indented json value for the OpenAPI object
 func (o *OpenAPI) JSONBytes() []byte {
-	b, err := json.MarshalIndent(o, "", "    ")
-	if err != nil {
+	var buf bytes.Buffer
+	if err := o.WriteJSON(&buf); err != nil {
 		log.Println(err)
 	}
-	return b
+	return buf.Bytes()
 }
@@ -2,14 +2,23 @@ package openapi
 
 import (
 	_ "embed"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/hydronica/trial"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
 
+type rawPayload struct {
+	Data  json.RawMessage `json:"data"`
+	Empty json.RawMessage `json:"empty"`
+}
+
 func TestBuildSchema(t *testing.T) {
 	type Primitives struct {
 		Int    int `json:"custom_int"`
@@ -102,6 +111,7 @@ func TestBuildSchema(t *testing.T) {
 							"Bool":       {Type: Boolean},
 							"Number":     {Type: Number},
 						},
+						Order: []string{"custom_int", "String", "Bool", "Number"},
 					},
 				},
 			},
@@ -129,8 +139,9 @@ func TestBuildSchema(t *testing.T) {
 			Input: trial.TimeDay("2023-01-11"),
 
 			Expected: Schema{
-				Title: "time.Time",
-				Type:  "string",
+				Title:  "time.Time",
+				Type:   "string",
+				Format: DateTime,
 			},
 		},
 		"simple_object": {
@@ -144,6 +155,7 @@ func TestBuildSchema(t *testing.T) {
 					"Bool":       {Type: Boolean},
 					"Number":     {Type: Number},
 				},
+				Order: []string{"custom_int", "String", "Bool", "Number"},
 			},
 		},
 		"object_within_object": {
@@ -226,11 +238,156 @@ func TestBuildSchema(t *testing.T) {
 				},
 			},
 		},
-		/*"any_array": {
-			Input: []any{"eholo", struct{ Name string }{Name: "abc"}},
-		}, */
+		"raw_message": {
+			Input: rawPayload{Data: json.RawMessage(`{"id":1}`)},
+			Expected: Schema{
+				Title: "openapi.rawPayload",
+				Type:  Object,
+				Properties: map[string]Schema{
+					"data":  {Title: "3369a00000000000", Type: Object, Properties: map[string]Schema{"id": {Type: Number}}},
+					"empty": {Type: Object, AdditionalProperties: true},
+				},
+			},
+		},
+		"any_array": {
+			Input: []any{"eholo", struct{ Name string }{Name: "abc"}, "other", 12},
+			Expected: Schema{
+				Type: Array,
+				Items: &Schema{AnyOf: []Schema{
+					{Type: String},
+					{Title: "struct { Name string }", Type: Object, Properties: map[string]Schema{"Name": {Type: String}}},
+					{Type: Integer},
+				}},
+			},
+		},
+		"same_any_array": {
+			Input:    []any{"a", "b"},
+			Expected: Schema{Type: Array, Items: &Schema{Type: String}},
+		},
+		"empty_any_array": {
+			Input:    []any{},
+			Expected: Schema{Type: Array, Items: &Schema{}},
+		},
+	}
+
+	trial.New(fn, cases).SubTest(t)
+}
+
+func TestFormat(t *testing.T) {
+	type event struct {
+		ID      string    `json:"id" format:"uuid"`
+		Email   string    `json:"email" openapi:"format=email"`
+		Day     time.Time `json:"day" format:"2006-01-02"`
+		Stamp   time.Time `json:"stamp" format:"15:04"`
+		Created time.Time `json:"created"`
+		Updated Time      `json:"updated"`
+		Seq     int64     `json:"seq"`
+		Code    uint32    `json:"code"`
+		Count   int       `json:"count"`
+		Ratio   float32   `json:"ratio"`
+	}
+	s := buildSchema(event{Updated: Time{Format: time.DateOnly}})
+	eq, diff := trial.Equal(s.Properties, map[string]Schema{
+		"id":      {Type: String, Format: UUID},
+		"email":   {Type: String, Format: Email},
+		"day":     {Type: String, Title: "time.Time", Format: Date},
+		"stamp":   {Type: String, Title: "time.Time"},
+		"created": {Type: String, Title: "time.Time", Format: DateTime},
+		"updated": {Type: String, Title: "openapi.Time", Format: Date},
+		"seq":     {Type: Integer, Format: Int64},
+		"code":    {Type: Integer, Format: Int32},
+		"count":   {Type: Integer},
+		"ratio":   {Type: Number, Format: Float},
+	})
+	if !eq {
+		t.Error(diff)
+	}
+}
+
+type node struct {
+	Name     string  `json:"name"`
+	Children []*node `json:"children"`
+	Parent   *node   `json:"parent"`
+}
+
+type tree struct {
+	Root node `json:"root"`
+}
+
+func TestSetBytesFormat(t *testing.T) {
+	type file struct {
+		Data []byte  `json:"data"`
+		Hash [4]byte `json:"hash"`
+	}
+	fn := func(format string) (map[string]Schema, error) {
+		doc := New("", "", "")
+		if format != "" {
+			doc.SetBytesFormat(format)
+		}
+		doc.GetRoute("/files", "post").AddRequest(RequestBody{}.WithExample(file{}))
+		err := doc.Compile()
+		return doc.Components.Schemas["openapi.file"].Properties, err
+	}
+	cases := trial.Cases[string, map[string]Schema]{
+		"default": {
+			Input: "",
+			Expected: map[string]Schema{
+				"data": {Type: String, Format: Byte},
+				"hash": {Type: Array, Items: &Schema{Type: Integer}},
+			},
+		},
+		"base64": {
+			Input: Byte,
+			Expected: map[string]Schema{
+				"data": {Type: String, Format: Byte},
+				"hash": {Type: Array, Items: &Schema{Type: Integer}},
+			},
+		},
+		"binary": {
+			Input: Binary,
+			Expected: map[string]Schema{
+				"data": {Type: String, Format: Binary},
+				"hash": {Type: Array, Items: &Schema{Type: Integer}},
+			},
+		},
 	}
+	trial.New(fn, cases).SubTest(t)
+}
 
+func TestCycles(t *testing.T) {
+	nodeSchema := Schema{
+		Title: "openapi.node",
+		Type:  Object,
+		Properties: map[string]Schema{
+			"name":     {Type: String},
+			"children": {Type: Array, Items: &Schema{Ref: "#/components/schemas/openapi.node"}},
+			"parent":   {Ref: "#/components/schemas/openapi.node"},
+		},
+		Order: []string{"name", "children", "parent"},
+	}
+	fn := func(example any) (map[string]Schema, error) {
+		doc := New("", "", "")
+		doc.GetRoute("/nodes", "get").AddResponse(Response{Status: 200}.WithExample(example))
+		err := doc.Compile()
+		return doc.Components.Schemas, err
+	}
+	cases := trial.Cases[any, map[string]Schema]{
+		"self": {
+			Input:    node{Name: "root", Children: []*node{{Name: "leaf"}}},
+			Expected: map[string]Schema{"openapi.node": nodeSchema},
+		},
+		"nested": {
+			Input: tree{},
+			Expected: map[string]Schema{
+				"openapi.node": nodeSchema,
+				"openapi.tree": {Title: "openapi.tree", Type: Object, Properties: map[string]Schema{"root": nodeSchema}},
+			},
+		},
+		"array": {
+			Input:    []node{{Name: "root"}},
+			Expected: map[string]Schema{"openapi.node": nodeSchema},
+		},
+	}
 	trial.New(fn, cases).SubTest(t)
 }
 
@@ -275,9 +432,10 @@ func TestCompile(t *testing.T) {
 						Type:  Object,
 						Properties: map[string]Schema{
 							"Count": {Type: Integer},
-							"Date":  {Type: String, Title: "time.Time"},
+							"Date":  {Type: String, Title: "time.Time", Format: DateTime},
 							"Price": {Type: Number},
 						},
+						Order: []string{"Date", "Price", "Count"},
 					}},
 				},
 			},
@@ -304,9 +462,10 @@ func TestCompile(t *testing.T) {
 						Type:  Object,
 						Properties: map[string]Schema{
 							"Count": {Type: Integer},
-							"Date":  {Type: String, Title: "time.Time"},
+							"Date":  {Type: String, Title: "time.Time", Format: DateTime},
 							"Price": {Type: Number},
 						},
+						Order: []string{"Date", "Price", "Count"},
 					}},
 				},
 			},
@@ -322,8 +481,8 @@ func TestCompile(t *testing.T) {
 			ExpectedErr: errors.New(`invalid json get response at test: "invalid"`),
 		},
 		"param-error": {
-			Input:       (&Route{path: "test", method: "get"}).AddParam("query", "name", abc{}, ""),
-			ExpectedErr: errors.New("query param name| err"),
+			Input:       (&Route{path: "test", method: "get"}).AddParam("header", "name", abc{}, ""),
+			ExpectedErr: errors.New("header param name| err"),
 		},
 	}
 	ignoreExamples := func(_ any) cmp.Option {
@@ -336,3 +495,239 @@ func TestCompile(t *testing.T) {
 			ignoreExamples,
 		)).SubTest(t)
 }
+
+func TestCompileIncremental(t *testing.T) {
+	doc := New("", "", "")
+	doc.GetRoute("/a", "get").AddResponse(Response{Status: 200}.WithExample(struct{ Name string }{}))
+	b := doc.GetRoute("/b", "get").AddResponse(Response{Status: 400}.WithJSONString("invalid"))
+
+	if err := doc.Compile(); err == nil {
+		t.Fatal("expected error for invalid json")
+	}
+	// routes with errors are compiled again
+	if err := doc.Compile(); err == nil {
+		t.Fatal("expected error to be reported again")
+	}
+
+	b.AddResponse(Response{Status: 400}.WithJSONString(`{"error":"bad request"}`))
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a direct write to the fields of a route is only found with DetectFieldChanges
+	a := doc.GetRoute("/a", "get")
+	a.Responses[500] = Response{Status: 500}.WithJSONString("invalid")
+	if err := doc.Compile(); err != nil {
+		t.Fatalf("unexpected compile of a route written directly: %v", err)
+	}
+	doc.DetectFieldChanges(true)
+	if err := doc.Compile(); err == nil {
+		t.Fatal("expected changed route to be compiled")
+	}
+	type apiError struct{ Code int }
+	a.Responses[500] = Response{Status: 500}.WithExample(apiError{Code: 500})
+	a.Desc = "get a"
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if l := len(doc.Components.Schemas); l != 3 {
+		t.Errorf("expected 3 schemas got %d", l)
+	}
+	if ref := a.Responses[500].Content[Json].Schema.Ref; ref != "#/components/schemas/openapi.apiError" {
+		t.Errorf("unexpected ref %q", ref)
+	}
+
+	// unchanged routes are skipped
+	b.compiled = false
+	b.Responses[400] = Response{Status: 400}.WithJSONString("invalid")
+	doc.setCompiled(b, nil)
+	if err := doc.Compile(); err != nil {
+		t.Fatalf("unexpected compile of unchanged route: %v", err)
+	}
+}
+
+func TestSetHasher(t *testing.T) {
+	m := map[string]int{"b": 2, "a": 1}
+	if title := buildSchema(m).Title; title != hash16("ab") {
+		t.Errorf("unexpected default title %q", title)
+	}
+
+	doc := New("", "", "")
+	var calls int
+	doc.SetHasher(func(data []byte) uint64 {
+		calls++
+		return uint64(len(data))
+	})
+	for _, path := range []string{"/a", "/b", "/c"} {
+		doc.GetRoute(path, "get").AddResponse(Response{Status: 200}.WithExample(m))
+	}
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(sortedKeys(doc.Components.Schemas), []string{"2"}); !eq {
+		t.Errorf("expected custom hasher title %v", diff)
+	}
+	if calls != 1 {
+		t.Errorf("expected keys to be hashed once got %d", calls)
+	}
+
+	// the hasher of a document is not used by the others
+	if title := New("", "", "").buildSchema(map[string]string{"key": "value"}).Title; title != "2292dac000000000" {
+		t.Errorf("expected default hasher title got %q", title)
+	}
+}
+
+func TestRegisterSchema(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	doc := New("", "", "")
+	ref := doc.RegisterSchema("User", user{})
+	doc.GetRoute("/users", "get").
+		AddResponse(Response{Status: 200}.WithSchemaRef(ref).WithExample(user{Name: "bob"}))
+	doc.GetRoute("/users", "post").
+		AddRequest(RequestBody{}.WithExample(user{Name: "bob"}).WithSchemaRef(ref))
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	eq, diff := trial.Equal(doc.Components.Schemas, map[string]Schema{
+		"User": {
+			Title:      "User",
+			Type:       Object,
+			Properties: map[string]Schema{"name": {Type: String}},
+		},
+	})
+	if !eq {
+		t.Error(diff)
+	}
+	resp := doc.GetRoute("/users", "get").Responses[200].Content[Json]
+	if resp.Schema.Ref != "#/components/schemas/User" || len(resp.Examples) != 1 {
+		t.Errorf("unexpected response media %v", resp)
+	}
+	if s := doc.GetRoute("/users", "post").Requests.Content[Json].Schema; s.Ref != "#/components/schemas/User" {
+		t.Errorf("unexpected request schema %v", s)
+	}
+}
+
+type createUser struct {
+	Name string `json:"name"`
+}
+
+func (*createUser) SchemaName() string { return "UserRequest" }
+
+func TestSchemaTitle(t *testing.T) {
+	type account struct {
+		Owner   createUser            `json:"owner"`
+		Address struct{ City string } `json:"address" title:"Address"`
+	}
+	doc := New("", "", "")
+	doc.GetRoute("/accounts", "post").
+		AddRequest(RequestBody{}.WithExample(createUser{Name: "bob"})).
+		AddResponse(Response{Status: 201}.WithExample(account{}))
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(sortedKeys(doc.Components.Schemas), []string{"UserRequest", "openapi.account"}); !eq {
+		t.Error(diff)
+	}
+	props := doc.Components.Schemas["openapi.account"].Properties
+	if eq, diff := trial.Equal([]string{props["owner"].Title, props["address"].Title}, []string{"UserRequest", "Address"}); !eq {
+		t.Error(diff)
+	}
+}
+
+func TestSchemaCollision(t *testing.T) {
+	users := func() any {
+		type Response struct {
+			Users []string `json:"users"`
+		}
+		return Response{}
+	}
+	orders := func() any {
+		type Response struct {
+			Orders []int `json:"orders"`
+		}
+		return Response{}
+	}
+	fn := func(examples []any) (any, error) {
+		doc := New("", "", "")
+		doc.SetNamingFunc(ShortNaming)
+		for i, ex := range examples {
+			doc.GetRoute(fmt.Sprintf("/r%d", i), "get").AddResponse(Response{Status: 200}.WithExample(ex))
+		}
+		return nil, doc.Compile()
+	}
+	cases := trial.Cases[[]any, any]{
+		"same type": {
+			Input: []any{users(), users()},
+		},
+		"same name": {
+			Input:       []any{users(), orders()},
+			ExpectedErr: errors.New(`schema "Response" of get response at /r1 conflicts with the schema of get response at /r0`),
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
+func TestNamingFunc(t *testing.T) {
+	type User struct {
+		Name string `json:"name"`
+		ID   int    `json:"id"`
+	}
+
+	fn := func(in NamingFunc) ([]string, error) {
+		doc := New("", "", "")
+		doc.SetNamingFunc(in)
+		doc.GetRoute("/users", "get").AddResponse(Response{Status: 200}.WithExample(User{}))
+		err := doc.Compile()
+		return sortedKeys(doc.Components.Schemas), err
+	}
+	cases := trial.Cases[NamingFunc, []string]{
+		"default": {
+			Input:    nil,
+			Expected: []string{"openapi.User"},
+		},
+		"short": {
+			Input:    ShortNaming,
+			Expected: []string{"User"},
+		},
+		"versioned": {
+			Input: func(t reflect.Type, keys []string) string {
+				return t.Name() + "V2"
+			},
+			Expected: []string{"UserV2"},
+		},
+		"keys": {
+			Input: func(t reflect.Type, keys []string) string {
+				return strings.Join(keys, "_")
+			},
+			Expected: []string{"id_name"},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
+func TestRegisterTags(t *testing.T) {
+	doc := New("", "", "")
+	doc.AddTags(Tag{Name: "users", Desc: "user accounts"})
+	doc.DescribeTag("billing", "invoices and payments")
+	doc.DescribeTag("users", "the user accounts")
+	doc.GetRoute("/users", "get").Tags("users")
+	doc.GetRoute("/invoices", "get").Tags("billing", "reports")
+	doc.GetRoute("/health", "get")
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	// a second Compile does not add the tags again
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(doc.Tags, []Tag{
+		{Name: "users", Desc: "the user accounts"},
+		{Name: "billing", Desc: "invoices and payments"},
+		{Name: "reports"},
+	}); !eq {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,131 @@
+package openapi
+
+import (
+	"reflect"
+	"sync"
+)
+
+// schemaBuilder builds the schemas of go values with the settings of a document,
+// such as the schemas used in place of reflection for specific types.
+// Each document has its own builder so its settings do not change other documents.
+type schemaBuilder struct {
+	mu     sync.RWMutex
+	types  map[reflect.Type]Schema // see OverrideSchema
+	names  map[string]Schema       // [type name]Schema, see OverrideSchemaName
+	naming NamingFunc              // see SetNamingFunc, DefaultNaming when nil
+	hasher Hasher                  // see SetHasher, crc64 when nil
+	bytes  string                  // see SetBytesFormat, Byte when empty
+	cache  schemaCache
+
+	components map[string]string // names of the component schemas by the title they were built with, see SetSchemaName
+
+	titleMu sync.Mutex
+	titles  map[hashKey]string // the titles of the maps already hashed, see hash
+}
+
+// defaultSchemas builds the schemas of the examples added to responses and requests,
+// they do not belong to a document yet. A route builds them again with the settings
+// of its document when they are added, see Route.buildContent.
+var defaultSchemas = &schemaBuilder{}
+
+// newSchemaBuilder creates the builder of a document with the default settings
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{titles: make(map[hashKey]string)}
+}
+
+// schemaBuilder returns the builder with the schema settings of the document, it is guarded by
+// its own lock. The builder of a document not created by New or NewFromJson is created on first use.
+func (o *OpenAPI) schemaBuilder() *schemaBuilder {
+	if o.schemas == nil {
+		o.schemas = newSchemaBuilder()
+	}
+	return o.schemas
+}
+
+// buildSchema creates the schema of the value with the settings of the document
+func (o *OpenAPI) buildSchema(v any) Schema {
+	return o.schemaBuilder().build(v)
+}
+
+// custom reports if a setting of the builder differs from the defaults
+func (b *schemaBuilder) custom() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.types) > 0 || len(b.names) > 0 || b.naming != nil || b.hasher != nil || b.bytesFormat() != Byte
+}
+
+// builder returns the schema builder of the document of the route,
+// nil when the route has no document or the document uses the default settings.
+func (r *Route) builder() *schemaBuilder {
+	if r.doc == nil {
+		return nil
+	}
+	if b := r.doc.schemaBuilder(); b.custom() {
+		return b
+	}
+	return nil
+}
+
+// buildSchema creates the schema of the value with the settings of the document of the route
+func (r *Route) buildSchema(v any) Schema {
+	if b := r.builder(); b != nil {
+		return b.build(v)
+	}
+	return buildSchema(v)
+}
+
+// buildContent builds the schema of each media type again from the value it was built with
+// by the settings of the document of the route. The content is copied when a schema is built,
+// so a Response or RequestBody added to more than one document keeps its own schemas.
+func (r *Route) buildContent(c Content) Content {
+	b := r.builder()
+	if b == nil {
+		return c
+	}
+	var built Content
+	for mime, m := range c {
+		if m.source == nil {
+			continue
+		}
+		if built == nil {
+			built = make(Content, len(c))
+			for k, v := range c {
+				built[k] = v
+			}
+		}
+		m.Schema = b.build(m.source)
+		built[mime] = m
+	}
+	if built == nil {
+		return c
+	}
+	return built
+}
+
+// buildHeaders builds the schema of each header again from the value it was built with
+// by the settings of the document of the route, the headers are copied like in buildContent.
+func (r *Route) buildHeaders(headers map[string]Header) map[string]Header {
+	b := r.builder()
+	if b == nil {
+		return headers
+	}
+	var built map[string]Header
+	for name, h := range headers {
+		if h.source == nil {
+			continue
+		}
+		if built == nil {
+			built = make(map[string]Header, len(headers))
+			for k, v := range headers {
+				built[k] = v
+			}
+		}
+		s := b.build(h.source)
+		h.Schema = &s
+		built[name] = h
+	}
+	if built == nil {
+		return headers
+	}
+	return built
+}
@@ -0,0 +1,59 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestRouteBuildSchemas(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	resp := Response{Status: 200}.WithExample(user{Name: "bob"})
+
+	short := New("", "", "")
+	short.SetNamingFunc(ShortNaming)
+	short.GetRoute("/users", "get").
+		AddResponse(resp).
+		AddRequest(RequestBody{}.WithExample(user{})).
+		QueryParam("filter", JSONParam(user{}), "")
+	short.GetWebhook("user", "post").AddRequest(RequestBody{}.WithExample(user{}))
+	short.GetRoute("/users", "post").Callback("created", "{$request.body#/url}", "post").
+		AddRequest(RequestBody{}.WithExample(user{}))
+	other := New("", "", "")
+	other.GetRoute("/users", "get").AddResponse(resp)
+
+	users := short.GetRoute("/users", "get")
+	cb := short.GetRoute("/users", "post").Callbacks["created"]["{$request.body#/url}|post"]
+	titles := []string{
+		users.Responses[200].Content[Json].Schema.Title,
+		users.Requests.Content[Json].Schema.Title,
+		users.Params["query|filter"].Content[Json].Schema.Title,
+		short.GetWebhook("user", "post").Requests.Content[Json].Schema.Title,
+		cb.Requests.Content[Json].Schema.Title,
+		other.GetRoute("/users", "get").Responses[200].Content[Json].Schema.Title,
+		resp.Content[Json].Schema.Title,
+	}
+	// the response added to both documents keeps its default schema
+	eq, diff := trial.Equal(titles, []string{"user", "user", "user", "user", "user", "openapi.user", "openapi.user"})
+	if !eq {
+		t.Error(diff)
+	}
+
+	// a schema set by reference is kept and loaded routes use the document settings
+	loaded, err := NewFromJson(short.JSON())
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded.SetNamingFunc(ShortNaming)
+	loaded.GetRoute("/users", "get").
+		AddResponse(Response{Status: 201}.WithExample(user{})).
+		AddResponse(Response{Status: 202}.WithSchemaRef("#/components/schemas/Account"))
+	responses := loaded.GetRoute("/users", "get").Responses
+	eq, diff = trial.Equal([]string{responses[201].Content[Json].Schema.Title, responses[202].Content[Json].Schema.Ref},
+		[]string{"user", "#/components/schemas/Account"})
+	if !eq {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,36 @@
+package openapi
+
+import "runtime/debug"
+
+// BuildInfo is the version control information of the binary that created the document.
+type BuildInfo struct {
+	Revision  string `json:"revision,omitempty"`  // commit of the build
+	Time      string `json:"time,omitempty"`      // commit time, RFC3339
+	Modified  bool   `json:"modified,omitempty"`  // the working tree had uncommitted changes
+	GoVersion string `json:"goVersion,omitempty"` // version of go used to build the binary
+}
+
+// StampBuildInfo records the VCS revision and time of the running binary
+// in the x-build extension of the Info, so a published document can be traced to a commit.
+// Nothing is recorded when the binary has no build information.
+func (o *OpenAPI) StampBuildInfo() {
+	o.stampBuildInfo(debug.ReadBuildInfo())
+}
+
+func (o *OpenAPI) stampBuildInfo(info *debug.BuildInfo, ok bool) {
+	if !ok || info == nil {
+		return
+	}
+	b := BuildInfo{GoVersion: info.GoVersion}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			b.Revision = s.Value
+		case "vcs.time":
+			b.Time = s.Value
+		case "vcs.modified":
+			b.Modified = s.Value == "true"
+		}
+	}
+	o.Info.SetExtension("x-build", b)
+}
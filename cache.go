@@ -0,0 +1,161 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// schemaCache stores the schemas of types that always reflect to the same shape.
+// A type is cacheable when its schema does not depend on the value passed in,
+// that is, when no maps, interfaces, json.RawMessage or Time can be reached from it.
+// The schemas are cleared when LoadFieldDocs changes the descriptions of the types.
+type schemaCache struct {
+	disabled atomic.Bool
+	docs     atomic.Uint64 // the version of the field docs of the schemas
+	schemas  sync.Map      // [reflect.Type]Schema
+	static   sync.Map      // [reflect.Type]bool
+}
+
+// SchemaCache enables or disables the reuse of schemas for types that have already been
+// reflected by the document. It is enabled by default, disabling it will also clear any
+// previously cached schemas. Each document has its own cache, the schemas of a document
+// depend on its settings such as OverrideSchema.
+func (o *OpenAPI) SchemaCache(enabled bool) {
+	c := &o.schemaBuilder().cache
+	c.disabled.Store(!enabled)
+	if !enabled {
+		c.clear()
+	}
+}
+
+func (c *schemaCache) clear() {
+	c.schemas.Range(func(k, _ any) bool {
+		c.schemas.Delete(k)
+		return true
+	})
+}
+
+// load a copy of the schema for the type if it was previously stored
+func (c *schemaCache) load(t reflect.Type) (Schema, bool) {
+	if c.disabled.Load() {
+		return Schema{}, false
+	}
+	if v := fieldDocsVersion.Load(); c.docs.Swap(v) != v {
+		c.clear()
+		return Schema{}, false
+	}
+	v, found := c.schemas.Load(t)
+	if !found {
+		return Schema{}, false
+	}
+	return v.(Schema).clone(), true
+}
+
+// store the schema for the type if the type is cacheable
+func (c *schemaCache) store(t reflect.Type, s Schema) {
+	if c.disabled.Load() || !c.isStatic(t) {
+		return
+	}
+	c.schemas.Store(t, s.clone())
+}
+
+// isStatic reports if the schema of type t is independent of its value.
+func (c *schemaCache) isStatic(t reflect.Type) bool {
+	if v, found := c.static.Load(t); found {
+		return v.(bool)
+	}
+	b := isStaticType(t, make(map[reflect.Type]bool))
+	c.static.Store(t, b)
+	return b
+}
+
+func isStaticType(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if visited[t] { // recursive types are never cached
+		return false
+	}
+	visited[t] = true
+	defer delete(visited, t)
+
+	switch t.Kind() {
+	case reflect.Map, reflect.Interface, reflect.Invalid:
+		return false
+	case reflect.Slice:
+		if isRawMessage(t) { // the schema is built from the json value
+			return false
+		}
+		return isStaticType(t.Elem(), visited)
+	case reflect.Pointer, reflect.Array:
+		return isStaticType(t.Elem(), visited)
+	case reflect.Struct:
+		if t == timeType { // the format is the Format of the value
+			return false
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			if !isStaticType(t.Field(i).Type, visited) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+var (
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+	timeType       = reflect.TypeOf(Time{})
+)
+
+// isRawMessage reports if t is a json.RawMessage or another []byte named RawMessage
+func isRawMessage(t reflect.Type) bool {
+	return t == rawMessageType || (t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && t.Name() == "RawMessage")
+}
+
+// clone creates a deep copy of the schema so cached values are never shared
+func (s Schema) clone() Schema {
+	if s.Items != nil {
+		items := s.Items.clone()
+		s.Items = &items
+	}
+	if s.Enum != nil {
+		s.Enum = append([]any(nil), s.Enum...)
+	}
+	if s.Required != nil {
+		s.Required = append([]string(nil), s.Required...)
+	}
+	if s.Order != nil {
+		s.Order = append([]string(nil), s.Order...)
+	}
+	if s.XML != nil {
+		x := *s.XML
+		s.XML = &x
+	}
+	if s.Properties != nil {
+		props := make(map[string]Schema, len(s.Properties))
+		for k, v := range s.Properties {
+			props[k] = v.clone()
+		}
+		s.Properties = props
+	}
+	for _, l := range []*[]Schema{&s.OneOf, &s.AnyOf, &s.AllOf} {
+		if *l == nil {
+			continue
+		}
+		schemas := make([]Schema, len(*l))
+		for i, v := range *l {
+			schemas[i] = v.clone()
+		}
+		*l = schemas
+	}
+	if s.Extensions != nil {
+		ext := make(Extensions, len(s.Extensions))
+		for k, v := range s.Extensions {
+			ext[k] = v
+		}
+		s.Extensions = ext
+	}
+	return s
+}
@@ -0,0 +1,126 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hydronica/trial"
+)
+
+func TestSchemaCache(t *testing.T) {
+	type static struct {
+		Name  string
+		Items []struct{ ID int }
+	}
+	type dynamic struct {
+		Name  string
+		Value any
+	}
+
+	fn := func(v any) (bool, error) {
+		b := New("", "", "").schemaBuilder()
+		s := b.build(v)
+		cached, found := b.cache.load(reflect.TypeOf(v))
+		if found {
+			if eq, diff := trial.Equal(cached, s); !eq {
+				t.Error(diff)
+			}
+		}
+		return found, nil
+	}
+	cases := trial.Cases[any, bool]{
+		"struct": {
+			Input:    static{},
+			Expected: true,
+		},
+		"pointer": {
+			Input:    &static{},
+			Expected: true,
+		},
+		"interface_field": {
+			Input:    dynamic{},
+			Expected: false,
+		},
+		"raw message field": {
+			Input:    struct{ Data json.RawMessage }{Data: json.RawMessage(`{"a":1}`)},
+			Expected: false,
+		},
+		"map": {
+			Input:    map[string]string{"key": "value"},
+			Expected: false,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	// the schema of a json.RawMessage field depends on its value
+	type raw struct{ Data json.RawMessage }
+	doc := New("", "", "")
+	doc.buildSchema(raw{Data: json.RawMessage(`{"a":1}`)})
+	if s := doc.buildSchema(raw{Data: json.RawMessage(`[1,2]`)}); s.Properties["Data"].Type != Array {
+		t.Errorf("expected array schema got %q", s.Properties["Data"].Type)
+	}
+
+	// the format of a Time depends on its value
+	type event struct{ At Time }
+	for _, format := range []string{time.DateOnly, time.RFC3339} {
+		s := doc.buildSchema(event{At: Time{Format: format}})
+		if f := s.Properties["At"].Format; f != timeFormat(format) {
+			t.Errorf("expected format %q got %q", timeFormat(format), f)
+		}
+		if s := doc.buildSchema(Time{Format: format}); s.Format != timeFormat(format) {
+			t.Errorf("expected format %q got %q", timeFormat(format), s.Format)
+		}
+	}
+
+	// disabled on a single document
+	doc = New("", "", "")
+	doc.SchemaCache(false)
+	doc.buildSchema(static{})
+	if _, found := doc.schemaBuilder().cache.load(reflect.TypeOf(static{})); found {
+		t.Error("expected the schema not to be cached")
+	}
+
+	// cached values must not be shared with the caller
+	s := buildSchema(static{})
+	s.Properties["Name"] = Schema{Type: Integer}
+	s.Items = &Schema{}
+	if got := buildSchema(static{}); got.Properties["Name"].Type != String {
+		t.Errorf("cached schema was modified: %v", got.Properties["Name"])
+	}
+}
+
+func TestBuildSchemaConcurrent(t *testing.T) {
+	type item struct {
+		ID   int
+		Tags []string
+	}
+	values := []any{
+		item{},
+		&item{},
+		map[string]any{"a": 1, "b": "two"},
+		[]item{{ID: 1}},
+	}
+	expected := make([]Schema, len(values))
+	for i, v := range values {
+		expected[i] = buildSchema(v)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 100; n++ {
+				i := n % len(values)
+				if eq, diff := trial.Equal(buildSchema(values[i]), expected[i]); !eq {
+					t.Error(diff)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
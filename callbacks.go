@@ -0,0 +1,99 @@
+package openapi
+
+import "encoding/json"
+
+// Callback returns the operation the API sends to the URL of the runtime expression
+// of the named callback, a new Route is created if it was not found.
+// The Route describes the request of the callback and the responses expected from the consumer.
+//
+//	// the callbackUrl of the request body is called when the job is done
+//	doc.GetRoute("/jobs", "post").
+//		Callback("jobDone", "{$request.body#/callbackUrl}", "post").
+//		AddRequest(openapi.RequestBody{}.WithExample(Job{})).
+//		AddResponse(openapi.Response{Status: 200, Desc: "received"})
+func (r *Route) Callback(name, expression, method string) *Route {
+	if r.Callbacks == nil {
+		r.Callbacks = make(map[string]Router)
+	}
+	if r.Callbacks[name] == nil {
+		r.Callbacks[name] = make(Router)
+	}
+	r.compiled = false
+	key := expression + "|" + method
+	cb, found := r.Callbacks[name][key]
+	if !found {
+		cb = &Route{
+			path:   expression,
+			method: method,
+			Params: make(Params),
+			doc:    r.doc,
+			seq:    len(r.Callbacks[name]) + 1,
+		}
+		r.Callbacks[name][key] = cb
+	}
+	return cb
+}
+
+// pending reports if the route or any of its callbacks changed since the last Compile,
+// the methods of the route mark it as not compiled. See DetectFieldChanges for direct writes to its fields.
+func (r *Route) pending() bool {
+	if !r.compiled {
+		return true
+	}
+	for _, cbs := range r.Callbacks {
+		for _, cb := range cbs {
+			if cb.pending() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DetectFieldChanges makes Compile process the routes changed by a direct write to their fields
+// such as r.Desc or r.Responses[201] as well as the routes changed with their methods.
+// The json of each route is checksummed on every Compile to find the changes, which
+// can cost more than compiling every route again, so it is disabled by default.
+// The routes compiled before it is enabled are compiled again once.
+//
+//	doc.DetectFieldChanges(true)
+//	r.Responses[201] = openapi.Response{Status: 201}.WithExample(user)
+//	doc.Compile() // compiles r again
+func (o *OpenAPI) DetectFieldChanges(enabled bool) {
+	o.fieldChanges = enabled
+}
+
+// detectFieldChanges marks the routes and callbacks whose json changed since they were compiled as not compiled
+func (o *OpenAPI) detectFieldChanges() {
+	var detect func(router Router)
+	detect = func(router Router) {
+		for _, r := range router {
+			if r.compiled && r.hash != r.checksum() {
+				r.compiled = false
+			}
+			for _, cbs := range r.Callbacks {
+				detect(cbs)
+			}
+		}
+	}
+	detect(o.Paths)
+	detect(o.Webhooks)
+}
+
+// setCompiled marks the route as compiled when err is nil,
+// the checksum of its json is kept when the document detects field changes.
+func (o *OpenAPI) setCompiled(r *Route, err error) {
+	r.compiled = err == nil
+	if o.fieldChanges {
+		r.hash = r.checksum()
+	}
+}
+
+// checksum of the json of the route, 0 when it can't be written
+func (r *Route) checksum() uint64 {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return 0
+	}
+	return crcHash(b)
+}
@@ -0,0 +1,54 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestCallback(t *testing.T) {
+	type job struct {
+		ID string `json:"id"`
+	}
+	doc := New("", "", "")
+	route := doc.GetRoute("/jobs", "post").AddResponse(Response{Status: 202, Desc: "accepted"})
+	route.Callback("jobDone", "{$request.body#/callbackUrl}", "post").
+		AddRequest(RequestBody{}.WithExample(job{ID: "1"})).
+		AddResponse(Response{Status: 200, Desc: "received"})
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := doc.Components.Schemas["openapi.job"]; !found {
+		t.Error("expected the schema of the callback in the components")
+	}
+
+	b, err := json.Marshal(route.Callbacks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(string(b),
+		`{"jobDone":{"{$request.body#/callbackUrl}":{"post":{"responses":{"200":{"description":"received"}},"requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/openapi.job"},"examples":{"openapi.job":{"value":{"id":"1"}}}}}}}}}}`); !eq {
+		t.Error(diff)
+	}
+
+	// a changed callback compiles the route again
+	route.Callback("jobDone", "{$request.body#/callbackUrl}", "post").
+		AddResponse(Response{Status: 500}.WithJSONString("invalid"))
+	if !route.pending() {
+		t.Error("expected the route to be pending")
+	}
+	if err := doc.Compile(); err == nil {
+		t.Error("expected the error of the callback")
+	}
+
+	// the callbacks are read back
+	doc2, err := NewFromJson(doc.JSON())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb := doc2.GetRoute("/jobs", "post").Callbacks["jobDone"]["{$request.body#/callbackUrl}|post"]
+	if cb == nil || cb.Responses[200].Desc != "received" {
+		t.Errorf("callback not unmarshaled %+v", cb)
+	}
+}
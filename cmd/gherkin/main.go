@@ -5,14 +5,16 @@ import (
 	"errors"
 	"flag"
 	"fmt"
-	"io"
 	"log"
 	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	gherkin "github.com/cucumber/gherkin/go/v27"
 	messages "github.com/cucumber/messages/go/v22"
@@ -30,6 +32,10 @@ type conf struct {
 	Out  string `flag:"out" comment:"generated openAPI file"`
 	Base string `flag:"base" comment:"base openAPI file"`
 
+	Workers int `flag:"workers" comment:"number of workers used to build example schemas"`
+
+	Report string `flag:"report" comment:"json file of the skipped scenarios and steps that failed to parse"`
+
 	Title       string `flag:"-" comment:"title for openAPI doc"`
 	Version     string `flag:"-" comment:"version of app for openAPI doc"`
 	Description string `flag:"-" comment:"description for openAPI doc"`
@@ -50,6 +56,7 @@ func main() {
 		Title:       "my app",
 		Version:     "v0.10.14",
 		Description: "describe me",
+		Workers:     runtime.NumCPU(),
 	}
 	flag.BoolVar(&debug, "d", false, "show debug logs")
 	config.LoadOrDie(&c)
@@ -58,19 +65,11 @@ func main() {
 	// Create openAPI/Swagger doc
 	var doc *openapi.OpenAPI
 	if c.Base != "" {
-		f, err := os.Open(c.Base)
-		if err != nil {
-			log.Fatalf("error reading base file %q: %v", c.Base, err)
-		}
-		b, err := io.ReadAll(f)
+		var err error
+		doc, err = openapi.NewResolver().Load(c.Base)
 		if err != nil {
 			log.Fatalf("error reading base file %q: %v", c.Base, err)
 		}
-
-		doc, err = openapi.NewFromJson(string(b))
-		if err != nil {
-
-		}
 	} else {
 		doc = openapi.New(c.Title, c.Version, c.Description)
 	}
@@ -81,6 +80,7 @@ func main() {
 		log.Fatal(err)
 	}
 	tests := make(routes)
+	var rep report
 	uuid := &messages.UUID{}
 	for _, f := range files {
 		fileContent, err := os.ReadFile(f)
@@ -90,9 +90,11 @@ func main() {
 		reader := strings.NewReader(string(fileContent))
 		gherkinDocument, err := gherkin.ParseGherkinDocument(reader, uuid.NewId)
 		if err != nil {
-			log.Fatal(err)
+			log.Printf("Skip %v: %v", f, err)
+			rep.Skipped = append(rep.Skipped, parseIssues(f, err)...)
+			continue
 		}
-		r := extractTest(gherkinDocument)
+		r := extractTest(f, gherkinDocument)
 		if debug {
 			fName := strings.Split(filepath.Base(f), ".")[0]
 			gFil, _ := os.Create("debug/" + fName + ".gherkin.json")
@@ -107,34 +109,33 @@ func main() {
 		tests.addRoutes(r)
 	}
 
+	// build the request and response schemas concurrently
+	// before they are added to the routes of the doc.
+	bodies := buildBodies(tests, c.Workers)
+
 	// convert gherkin docs to routes
 	for k, examples := range tests {
 		s := strings.Split(k, "|")
 		path, method := s[0], s[1]
+		for _, ex := range examples {
+			rep.Scenarios++
+			rep.Failures = append(rep.Failures, ex.failures...)
+		}
 		if path == "" && method == "" {
 			for _, ex := range examples {
 				log.Printf("Skip: %v", ex.Name)
+				rep.Skipped = append(rep.Skipped, issue{File: ex.file, Line: ex.line, Scenario: ex.Name, Reason: "missing method and url"})
 			}
 			continue
 		}
 		route := doc.GetRoute(path, method)
 
-		req := openapi.RequestBody{}
-		for _, ex := range examples {
-
-			r := openapi.Response{
-				Status: openapi.Code(ex.Status),
-				Desc:   ex.Description,
-			}
-
-			if ex.ReqBody != "" {
-				route.AddRequest(req.WithJSONString(ex.ReqBody))
+		for i, ex := range examples {
+			b := bodies[k][i]
+			if b.req != nil {
+				route.AddRequest(*b.req)
 			}
-
-			if ex.RespBody != "" {
-				r = r.WithJSONString(ex.RespBody)
-			}
-			route.AddResponse(r)
+			route.AddResponse(b.resp)
 
 			for k, v := range ex.params {
 				route.QueryParam(k, v, "")
@@ -144,22 +145,37 @@ func main() {
 	if err := doc.Compile(); err != nil {
 		log.Println(err)
 	}
+	for _, w := range doc.Warnings() {
+		log.Println("warning:", w)
+	}
 	// generate the output swagger doc
 	f, err := os.Create(c.Out)
 	if err != nil {
-		log.Fatalf("issue with writing %q: %w", c.Out, err)
+		log.Fatalf("issue with writing %q: %v", c.Out, err)
+	}
+	defer f.Close()
+	if err := doc.WriteJSON(f); err != nil {
+		log.Fatalf("issue with writing %q: %v", c.Out, err)
+	}
+	if c.Report != "" {
+		if err := rep.write(c.Report); err != nil {
+			log.Fatalf("issue with writing %q: %v", c.Report, err)
+		}
 	}
-	f.Write([]byte(doc.JSON()))
 }
 
 var regURL = regexp.MustCompile(".*(POST|GET|PUT|DELETE).*\\\"(.*)\\\"")
 
-func extractTest(document *messages.GherkinDocument) routes {
+func extractTest(file string, document *messages.GherkinDocument) routes {
 	tests := make(routes)
+	if document.Feature == nil {
+		return tests
+	}
 	for _, child := range document.Feature.Children {
-		ex := Example{}
+		ex := Example{file: file}
 		if child.Scenario != nil {
 			ex.Name = child.Scenario.Name
+			ex.line = int(child.Scenario.Location.Line)
 			ex.Description = child.Scenario.Description
 			for _, step := range child.Scenario.Steps {
 				switch step.KeywordType {
@@ -181,6 +197,7 @@ func extractTest(document *messages.GherkinDocument) routes {
 						m := processDataTable(step.DataTable)
 						b, err := json.Marshal(m)
 						if err != nil {
+							ex.fail(step, "form data: "+err.Error())
 							if debug {
 								log.Println("error parsing form data ", step.Text, err)
 							}
@@ -200,6 +217,7 @@ func extractTest(document *messages.GherkinDocument) routes {
 				case "Action":
 					if !regURL.MatchString(step.Text) {
 						log.Println("match not found:", step.Text)
+						ex.fail(step, "no method and url in "+strconv.Quote(step.Text))
 						continue
 					}
 					m := regURL.FindStringSubmatch(step.Text)
@@ -212,17 +230,23 @@ func extractTest(document *messages.GherkinDocument) routes {
 				case "Outcome":
 					if after, found := strings.CutPrefix(step.Text, "The status code should be "); found {
 						i, err := strconv.Atoi(after)
-						if err != nil && debug {
-							log.Printf("unknown status code %q", after)
-							continue
+						if err != nil {
+							ex.fail(step, fmt.Sprintf("unknown status code %q", after))
+							if debug {
+								log.Printf("unknown status code %q", after)
+								continue
+							}
 						}
 						ex.Status = i
 					} else if after, found := strings.CutPrefix(step.Text, "I should see the following JSON error message with code"); found {
 						after = strings.Trim(after, " \\\":")
 						i, err := strconv.Atoi(after)
-						if err != nil && debug {
-							log.Printf("unknown status error %q", after)
-							continue
+						if err != nil {
+							ex.fail(step, fmt.Sprintf("unknown status error %q", after))
+							if debug {
+								log.Printf("unknown status error %q", after)
+								continue
+							}
 						}
 						ex.Status = i
 						ex.Description = step.DocString.Content
@@ -311,11 +335,71 @@ func (r routes) addRoutes(new routes) {
 	}
 }
 
+// body is the request and response built from an Example
+type body struct {
+	req  *openapi.RequestBody
+	resp openapi.Response
+}
+
+// buildBodies creates the request and response of every example using n workers.
+// The result has the same keys and order as the routes.
+func buildBodies(r routes, n int) map[string][]body {
+	if n < 1 {
+		n = 1
+	}
+	bodies := make(map[string][]body, len(r))
+	for k, examples := range r {
+		bodies[k] = make([]body, len(examples))
+	}
+
+	type job struct {
+		key string
+		i   int
+	}
+	jobs := make(chan job)
+	var wg sync.WaitGroup
+	for w := 0; w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				ex := r[j.key][j.i]
+				b := body{
+					resp: openapi.Response{
+						Status: openapi.Code(ex.Status),
+						Desc:   ex.Description,
+					},
+				}
+				if ex.ReqBody != "" {
+					req := openapi.RequestBody{}.WithJSONString(ex.ReqBody)
+					b.req = &req
+				}
+				if ex.RespBody != "" {
+					b.resp = b.resp.WithJSONString(ex.RespBody)
+				}
+				bodies[j.key][j.i] = b
+			}
+		}()
+	}
+	for k, examples := range r {
+		for i := range examples {
+			jobs <- job{key: k, i: i}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return bodies
+}
+
 type Example struct {
 	path   string
 	params url.Values
 	method string
 
+	file     string // feature file and line of the scenario
+	line     int
+	failures []issue // steps that could not be parsed
+
 	Name        string
 	Description string
 	ContentType string
@@ -325,3 +409,65 @@ type Example struct {
 	Status   int
 	RespBody string
 }
+
+// fail records a step of the scenario that could not be parsed
+func (ex *Example) fail(step *messages.Step, reason string) {
+	ex.failures = append(ex.failures, issue{
+		File:     ex.file,
+		Line:     int(step.Location.Line),
+		Scenario: ex.Name,
+		Reason:   reason,
+	})
+}
+
+// report of the -report flag lists the scenarios that are missing from the doc
+type report struct {
+	Scenarios int     `json:"scenarios"` // number of scenarios read
+	Skipped   []issue `json:"skipped"`   // scenarios or files that were not added
+	Failures  []issue `json:"failures"`  // steps that could not be parsed, the scenario may be incomplete
+}
+
+type issue struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Scenario string `json:"scenario,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+// write the report as json to the file sorted by file and line
+func (r report) write(file string) error {
+	for _, l := range [][]issue{r.Skipped, r.Failures} {
+		sort.SliceStable(l, func(i, j int) bool {
+			if l[i].File != l[j].File {
+				return l[i].File < l[j].File
+			}
+			return l[i].Line < l[j].Line
+		})
+	}
+	if r.Skipped == nil {
+		r.Skipped = []issue{}
+	}
+	if r.Failures == nil {
+		r.Failures = []issue{}
+	}
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, b, 0644)
+}
+
+var regParseError = regexp.MustCompile(`(?m)^\((\d+):\d+\): (.*)$`)
+
+// parseIssues returns an issue for every error of a feature file that could not be parsed
+func parseIssues(file string, err error) []issue {
+	var issues []issue
+	for _, m := range regParseError.FindAllStringSubmatch(err.Error(), -1) {
+		line, _ := strconv.Atoi(m[1])
+		issues = append(issues, issue{File: file, Line: line, Reason: m[2]})
+	}
+	if len(issues) == 0 {
+		issues = append(issues, issue{File: file, Reason: err.Error()})
+	}
+	return issues
+}
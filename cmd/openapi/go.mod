@@ -0,0 +1,14 @@
+module openapi
+
+go 1.20
+
+require (
+	github.com/hydronica/go-openapi v0.0.0-00010101000000-000000000000
+	github.com/hydronica/trial v0.7.2
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require github.com/google/go-cmp v0.6.0 // indirect
+
+// the cli is built with the library of this repository
+replace github.com/hydronica/go-openapi => ../..
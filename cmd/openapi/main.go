@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/hydronica/go-openapi"
+)
+
+const usage = `usage: openapi <command> [flags] files...
+
+commands:
+  validate file...               check the documents for unresolved references
+  merge [-o out] base file...    merge the routes and components of the files into base
+  diff base head                 list the changes from base to head, exits with 1 if they differ
+  convert [-o out] [-swagger2] file
+                                 convert between json and yaml, swagger 2.0 files are converted to openapi 3.0
+                                 and -swagger2 converts an openapi 3.0 file to swagger 2.0
+  traffic file access.log        compare the documented routes with the requests of an access log
+
+files may be json or yaml, the output format is chosen by the extension of -o (json by default)
+external $refs to json and yaml files are inlined
+the output is openapi 3.0 or later unless convert is called with -swagger2
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	code, err := run(os.Args[1], os.Args[2:], os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	os.Exit(code)
+}
+
+// run the command with its args and returns the exit code
+func run(cmd string, args []string, stdout io.Writer) (int, error) {
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	out := fs.String("o", "", "output file, stdout if empty")
+	swagger2 := fs.Bool("swagger2", false, "convert the output to swagger 2.0")
+	if err := fs.Parse(args); err != nil {
+		return 2, nil
+	}
+	files := fs.Args()
+
+	switch cmd {
+	case "validate":
+		if len(files) == 0 {
+			return 2, errors.New("validate requires a file")
+		}
+		var errs error
+		for _, f := range files {
+			doc, err := load(f)
+			if err == nil {
+				if err = doc.Validate(); err != nil {
+					err = fmt.Errorf("%v: %w", f, err)
+				}
+			}
+			errs = errors.Join(errs, err)
+		}
+		if errs != nil {
+			return 1, errs
+		}
+		return 0, nil
+
+	case "merge":
+		if len(files) < 2 {
+			return 2, errors.New("merge requires a base file and at least one file to merge")
+		}
+		doc, err := load(files[0])
+		if err != nil {
+			return 1, err
+		}
+		for _, f := range files[1:] {
+			other, err := load(f)
+			if err != nil {
+				return 1, err
+			}
+			if err := doc.Merge(other); err != nil {
+				return 1, fmt.Errorf("%v: %w", f, err)
+			}
+		}
+		return write(doc, *out, stdout)
+
+	case "diff":
+		if len(files) != 2 {
+			return 2, errors.New("diff requires a base and head file")
+		}
+		base, err := load(files[0])
+		if err != nil {
+			return 1, err
+		}
+		head, err := load(files[1])
+		if err != nil {
+			return 1, err
+		}
+		changes, err := openapi.Diff(base, head)
+		if err != nil {
+			return 1, err
+		}
+		for _, c := range changes {
+			fmt.Fprintln(stdout, c)
+		}
+		if len(changes) > 0 {
+			return 1, nil
+		}
+		return 0, nil
+
+	case "convert":
+		if len(files) != 1 {
+			return 2, errors.New("convert requires a file")
+		}
+		doc, err := load(files[0])
+		if err != nil {
+			return 1, err
+		}
+		if *swagger2 {
+			b, err := doc.Swagger2()
+			if err != nil {
+				return 1, err
+			}
+			var buf bytes.Buffer
+			if err := json.Indent(&buf, b, "", "    "); err != nil {
+				return 1, err
+			}
+			return writeBytes(buf.Bytes(), *out, stdout)
+		}
+		return write(doc, *out, stdout)
+
+	case "traffic":
+		if len(files) != 2 {
+			return 2, errors.New("traffic requires a file and an access log")
+		}
+		doc, err := load(files[0])
+		if err != nil {
+			return 1, err
+		}
+		f, err := os.Open(files[1])
+		if err != nil {
+			return 1, err
+		}
+		defer f.Close()
+		entries, err := openapi.ParseAccessLog(f)
+		if err != nil {
+			return 1, err
+		}
+		report := doc.TrafficCoverage(entries)
+		for _, s := range report.Unused {
+			fmt.Fprintln(stdout, "unused", s)
+		}
+		for _, s := range report.Undocumented {
+			fmt.Fprintln(stdout, "undocumented", s)
+		}
+		for _, s := range report.UndocumentedStatus {
+			fmt.Fprintln(stdout, "undocumented status", s)
+		}
+		return 0, nil
+	}
+	return 2, fmt.Errorf("unknown command %q\n%v", cmd, usage)
+}
+
+// load a json or yaml document, external $refs are inlined and swagger 2.0 documents are converted to 3.0.
+func load(file string) (*openapi.OpenAPI, error) {
+	doc, err := openapi.NewResolver().Load(file)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", file, err)
+	}
+	return doc, nil
+}
+
+// write the document as json or yaml based on the extension of the file
+func write(doc *openapi.OpenAPI, file string, stdout io.Writer) (int, error) {
+	var buf bytes.Buffer
+	if err := doc.WriteJSON(&buf); err != nil {
+		return 1, err
+	}
+	return writeBytes(buf.Bytes(), file, stdout)
+}
+
+// writeBytes writes the json b to the file, converted to yaml based on its extension
+func writeBytes(b []byte, file string, stdout io.Writer) (int, error) {
+	if isYAML(file) {
+		var err error
+		if b, err = jsonToYAML(b); err != nil {
+			return 1, err
+		}
+	}
+	if file == "" {
+		_, err := stdout.Write(b)
+		return 0, err
+	}
+	if err := os.WriteFile(file, b, 0o644); err != nil {
+		return 1, err
+	}
+	return 0, nil
+}
+
+func isYAML(file string) bool {
+	ext := strings.ToLower(filepath.Ext(file))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+func jsonToYAML(b []byte) ([]byte, error) {
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(v)
+}
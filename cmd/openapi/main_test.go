@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+// result of a command, Out is the output written to stdout or to the -o file
+type result struct {
+	Code int
+	Out  string
+	Err  string
+}
+
+// golden reads the expected output of a command from testdata
+func golden(t *testing.T, name string) string {
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestRun(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out.yaml")
+	fn := func(args []string) (result, error) {
+		var stdout bytes.Buffer
+		code, err := run(args[0], args[1:], &stdout)
+		r := result{Code: code, Out: stdout.String()}
+		if err != nil {
+			r.Err = err.Error()
+		}
+		if len(args) > 2 && args[1] == "-o" {
+			b, err := os.ReadFile(args[2])
+			if err != nil {
+				return r, err
+			}
+			r.Out = string(b)
+		}
+		return r, nil
+	}
+	cases := trial.Cases[[]string, result]{
+		"validate": {
+			Input:    []string{"validate", "testdata/users.yaml", "testdata/orders.json"},
+			Expected: result{},
+		},
+		"validate undeclared scheme": {
+			Input:    []string{"validate", "testdata/users.yaml", "testdata/invalid.json"},
+			Expected: result{Code: 1, Err: `testdata/invalid.json: get /users: security scheme "apiKey" is not declared`},
+		},
+		"validate without file": {
+			Input:    []string{"validate"},
+			Expected: result{Code: 2, Err: "validate requires a file"},
+		},
+		"merge": {
+			Input:    []string{"merge", "testdata/users.yaml", "testdata/orders.json"},
+			Expected: result{Out: golden(t, "merge.golden.json")},
+		},
+		"merge conflict": {
+			Input:    []string{"merge", "testdata/users.yaml", "testdata/conflict.json"},
+			Expected: result{Code: 1, Err: "testdata/conflict.json: conflicting route get /users"},
+		},
+		"diff": {
+			Input:    []string{"diff", "testdata/users.yaml", "testdata/users2.json"},
+			Expected: result{Code: 1, Out: "changed /info/version\nadded /paths/~1users/post\n"},
+		},
+		"diff same": {
+			Input:    []string{"diff", "testdata/users.yaml", "testdata/users.yaml"},
+			Expected: result{},
+		},
+		"convert yaml with external refs": {
+			Input:    []string{"convert", "testdata/users.yaml"},
+			Expected: result{Out: golden(t, "convert.golden.json")},
+		},
+		"convert swagger 2.0 to yaml": {
+			Input:    []string{"convert", "-o", out, "testdata/swagger.json"},
+			Expected: result{Out: golden(t, "swagger.golden.yaml")},
+		},
+		"convert to swagger 2.0": {
+			Input:    []string{"convert", "-swagger2", "testdata/users.yaml"},
+			Expected: result{Out: golden(t, "swagger2.golden.json")},
+		},
+		"unknown command": {
+			Input:    []string{"lint", "testdata/users.yaml"},
+			Expected: result{Code: 2, Err: "unknown command \"lint\"\n" + usage},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
@@ -0,0 +1,74 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AddComponentParam adds the param to the components with the given name,
+// routes reference it with Route.ParamRef instead of repeating the param.
+//
+//	doc.AddComponentParam("tenant", openapi.Param{Name: "X-Tenant-Id", In: "header", Schema: &openapi.Schema{Type: openapi.String}})
+//	doc.GetRoute("/users", "get").ParamRef("tenant")
+func (o *OpenAPI) AddComponentParam(name string, p Param) {
+	if o.Components.Parameters == nil {
+		o.Components.Parameters = make(map[string]Param)
+	}
+	if p.Examples == nil {
+		p.Examples = make(map[string]Example)
+	}
+	o.Components.Parameters[name] = p
+}
+
+// ParamRef adds a reference to a param added with AddComponentParam to the route,
+// the operation lists the param as a $ref to #/components/parameters/{name}.
+func (r *Route) ParamRef(name string) *Route {
+	if r.Params == nil {
+		r.Params = make(Params)
+	}
+	r.compiled = false
+	ref := "#/components/parameters/" + name
+	r.Params[ref] = Param{Ref: ref}
+	return r
+}
+
+// AddComponentHeader adds the header to the components with the given name,
+// responses reference it with Response.WithHeaderRef instead of repeating the header.
+//
+//	doc.AddComponentHeader("RateLimit", openapi.Header{Desc: "requests left", Schema: &openapi.Schema{Type: openapi.Integer}})
+//	openapi.Response{Status: 200}.WithHeaderRef("X-RateLimit-Remaining", "RateLimit")
+func (o *OpenAPI) AddComponentHeader(name string, h Header) {
+	if o.Components.Headers == nil {
+		o.Components.Headers = make(map[string]Header)
+	}
+	o.Components.Headers[name] = h
+}
+
+// WithHeaderRef returns the Response with the header referencing a header added with AddComponentHeader
+func (r Response) WithHeaderRef(header, name string) Response {
+	return r.WithHeader(header, Header{Ref: "#/components/headers/" + name})
+}
+
+// checkParamRef returns an error when the param references a missing component
+func (o *OpenAPI) checkParamRef(p Param) error {
+	name, found := strings.CutPrefix(p.Ref, "#/components/parameters/")
+	if !found {
+		return nil
+	}
+	if _, found := o.Components.Parameters[name]; !found {
+		return fmt.Errorf("parameter %v not found", p.Ref)
+	}
+	return nil
+}
+
+// checkHeaderRef returns an error when the header references a missing component
+func (o *OpenAPI) checkHeaderRef(h Header) error {
+	name, found := strings.CutPrefix(h.Ref, "#/components/headers/")
+	if !found {
+		return nil
+	}
+	if _, found := o.Components.Headers[name]; !found {
+		return fmt.Errorf("header %v not found", h.Ref)
+	}
+	return nil
+}
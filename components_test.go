@@ -0,0 +1,82 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestParamRef(t *testing.T) {
+	doc := New("", "", "")
+	doc.AddComponentParam("tenant", Param{Name: "X-Tenant-Id", In: "header", Desc: "the tenant", Schema: &Schema{Type: String}})
+	doc.AddComponentParam("page", Param{Name: "page", In: "query", Schema: &Schema{Type: Integer}})
+	for _, path := range []string{"/users", "/orders"} {
+		doc.GetRoute(path, "get").
+			ParamRef("tenant").
+			ParamRef("page").
+			AddResponse(Response{Status: 200, Desc: "ok"})
+	}
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(doc.GetRoute("/users", "get").Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(string(b), `[{"$ref":"#/components/parameters/page"},{"$ref":"#/components/parameters/tenant"}]`); !eq {
+		t.Error(diff)
+	}
+	b, err = json.Marshal(doc.Components.Parameters["tenant"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(string(b), `{"name":"X-Tenant-Id","description":"the tenant","in":"header","schema":{"type":"string"},"examples":{}}`); !eq {
+		t.Error(diff)
+	}
+
+	// the params are read back with the refs
+	doc2, err := NewFromJson(doc.JSON())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(doc2.GetRoute("/orders", "get").Params, doc.GetRoute("/orders", "get").Params); !eq {
+		t.Error(diff)
+	}
+
+	doc.GetRoute("/users", "get").ParamRef("missing")
+	if err := doc.Compile(); err == nil {
+		t.Error("expected an error for the missing param")
+	}
+}
+
+func TestHeaderRef(t *testing.T) {
+	doc := New("", "", "")
+	doc.AddComponentHeader("RateLimit", Header{Desc: "requests left in the window", Schema: &Schema{Type: Integer}})
+	for _, path := range []string{"/users", "/orders"} {
+		doc.GetRoute(path, "get").AddResponse(Response{Status: 200, Desc: "ok"}.WithHeaderRef("X-RateLimit-Remaining", "RateLimit"))
+	}
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	fn := func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+	cases := trial.Cases[any, string]{
+		"response": {
+			Input:    doc.GetRoute("/users", "get").Responses[200],
+			Expected: `{"description":"ok","headers":{"X-RateLimit-Remaining":{"$ref":"#/components/headers/RateLimit"}}}`,
+		},
+		"components": {
+			Input:    doc.Components.Headers,
+			Expected: `{"RateLimit":{"description":"requests left in the window","schema":{"type":"integer"}}}`,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	doc.GetRoute("/users", "get").AddResponse(Response{Status: 429, Desc: "slow down"}.WithHeaderRef("Retry-After", "RetryAfter"))
+	if err := doc.Compile(); err == nil {
+		t.Error("expected an error for the missing header")
+	}
+}
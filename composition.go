@@ -0,0 +1,89 @@
+package openapi
+
+import "encoding/json"
+
+// Composition is a value whose schema is composed of the schemas of its values,
+// it is created with OneOf, AnyOf or AllOf and can be used as an example or as a struct field.
+type Composition struct {
+	kind   string
+	Values []any
+}
+
+// OneOf creates a Composition whose schema matches exactly one of the schemas of the values,
+// used to document endpoints that return one of several types.
+// Each value is added as an example of the content.
+//
+//	Response{Status: 200}.WithExample(openapi.OneOf(Cat{Name: "tom"}, Dog{Name: "rex"}))
+func OneOf(values ...any) Composition {
+	return Composition{kind: "oneOf", Values: values}
+}
+
+// AnyOf creates a Composition whose schema matches any of the schemas of the values.
+// Each value is added as an example of the content.
+func AnyOf(values ...any) Composition {
+	return Composition{kind: "anyOf", Values: values}
+}
+
+// AllOf creates a Composition whose schema matches all of the schemas of the values,
+// used to extend a schema. The example of the content is the json objects of the values merged together.
+//
+//	Response{Status: 200}.WithExample(openapi.AllOf(Pet{Name: "tom"}, Cat{Indoor: true}))
+func AllOf(values ...any) Composition {
+	return Composition{kind: "allOf", Values: values}
+}
+
+// MarshalJSON writes the first value of a oneOf or anyOf Composition
+// and the merged values of allOf, so a Composition field has a valid example.
+func (c Composition) MarshalJSON() ([]byte, error) {
+	if c.kind == "allOf" {
+		merged, _ := c.merged()
+		return json.Marshal(merged)
+	}
+	if len(c.Values) == 0 {
+		return []byte("null"), nil
+	}
+	return json.Marshal(c.Values[0])
+}
+
+// schema of the composition with the schema of each value built by b
+func (c Composition) schema(b *schemaBuilder) Schema {
+	schemas := make([]Schema, 0, len(c.Values))
+	for _, v := range c.Values {
+		schemas = append(schemas, b.schemaOf(v, nil))
+	}
+	var s Schema
+	switch c.kind {
+	case "anyOf":
+		s.AnyOf = schemas
+	case "allOf":
+		s.AllOf = schemas
+	default:
+		s.OneOf = schemas
+	}
+	return s
+}
+
+// merged returns the json objects of the values merged together and the titles of their schemas
+func (c Composition) merged() (map[string]any, []string) {
+	merged := make(map[string]any)
+	titles := make([]string, 0, len(c.Values))
+	for _, v := range c.Values {
+		titles = append(titles, buildSchema(v).Title)
+		b, err := json.Marshal(v)
+		if err != nil {
+			continue
+		}
+		var m map[string]any
+		if json.Unmarshal(b, &m) == nil {
+			for k, v := range m {
+				merged[k] = v
+			}
+		}
+	}
+	return merged, titles
+}
+
+// composed reports if the schema is a oneOf, anyOf or allOf of other schemas
+func (s Schema) composed() bool {
+	return len(s.OneOf) > 0 || len(s.AnyOf) > 0 || len(s.AllOf) > 0
+}
@@ -0,0 +1,61 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestComposition(t *testing.T) {
+	type cat struct {
+		Name   string `json:"name"`
+		Indoor bool   `json:"indoor"`
+	}
+	type dog struct {
+		Name string `json:"name"`
+	}
+	fn := func(example any) (string, error) {
+		doc := New("", "", "")
+		doc.GetRoute("/pets", "get").AddResponse(Response{Status: 200}.WithExample(example))
+		if err := doc.Compile(); err != nil {
+			return "", err
+		}
+		b, err := json.Marshal(doc.Paths["/pets|get"].Responses[200].Content[Json])
+		return string(b), err
+	}
+	cases := trial.Cases[any, string]{
+		"oneOf": {
+			Input: OneOf(cat{Name: "tom"}, dog{Name: "rex"}),
+			Expected: `{"schema":{"oneOf":[{"$ref":"#/components/schemas/openapi.cat"},{"$ref":"#/components/schemas/openapi.dog"}]},` +
+				`"examples":{"openapi.cat":{"value":{"name":"tom","indoor":false}},"openapi.dog":{"value":{"name":"rex"}}}}`,
+		},
+		"anyOf": {
+			Input:    AnyOf("id", 1),
+			Expected: `{"schema":{"anyOf":[{"type":"string"},{"type":"integer"}]},"examples":{"":{"value":"id"},"1":{"value":1}}}`,
+		},
+		"allOf": {
+			Input: AllOf(dog{Name: "rex"}, map[string]any{"good": true}),
+			Expected: `{"schema":{"allOf":[{"$ref":"#/components/schemas/openapi.dog"},{"$ref":"#/components/schemas/336c3c3180000000"}]},` +
+				`"examples":{"openapi.dog_336c3c3180000000":{"value":{"good":true,"name":"rex"}}}}`,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	// a composition field
+	type owner struct {
+		Pet Composition `json:"pet"`
+	}
+	b, err := json.Marshal(buildSchema(owner{Pet: OneOf(cat{Name: "tom"}, dog{})}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(string(b), `{"title":"openapi.owner","type":"object","properties":{"pet":{"oneOf":[`+
+		`{"title":"openapi.cat","type":"object","properties":{"name":{"type":"string"},"indoor":{"type":"boolean"}}},`+
+		`{"title":"openapi.dog","type":"object","properties":{"name":{"type":"string"}}}]}}}`); !eq {
+		t.Error(diff)
+	}
+	if b, _ := json.Marshal(owner{Pet: OneOf(cat{Name: "tom"}, dog{})}); string(b) != `{"pet":{"name":"tom","indoor":false}}` {
+		t.Errorf("unexpected example %s", b)
+	}
+}
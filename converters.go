@@ -0,0 +1,279 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Converter creates the example value and schema of v for a media type from its json schema s.
+type Converter func(v any, s Schema) (example any, schema Schema, err error)
+
+var converters = struct {
+	sync.RWMutex
+	m map[MIMEType]Converter
+}{m: map[MIMEType]Converter{
+	Json:  jsonConverter,
+	Xml:   xmlConverter,
+	XForm: formConverter,
+	Text:  textConverter,
+}}
+
+// RegisterConverter sets the Converter used by WithRepresentations and WithContentExample for the media type.
+// Converters for json, xml, form-urlencoded and plain text are registered by default.
+func RegisterConverter(mime MIMEType, c Converter) {
+	converters.Lock()
+	converters.m[mime] = c
+	converters.Unlock()
+}
+
+// RegisterMIME registers a media type for WithContentExample and WithRepresentations.
+// encode writes the example as it is sent, such as base64 for a binary format,
+// and schemaFn creates the schema of the example. A nil encode keeps the example value
+// and a nil schemaFn uses the json schema of the example.
+//
+//	openapi.RegisterMIME("application/msgpack", func(v any) (string, error) {
+//		b, err := msgpack.Marshal(v)
+//		return base64.StdEncoding.EncodeToString(b), err
+//	}, nil)
+func RegisterMIME(mime MIMEType, encode func(any) (string, error), schemaFn func(any) Schema) {
+	RegisterConverter(mime, func(v any, s Schema) (any, Schema, error) {
+		if schemaFn != nil {
+			s = schemaFn(v)
+		}
+		if encode == nil {
+			return v, s, nil
+		}
+		str, err := encode(v)
+		return str, s, err
+	})
+}
+
+// converter returns the Converter of the media type. Media types with a
+// +json or +xml structured syntax suffix use the json or xml converter unless registered.
+func converter(mime MIMEType) (Converter, bool) {
+	converters.RLock()
+	defer converters.RUnlock()
+	if c, found := converters.m[mime]; found {
+		return c, true
+	}
+	base, _, _ := strings.Cut(string(mime), ";")
+	switch {
+	case strings.HasSuffix(base, "+json"):
+		return converters.m[Json], true
+	case strings.HasSuffix(base, "+xml"):
+		return converters.m[Xml], true
+	}
+	return nil, false
+}
+
+// WithContentExample adds the example to the Response as the media type,
+// the example is converted with the Converter registered for it.
+//
+//	Response{Status: 200}.WithContentExample("application/vnd.acme+json", user)
+func (r Response) WithContentExample(mime MIMEType, i any) Response {
+	r.Content = r.Content.addRepresentations(i, []MIMEType{mime})
+	return r
+}
+
+// WithContentExample adds the example to the RequestBody as the media type,
+// the example is converted with the Converter registered for it.
+func (r RequestBody) WithContentExample(mime MIMEType, i any) RequestBody {
+	r.Content = r.Content.addRepresentations(i, []MIMEType{mime})
+	return r
+}
+
+// WithContent adds the example to the Response as any media type, such as text/csv or application/pdf.
+// The example is converted with the Converter registered for the media type, see WithContentExample.
+// Without one a string is a text example, []byte is binary content and other values keep their json schema.
+//
+//	Response{Status: 200}.WithContent("text/csv", "id,name\n1,bob\n")
+func (r Response) WithContent(mime MIMEType, i any) Response {
+	r.Content = r.Content.addContent(mime, i)
+	return r
+}
+
+// WithContent adds the example to the RequestBody as any media type, see Response.WithContent.
+func (r RequestBody) WithContent(mime MIMEType, i any) RequestBody {
+	r.Content = r.Content.addContent(mime, i)
+	return r
+}
+
+// WithRepresentations adds the example to the Response for every media type,
+// each converted by the Converter registered for it.
+//
+//	Response{Status: 200}.WithRepresentations(user, openapi.Json, openapi.Xml)
+func (r Response) WithRepresentations(i any, mimes ...MIMEType) Response {
+	r.Content = r.Content.addRepresentations(i, mimes)
+	return r
+}
+
+// WithRepresentations adds the example to the RequestBody for every media type,
+// each converted by the Converter registered for it.
+func (r RequestBody) WithRepresentations(i any, mimes ...MIMEType) RequestBody {
+	r.Content = r.Content.addRepresentations(i, mimes)
+	return r
+}
+
+// addRepresentations converts the example to each media type. A media type without a Converter
+// or a failed conversion is reported by Compile.
+func (c Content) addRepresentations(i any, mimes []MIMEType) Content {
+	if c == nil {
+		c = make(Content)
+	}
+	s := buildSchema(i)
+	for _, mime := range mimes {
+		conv, found := converter(mime)
+		if !found {
+			c["invalid/json"] = Media{Examples: map[string]Example{"invalid": {Value: fmt.Sprintf("no converter for %v", mime)}}}
+			continue
+		}
+		v, schema, err := conv(i, s.clone())
+		if err != nil {
+			c["invalid/json"] = Media{Examples: map[string]Example{"invalid": {Value: fmt.Sprintf("%v: %v", mime, err)}}}
+			continue
+		}
+		m := c[mime]
+		if m.Schema.Title == "" && m.Schema.Ref == "" {
+			m.Schema = schema
+		}
+		m.addExample(s.Title, Example{Desc: s.Desc, Value: v})
+		c[mime] = m
+	}
+	return c
+}
+
+// addContent adds the example as the media type with its Converter,
+// or as text, binary or json content for a media type without one.
+func (c Content) addContent(mime MIMEType, i any) Content {
+	if _, found := converter(mime); found {
+		return c.addRepresentations(i, []MIMEType{mime})
+	}
+	if c == nil {
+		c = make(Content)
+	}
+	m := c[mime]
+	isSet := m.Schema.Type != "" || m.Schema.Ref != ""
+	switch v := i.(type) {
+	case string:
+		if !isSet {
+			m.Schema = Schema{Type: String}
+		}
+		m.addExample("", Example{Value: v})
+	case []byte:
+		if !isSet {
+			m.Schema = Schema{Type: String, Format: Binary}
+		}
+		// binary content can't be written in the document
+		if utf8.Valid(v) {
+			m.addExample("", Example{Value: string(v)})
+		}
+	default:
+		m.AddExample("", i)
+	}
+	c[mime] = m
+	return c
+}
+
+func jsonConverter(v any, s Schema) (any, Schema, error) {
+	return v, s, nil
+}
+
+func textConverter(v any, s Schema) (any, Schema, error) {
+	return fmt.Sprint(v), Schema{Type: String}, nil
+}
+
+// xmlConverter encodes the example as xml. Structs are encoded with encoding/xml,
+// other values by their generic json representation. The schema is named by the root element.
+func xmlConverter(v any, s Schema) (any, Schema, error) {
+	root := "root"
+	if t := reflect.TypeOf(v); t != nil {
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct && t.Name() != "" {
+			b, err := xml.MarshalIndent(v, "", "  ")
+			if err != nil {
+				return nil, s, err
+			}
+			// encoding/xml names the root element by the XMLName field or the type name
+			root = t.Name()
+			if end := bytes.IndexAny(b, " >"); end > 1 {
+				root = string(b[1:end])
+			}
+			s.XML = &XML{Name: root}
+			return string(b), s, nil
+		}
+	}
+
+	generic, _ := genericJSON(v)
+	var buf bytes.Buffer
+	writeXML(&buf, root, generic, "")
+	s.XML = &XML{Name: root}
+	return buf.String(), s, nil
+}
+
+var regexXMLName = regexp.MustCompile(`[^a-zA-Z0-9_.\-]`)
+
+// writeXML writes the generic json value v as an element
+func writeXML(buf *bytes.Buffer, name string, v any, indent string) {
+	name = regexXMLName.ReplaceAllString(name, "_")
+	switch t := v.(type) {
+	case map[string]any:
+		buf.WriteString(indent + "<" + name + ">\n")
+		for _, k := range sortedKeys(t) {
+			writeXML(buf, k, t[k], indent+"  ")
+		}
+		buf.WriteString(indent + "</" + name + ">\n")
+	case []any:
+		for _, val := range t {
+			writeXML(buf, name, val, indent)
+		}
+	case nil:
+		buf.WriteString(indent + "<" + name + "/>\n")
+	default:
+		buf.WriteString(indent + "<" + name + ">")
+		xml.EscapeText(buf, []byte(fmt.Sprint(t)))
+		buf.WriteString("</" + name + ">\n")
+	}
+}
+
+// formConverter encodes the example as application/x-www-form-urlencoded.
+// Nested objects use the deepObject style, user[name]=bob, and arrays repeat the key.
+func formConverter(v any, s Schema) (any, Schema, error) {
+	generic, _ := genericJSON(v)
+	m, ok := generic.(map[string]any)
+	if !ok {
+		return nil, s, fmt.Errorf("form example must be an object not %T", v)
+	}
+	values := make(url.Values)
+	formValues(values, "", m)
+	return values.Encode(), s, nil
+}
+
+func formValues(values url.Values, prefix string, v any) {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			key := k
+			if prefix != "" {
+				key = prefix + "[" + k + "]"
+			}
+			formValues(values, key, val)
+		}
+	case []any:
+		for _, val := range t {
+			formValues(values, prefix, val)
+		}
+	case nil:
+		values.Add(prefix, "")
+	default:
+		values.Add(prefix, fmt.Sprint(t))
+	}
+}
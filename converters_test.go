@@ -0,0 +1,198 @@
+package openapi
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestWithRepresentations(t *testing.T) {
+	type pet struct {
+		Name string   `json:"name" xml:"name"`
+		Tags []string `json:"tags" xml:"tag"`
+	}
+	p := pet{Name: "rex", Tags: []string{"dog", "good boy"}}
+	RegisterConverter("application/vnd.pet+json", func(v any, s Schema) (any, Schema, error) {
+		return nil, s, errors.New("unsupported")
+	})
+	t.Cleanup(func() {
+		converters.Lock()
+		delete(converters.m, "application/vnd.pet+json")
+		converters.Unlock()
+	})
+
+	type output struct {
+		Value  any
+		Schema Schema
+	}
+	fn := func(mime MIMEType) (output, error) {
+		c := Response{Status: 200}.WithRepresentations(p, Json, mime).Content
+		if m, found := c["invalid/json"]; found {
+			return output{}, errors.New(m.Examples["invalid"].Value.(string))
+		}
+		m := c[mime]
+		return output{Value: m.Examples["openapi.pet"].Value, Schema: m.Schema}, nil
+	}
+	schema := buildSchema(p)
+	xmlSchema := schema.clone()
+	xmlSchema.XML = &XML{Name: "pet"}
+	cases := trial.Cases[MIMEType, output]{
+		"json": {
+			Input:    Json,
+			Expected: output{Value: p, Schema: schema},
+		},
+		"xml": {
+			Input: Xml,
+			Expected: output{
+				Value:  "<pet>\n  <name>rex</name>\n  <tag>dog</tag>\n  <tag>good boy</tag>\n</pet>",
+				Schema: xmlSchema,
+			},
+		},
+		"form": {
+			Input:    XForm,
+			Expected: output{Value: "name=rex&tags=dog&tags=good+boy", Schema: schema},
+		},
+		"no converter": {
+			Input:       "application/yaml",
+			ExpectedErr: errors.New("no converter for application/yaml"),
+		},
+		"converter error": {
+			Input:       "application/vnd.pet+json",
+			ExpectedErr: errors.New("application/vnd.pet+json: unsupported"),
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	// maps are encoded from their generic json value
+	c := RequestBody{}.WithRepresentations(map[string]any{"user": map[string]any{"name": "bob", "id": 1}}, Xml, XForm).Content
+	for mime, want := range map[MIMEType]string{
+		Xml:   "<root>\n  <user>\n    <id>1</id>\n    <name>bob</name>\n  </user>\n</root>\n",
+		XForm: "user%5Bid%5D=1&user%5Bname%5D=bob",
+	} {
+		for _, ex := range c[mime].Examples {
+			if eq, diff := trial.Equal(ex.Value, want); !eq {
+				t.Errorf("%v: %v", mime, diff)
+			}
+		}
+	}
+
+	// the xml name is kept on the component schema
+	doc := New("", "", "")
+	doc.GetRoute("/pets", "get").AddResponse(Response{Status: 200}.WithRepresentations(p, Json, Xml))
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(doc.Components.Schemas["openapi.pet"].XML, &XML{Name: "pet"}); !eq {
+		t.Error(diff)
+	}
+}
+
+func TestWithContentExample(t *testing.T) {
+	RegisterMIME("application/msgpack", func(v any) (string, error) {
+		return base64.StdEncoding.EncodeToString([]byte(fmt.Sprint(v))), nil
+	}, func(any) Schema {
+		return Schema{Type: String, Format: "byte"}
+	})
+	RegisterMIME("application/vnd.acme.v2", nil, nil)
+	t.Cleanup(func() {
+		converters.Lock()
+		delete(converters.m, "application/msgpack")
+		delete(converters.m, "application/vnd.acme.v2")
+		converters.Unlock()
+	})
+
+	type output struct {
+		Value  any
+		Schema Schema
+	}
+	fn := func(mime MIMEType) (output, error) {
+		c := RequestBody{}.WithContentExample(mime, 42).Content
+		if m, found := c["invalid/json"]; found {
+			return output{}, errors.New(m.Examples["invalid"].Value.(string))
+		}
+		for _, ex := range c[mime].Examples {
+			return output{Value: ex.Value, Schema: c[mime].Schema}, nil
+		}
+		return output{}, errors.New("no example")
+	}
+	cases := trial.Cases[MIMEType, output]{
+		"encoded": {
+			Input:    "application/msgpack",
+			Expected: output{Value: "NDI=", Schema: Schema{Type: String, Format: "byte"}},
+		},
+		"registered without encoder": {
+			Input:    "application/vnd.acme.v2",
+			Expected: output{Value: 42, Schema: Schema{Type: Integer}},
+		},
+		"json suffix": {
+			Input:    "application/vnd.acme+json",
+			Expected: output{Value: 42, Schema: Schema{Type: Integer}},
+		},
+		"xml suffix": {
+			Input:    "application/atom+xml; charset=utf-8",
+			Expected: output{Value: "<root>42</root>\n", Schema: Schema{Type: Integer, XML: &XML{Name: "root"}}},
+		},
+		"unknown": {
+			Input:       "application/x-unknown",
+			ExpectedErr: errors.New("no converter for application/x-unknown"),
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
+func TestWithContent(t *testing.T) {
+	type input struct {
+		mime  MIMEType
+		value any
+	}
+	type user struct {
+		Name string `xml:"name" json:"name"`
+	}
+	fn := func(in input) (Media, error) {
+		resp := Response{Status: 200}.WithContent(in.mime, in.value)
+		req := RequestBody{}.WithContent(in.mime, in.value)
+		if eq, diff := trial.Equal(resp.Content, req.Content); !eq {
+			return Media{}, errors.New(diff)
+		}
+		return resp.Content[in.mime], nil
+	}
+	cases := trial.Cases[input, Media]{
+		"csv": {
+			Input: input{mime: "text/csv", value: "id,name\n1,bob\n"},
+			Expected: Media{
+				Schema:   Schema{Type: String},
+				Examples: map[string]Example{"": {Value: "id,name\n1,bob\n"}},
+			},
+		},
+		"pdf": {
+			Input:    input{mime: "application/pdf", value: []byte{0x25, 0x50, 0x44, 0x46, 0xff}},
+			Expected: Media{Schema: Schema{Type: String, Format: Binary}},
+		},
+		"text bytes": {
+			Input: input{mime: "application/yaml", value: []byte("name: bob\n")},
+			Expected: Media{
+				Schema:   Schema{Type: String, Format: Binary},
+				Examples: map[string]Example{"": {Value: "name: bob\n"}},
+			},
+		},
+		"json schema": {
+			Input: input{mime: "application/x-custom", value: user{Name: "bob"}},
+			Expected: Media{
+				Schema:   buildSchema(user{}),
+				Examples: map[string]Example{"openapi.user": {Value: user{Name: "bob"}}},
+				source:   user{Name: "bob"},
+			},
+		},
+		"converter": {
+			Input: input{mime: Xml, value: user{Name: "bob"}},
+			Expected: Media{
+				Schema:   Schema{Title: "openapi.user", Type: Object, Properties: map[string]Schema{"name": {Type: String}}, XML: &XML{Name: "user"}},
+				Examples: map[string]Example{"openapi.user": {Value: "<user>\n  <name>bob</name>\n</user>"}},
+			},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
@@ -0,0 +1,116 @@
+package openapi
+
+import "strings"
+
+// Coverage is the documentation quality of the operations of a document.
+// Each operation is checked for a summary, descriptions, examples, error responses and tags.
+type Coverage struct {
+	Score float64 // fraction of checks passed by all operations, 0 to 1
+
+	// number of operations passing each check
+	Summary        int
+	Description    int
+	Examples       int
+	ErrorResponses int
+	Tags           int
+
+	Routes []RouteCoverage // sorted by path and method
+}
+
+// RouteCoverage are the documentation checks of a single operation.
+type RouteCoverage struct {
+	Path   string
+	Method string
+
+	Summary        bool // the operation has a summary
+	Description    bool // all responses and parameters are described
+	Examples       bool // all request and response content has an example
+	ErrorResponses bool // a 4xx, 5xx or default response is documented
+	Tags           bool // the operation has a tag
+
+	Score float64 // fraction of checks passed, 0 to 1
+}
+
+// Missing returns the names of the checks the operation failed
+func (r RouteCoverage) Missing() []string {
+	var s []string
+	for _, c := range []struct {
+		name string
+		ok   bool
+	}{
+		{"summary", r.Summary},
+		{"description", r.Description},
+		{"examples", r.Examples},
+		{"error responses", r.ErrorResponses},
+		{"tags", r.Tags},
+	} {
+		if !c.ok {
+			s = append(s, c.name)
+		}
+	}
+	return s
+}
+
+// CoverageReport scores how well each operation of the document is documented.
+func (o *OpenAPI) CoverageReport() Coverage {
+	var c Coverage
+	for _, k := range sortedKeys(o.Paths) {
+		r := o.Paths[k]
+		path, method, _ := strings.Cut(k, "|")
+		rc := RouteCoverage{
+			Path:        path,
+			Method:      method,
+			Summary:     r.Summary != "",
+			Description: true,
+			Examples:    true,
+			Tags:        len(r.Tag) > 0,
+		}
+		for _, p := range r.Params {
+			rc.Description = rc.Description && (p.Desc != "" || p.Ref != "")
+		}
+		if r.Requests != nil {
+			rc.Examples = hasExamples(r.Requests.Content)
+		}
+		for code, resp := range r.Responses {
+			rc.Description = rc.Description && (resp.Desc != "" || resp.Ref != "")
+			rc.Examples = rc.Examples && hasExamples(resp.Content)
+			rc.ErrorResponses = rc.ErrorResponses || code >= 400 || code == DefaultStatus
+		}
+
+		passed := 0
+		for _, ok := range []bool{rc.Summary, rc.Description, rc.Examples, rc.ErrorResponses, rc.Tags} {
+			if ok {
+				passed++
+			}
+		}
+		rc.Score = float64(passed) / 5
+		c.Score += rc.Score
+		c.Routes = append(c.Routes, rc)
+		c.Summary += count(rc.Summary)
+		c.Description += count(rc.Description)
+		c.Examples += count(rc.Examples)
+		c.ErrorResponses += count(rc.ErrorResponses)
+		c.Tags += count(rc.Tags)
+	}
+	if len(c.Routes) > 0 {
+		c.Score /= float64(len(c.Routes))
+	}
+	return c
+}
+
+// hasExamples reports if every media type of the content has an example
+func hasExamples(c Content) bool {
+	for _, m := range c {
+		if len(m.Examples) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func count(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
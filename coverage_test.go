@@ -0,0 +1,39 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestCoverageReport(t *testing.T) {
+	doc := New("", "", "")
+	users := doc.GetRoute("/users", "get").Tags("users").
+		AddResponse(Response{Status: 200, Desc: "the users"}.WithExample([]string{"bob"})).
+		AddResponse(Response{Status: 404, Desc: "not found"})
+	users.Summary = "list users"
+	users.QueryParam("limit", 10, "max number of users")
+	doc.GetRoute("/users", "post").
+		AddRequest(RequestBody{Content: Content{Json: {Schema: Schema{Type: Object}}}}).
+		AddResponse(Response{Status: 201})
+
+	c := doc.CoverageReport()
+	eq, diff := trial.Equal(c, Coverage{
+		Score:          0.5,
+		Summary:        1,
+		Description:    1,
+		Examples:       1,
+		ErrorResponses: 1,
+		Tags:           1,
+		Routes: []RouteCoverage{
+			{Path: "/users", Method: "get", Summary: true, Description: true, Examples: true, ErrorResponses: true, Tags: true, Score: 1},
+			{Path: "/users", Method: "post"},
+		},
+	})
+	if !eq {
+		t.Error(diff)
+	}
+	if eq, diff := trial.Equal(c.Routes[1].Missing(), []string{"summary", "description", "examples", "error responses", "tags"}); !eq {
+		t.Error(diff)
+	}
+}
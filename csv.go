@@ -0,0 +1,63 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// CSVColumn describes a column of a csv response
+type CSVColumn struct {
+	Name    string
+	Type    Type // string by default
+	Desc    string
+	Example any // the value of the column in the example row
+}
+
+// WithCSV documents a text/csv response with a header row and the columns in order.
+// The schema is an array of rows with a property for each column and
+// the example is the header row followed by a row of the column examples.
+//
+//	Response{Status: 200, Desc: "users export"}.WithCSV([]openapi.CSVColumn{
+//		{Name: "id", Type: openapi.Integer, Example: 1},
+//		{Name: "email", Desc: "primary email", Example: "bob@example.com"},
+//	})
+func (r Response) WithCSV(columns []CSVColumn) Response {
+	if r.Content == nil {
+		r.Content = make(Content)
+	}
+	row := Schema{Type: Object, Properties: make(map[string]Schema, len(columns))}
+	names := make([]string, len(columns))
+	values := make([]string, len(columns))
+	hasExample := false
+	for i, c := range columns {
+		if c.Type == "" {
+			c.Type = String
+		}
+		row.Properties[c.Name] = Schema{Type: c.Type, Desc: c.Desc}
+		names[i] = c.Name
+		if c.Example != nil {
+			values[i] = fmt.Sprint(c.Example)
+			hasExample = true
+		}
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(names)
+	if hasExample {
+		w.Write(values)
+	}
+	w.Flush()
+
+	m := r.Content[CSV]
+	m.Schema = Schema{
+		Type:  Array,
+		Desc:  "csv with a header row, columns: " + strings.Join(names, ", "),
+		Items: &row,
+	}
+	m.addExample("rows", Example{Value: buf.String()})
+	r.Content[CSV] = m
+	return r
+}
@@ -0,0 +1,46 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestWithCSV(t *testing.T) {
+	fn := func(columns []CSVColumn) (Media, error) {
+		return Response{Status: 200}.WithCSV(columns).Content[CSV], nil
+	}
+	cases := trial.Cases[[]CSVColumn, Media]{
+		"columns": {
+			Input: []CSVColumn{
+				{Name: "id", Type: Integer, Example: 1},
+				{Name: "email", Desc: "primary email", Example: "bob@example.com"},
+				{Name: "note", Example: "likes, commas"},
+			},
+			Expected: Media{
+				Schema: Schema{
+					Type: Array,
+					Desc: "csv with a header row, columns: id, email, note",
+					Items: &Schema{Type: Object, Properties: map[string]Schema{
+						"id":    {Type: Integer},
+						"email": {Type: String, Desc: "primary email"},
+						"note":  {Type: String},
+					}},
+				},
+				Examples: map[string]Example{"rows": {Value: "id,email,note\n1,bob@example.com,\"likes, commas\"\n"}},
+			},
+		},
+		"header only": {
+			Input: []CSVColumn{{Name: "id"}},
+			Expected: Media{
+				Schema: Schema{
+					Type:  Array,
+					Desc:  "csv with a header row, columns: id",
+					Items: &Schema{Type: Object, Properties: map[string]Schema{"id": {Type: String}}},
+				},
+				Examples: map[string]Example{"rows": {Value: "id\n"}},
+			},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
@@ -0,0 +1,153 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// DedupSchemas is a CompileOption that merges the component schemas with the same structure,
+// such as anonymous structs with the same fields, into a single component and updates the refs to them.
+// The titles of the schemas are ignored when they are compared, the component named after a type
+// is kept over a generated name and otherwise the first name in order.
+//
+//	doc.Compile(openapi.DedupSchemas)
+func DedupSchemas(o *OpenAPI) error {
+	kept := make(map[string]string) // [canonical schema]name
+	renames := make(map[string]string)
+	names := sortedKeys(o.Components.Schemas)
+	// names of types first so they are kept
+	sorted := make([]string, 0, len(names))
+	for _, generated := range []bool{false, true} {
+		for _, name := range names {
+			if generatedTitle(name) == generated {
+				sorted = append(sorted, name)
+			}
+		}
+	}
+	for _, name := range sorted {
+		key, err := canonicalSchema(o.Components.Schemas[name])
+		if err != nil {
+			return err
+		}
+		if k, found := kept[key]; found {
+			renames[name] = k
+			continue
+		}
+		kept[key] = name
+	}
+	if len(renames) == 0 {
+		return nil
+	}
+	for name := range renames {
+		delete(o.Components.Schemas, name)
+		delete(o.schemaOrigins, name)
+	}
+	o.renameSchemaRefs(renames)
+	return nil
+}
+
+// renameSchemaRefs points the refs of the components and the contents, params and headers
+// of the document to the component schemas renamed from the key to the value
+func (o *OpenAPI) renameSchemaRefs(renames map[string]string) {
+	for name, s := range o.Components.Schemas {
+		renameRefs(&s, renames)
+		o.Components.Schemas[name] = s
+	}
+	contents := make([]Content, 0)
+	headers := []map[string]Header{o.Components.Headers}
+	params := []map[string]Param{o.Components.Parameters}
+	for _, r := range o.Components.RequestBodies {
+		contents = append(contents, r.Content)
+	}
+	for _, r := range o.Components.Responses {
+		contents = append(contents, r.Content)
+		headers = append(headers, r.Headers)
+	}
+	var routes func(router Router)
+	routes = func(router Router) {
+		for _, r := range router {
+			if r.Requests != nil {
+				contents = append(contents, r.Requests.Content)
+			}
+			for _, resp := range r.Responses {
+				contents = append(contents, resp.Content)
+				headers = append(headers, resp.Headers)
+			}
+			params = append(params, r.Params)
+			for _, cb := range r.Callbacks {
+				routes(cb)
+			}
+		}
+	}
+	routes(o.Paths)
+	routes(o.Webhooks)
+	for _, m := range params {
+		for k, p := range m {
+			// the schema may be shared with other params so it is copied
+			if p.Schema != nil {
+				s := *p.Schema
+				renameRefs(&s, renames)
+				p.Schema = &s
+				m[k] = p
+			}
+			contents = append(contents, p.Content)
+		}
+	}
+	for _, m := range headers {
+		for k, h := range m {
+			if h.Schema != nil {
+				s := *h.Schema
+				renameRefs(&s, renames)
+				h.Schema = &s
+				m[k] = h
+			}
+		}
+	}
+	for _, c := range contents {
+		for mime, m := range c {
+			renameRefs(&m.Schema, renames)
+			c[mime] = m
+		}
+	}
+}
+
+// canonicalSchema returns the json of the schema without the titles and the order of the properties
+func canonicalSchema(s Schema) (string, error) {
+	s = s.clone()
+	var clear func(s *Schema)
+	clear = func(s *Schema) {
+		s.Title, s.Order = "", nil
+		eachSchema(s, clear)
+	}
+	clear(&s)
+	b, err := json.Marshal(s)
+	return string(b), err
+}
+
+// renameRefs points the refs to the component schemas renamed from the key to the value
+func renameRefs(s *Schema, renames map[string]string) {
+	if name, found := strings.CutPrefix(s.Ref, "#/components/schemas/"); found {
+		if to, found := renames[name]; found {
+			s.Ref = "#/components/schemas/" + to
+		}
+	}
+	eachSchema(s, func(s *Schema) { renameRefs(s, renames) })
+}
+
+// eachSchema calls fn with the items, properties and composed schemas of s
+func eachSchema(s *Schema, fn func(s *Schema)) {
+	if s.Items != nil {
+		items := *s.Items
+		fn(&items)
+		s.Items = &items
+	}
+	for k, p := range s.Properties {
+		fn(&p)
+		s.Properties[k] = p
+	}
+	for _, l := range [][]Schema{s.OneOf, s.AnyOf, s.AllOf} {
+		for i := range l {
+			fn(&l[i])
+		}
+	}
+}
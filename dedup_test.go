@@ -0,0 +1,91 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestDedupSchemas(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	doc := New("", "", "")
+	doc.GetRoute("/users", "get").AddResponse(Response{Status: 200}.WithExample(user{Name: "bob"}))
+	doc.GetRoute("/users", "post").
+		AddRequest(RequestBody{}.WithExample(struct {
+			Name string `json:"name"`
+		}{Name: "bob"})).
+		AddResponse(Response{Status: 201}.WithExample(map[string]any{"name": "bob"}))
+	doc.GetRoute("/groups", "get").AddResponse(Response{Status: 200}.WithExample(struct {
+		Users []user `json:"users"`
+	}{}))
+	doc.GetRoute("/teams", "get").AddResponse(Response{Status: 200}.WithExample(struct {
+		Users []user `json:"users" desc:"members"`
+	}{}))
+	if err := doc.Compile(DedupSchemas); err != nil {
+		t.Fatal(err)
+	}
+
+	if eq, diff := trial.Equal(len(doc.Components.Schemas), 3); !eq {
+		t.Error(diff, sortedKeys(doc.Components.Schemas))
+	}
+	fn := func(key string) (string, error) {
+		r := doc.Paths[key]
+		if r.Requests != nil {
+			return r.Requests.Content[Json].Schema.Ref, nil
+		}
+		return r.Responses[sortedCodes(r.Responses)[0]].Content[Json].Schema.Ref, nil
+	}
+	cases := trial.Cases[string, string]{
+		"type": {
+			Input:    "/users|get",
+			Expected: "#/components/schemas/openapi.user",
+		},
+		"anonymous struct": {
+			Input:    "/users|post",
+			Expected: "#/components/schemas/openapi.user",
+		},
+		"different description": {
+			Input:    "/teams|get",
+			Expected: `#/components/schemas/struct { Users []openapi.user "json:\"users\" desc:\"members\"" }`,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+	if eq, diff := trial.Equal(doc.Paths["/users|post"].Responses[201].Content[Json].Schema.Ref, "#/components/schemas/openapi.user"); !eq {
+		t.Error(diff)
+	}
+}
+
+func TestDedupSchemasParams(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	anonymous := struct {
+		Name string `json:"name"`
+	}{Name: "bob"}
+	ref := &Schema{Ref: "#/components/schemas/" + buildSchema(anonymous).Title}
+	doc := New("", "", "")
+	doc.GetRoute("/users", "get").AddResponse(Response{Status: 200}.WithExample(user{Name: "bob"}))
+	r := doc.GetRoute("/users", "post").
+		AddRequest(RequestBody{}.WithExample(anonymous)).
+		AddResponse(Response{Status: 201, Headers: map[string]Header{"X-User": {Schema: ref}}})
+	r.Params = Params{"query|filter": {Name: "filter", In: "query", Schema: ref}}
+	doc.Components.Parameters = map[string]Param{"user": {Name: "user", In: "query", Schema: ref}}
+	doc.Components.Headers = map[string]Header{"X-User": {Schema: ref}}
+	if err := doc.Compile(DedupSchemas); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "#/components/schemas/openapi.user"
+	for name, got := range map[string]string{
+		"param":            r.Params["query|filter"].Schema.Ref,
+		"header":           r.Responses[201].Headers["X-User"].Schema.Ref,
+		"component param":  doc.Components.Parameters["user"].Schema.Ref,
+		"component header": doc.Components.Headers["X-User"].Schema.Ref,
+	} {
+		if got != expected {
+			t.Errorf("%v: expected %q got %q", name, expected, got)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package openapi
+
+import (
+	"reflect"
+	"time"
+)
+
+// DurationFormat is how time.Duration values are serialized by the API, see SetDurationFormat.
+type DurationFormat int
+
+const (
+	// DurationNanoseconds is an integer of nanoseconds, the encoding/json default
+	DurationNanoseconds DurationFormat = iota
+	// DurationISO8601 is an ISO 8601 duration string such as PT1H30M
+	DurationISO8601
+	// DurationString is the time.Duration.String format such as 1h30m0s
+	DurationString
+)
+
+// durationPattern matches the strings of time.ParseDuration
+const durationPattern = `^[-+]?(0|([0-9]+(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+)$`
+
+// SetDurationFormat sets the schema of time.Duration fields to the format the API writes them in.
+// It overrides the schema of time.Duration in this document only and must be set before the routes are added,
+// see OverrideSchema.
+//
+//	doc.SetDurationFormat(openapi.DurationString) // {"type":"string","pattern":"^[-+]?(0|..."}
+func (o *OpenAPI) SetDurationFormat(f DurationFormat) {
+	o.OverrideSchema(reflect.TypeOf(time.Duration(0)), durationSchema(f))
+}
+
+// durationSchema returns the schema of a time.Duration written in the format f
+func durationSchema(f DurationFormat) Schema {
+	switch f {
+	case DurationISO8601:
+		return Schema{Type: String, Format: "duration", Example: "PT1H30M"}
+	case DurationString:
+		return Schema{Type: String, Pattern: durationPattern, Example: "1h30m0s"}
+	}
+	return Schema{Type: Integer, Format: Int64}
+}
@@ -0,0 +1,65 @@
+package openapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hydronica/trial"
+)
+
+func TestSetDurationFormat(t *testing.T) {
+	type job struct {
+		Timeout time.Duration `json:"timeout"`
+	}
+	fn := func(f DurationFormat) (Schema, error) {
+		doc := New("", "", "")
+		doc.SetDurationFormat(f)
+		doc.GetRoute("/jobs", "post").AddRequest(RequestBody{}.WithExample(job{}))
+		if err := doc.Compile(); err != nil {
+			return Schema{}, err
+		}
+		return doc.Components.Schemas["openapi.job"].Properties["timeout"], nil
+	}
+	cases := trial.Cases[DurationFormat, Schema]{
+		"nanoseconds": {
+			Input:    DurationNanoseconds,
+			Expected: Schema{Type: Integer, Format: Int64},
+		},
+		"iso 8601": {
+			Input:    DurationISO8601,
+			Expected: Schema{Type: String, Format: "duration", Example: "PT1H30M"},
+		},
+		"string": {
+			Input:    DurationString,
+			Expected: Schema{Type: String, Pattern: durationPattern, Example: "1h30m0s"},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
+func TestDurationFormatDocument(t *testing.T) {
+	build := func(f *DurationFormat) *OpenAPI {
+		doc := New("", "", "")
+		if f != nil {
+			doc.SetDurationFormat(*f)
+		}
+		doc.GetRoute("/jobs", "get").QueryParam("timeout", 90*time.Minute, "how long to wait")
+		if err := doc.Compile(); err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+	iso := DurationISO8601
+	doc, other := build(&iso), build(nil)
+	got := []*Schema{
+		doc.GetRoute("/jobs", "get").Params["query|timeout"].Schema,
+		other.GetRoute("/jobs", "get").Params["query|timeout"].Schema,
+	}
+	eq, diff := trial.Equal(got, []*Schema{
+		{Type: String, Format: "duration", Example: "PT1H30M"},
+		{Type: Integer, Format: Int64},
+	})
+	if !eq {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,314 @@
+package openapi
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// MarshalOptions control the json output of the document
+type MarshalOptions struct {
+	Indent     string // indentation of each level, compact output when empty
+	EscapeHTML bool   // escape <, > and & in strings as \u003c, \u003e and \u0026
+	OmitEmpty  bool   // omit fields with an empty object value, example values are kept
+}
+
+// defaultMarshal are the options of WriteJSON and JSONBytes
+var defaultMarshal = MarshalOptions{Indent: "    ", EscapeHTML: true}
+
+// WriteJSON encodes the OpenAPI object as indented json to w.
+// The document is encoded one section at a time and the paths
+// one path at a time, so the complete output is never held in memory.
+func (o *OpenAPI) WriteJSON(w io.Writer) error {
+	return o.writeJSON(w, nil, defaultMarshal)
+}
+
+// JSONWith returns the json encoding of the document formatted with the options.
+//
+//	b, err := doc.JSONWith(openapi.MarshalOptions{OmitEmpty: true}) // compact
+func (o *OpenAPI) JSONWith(opts MarshalOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	err := o.writeJSON(&buf, nil, opts)
+	return buf.Bytes(), err
+}
+
+// writeJSON encodes the document to w, paths found in pathRefs are written as a $ref
+func (o *OpenAPI) writeJSON(w io.Writer, pathRefs map[string]string, opts MarshalOptions) error {
+	e := &jsonWriter{w: bufio.NewWriter(w), opts: opts, pathRefs: pathRefs, spec31: o.is31()}
+	e.write("{")
+	e.field("openapi", o.Version)
+	if len(o.Servers) > 0 {
+		e.field("servers", o.Servers)
+	}
+	e.field("info", o.Info)
+	if o.JSONSchemaDialect != "" {
+		e.field("jsonSchemaDialect", o.JSONSchemaDialect)
+	}
+	if len(o.Tags) > 0 {
+		e.field("tags", o.sortedTags())
+	}
+	e.paths(o.Paths, o.orderPaths, o.orderMethods)
+	if len(o.Webhooks) > 0 {
+		e.field("webhooks", o.Webhooks)
+	}
+	e.field("components", o.Components)
+	if len(o.Security) > 0 {
+		e.field("security", o.Security)
+	}
+	if o.ExternalDocs != nil {
+		e.field("externalDocs", o.ExternalDocs)
+	}
+	for _, k := range sortedKeys(o.Extensions) {
+		e.field(k, o.Extensions[k])
+	}
+	e.newline(0)
+	e.write("}")
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+// jsonWriter writes the fields of a json object and keeps the
+// first error that occurred.
+type jsonWriter struct {
+	w      *bufio.Writer
+	opts   MarshalOptions
+	fields int // number of fields written to the current object
+	err    error
+
+	pathRefs map[string]string // paths written as a $ref to another file
+	spec31   bool              // schemas are written with the 3.1 semantics
+}
+
+func (e *jsonWriter) write(s ...string) {
+	for _, v := range s {
+		if e.err != nil {
+			return
+		}
+		_, e.err = e.w.WriteString(v)
+	}
+}
+
+// newline starts a new line at the given depth of indented output
+func (e *jsonWriter) newline(depth int) {
+	if e.opts.Indent != "" {
+		e.write("\n", strings.Repeat(e.opts.Indent, depth))
+	}
+}
+
+// key writes the separator and the name of the next field at the given depth
+func (e *jsonWriter) key(name string, depth int) {
+	if e.fields > 0 {
+		e.write(",")
+	}
+	e.fields++
+	k, _ := json.Marshal(name)
+	e.newline(depth)
+	e.write(string(k), ":")
+	if e.opts.Indent != "" {
+		e.write(" ")
+	}
+}
+
+// field writes a top level field of the document, empty objects are skipped with OmitEmpty
+func (e *jsonWriter) field(name string, v any) {
+	b := e.encode(v, 1)
+	if e.opts.OmitEmpty && string(b) == "{}" {
+		return
+	}
+	e.key(name, 1)
+	e.writeBytes(b)
+}
+
+// value writes v as json at the given depth
+func (e *jsonWriter) value(v any, depth int) {
+	e.writeBytes(e.encode(v, depth))
+}
+
+func (e *jsonWriter) writeBytes(b []byte) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = e.w.Write(b)
+}
+
+// encode v with the options of the writer, indented for the given depth
+func (e *jsonWriter) encode(v any, depth int) []byte {
+	if e.err != nil {
+		return nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(e.opts.EscapeHTML)
+	if e.err = enc.Encode(v); e.err != nil {
+		return nil
+	}
+	b := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	if !e.opts.EscapeHTML {
+		// types with a MarshalJSON method are escaped by json.Marshal
+		b = unescapeHTML(b)
+	}
+	if e.spec31 {
+		if b, e.err = to31(b, false); e.err != nil {
+			return nil
+		}
+	}
+	if e.opts.OmitEmpty {
+		if b, e.err = omitEmpty(b); e.err != nil {
+			return nil
+		}
+	}
+	if e.opts.Indent == "" {
+		return b
+	}
+	var out bytes.Buffer
+	if e.err = json.Indent(&out, b, strings.Repeat(e.opts.Indent, depth), e.opts.Indent); e.err != nil {
+		return nil
+	}
+	return out.Bytes()
+}
+
+// unescapeHTML replaces the \u003c, \u003e and \u0026 escapes of json strings with <, > and &.
+// An escaped backslash followed by u003c is kept.
+func unescapeHTML(b []byte) []byte {
+	if !bytes.Contains(b, []byte(`\u00`)) {
+		return b
+	}
+	out := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		if b[i] != '\\' || i+1 >= len(b) {
+			out = append(out, b[i])
+			continue
+		}
+		if b[i+1] == 'u' && i+6 <= len(b) {
+			switch string(b[i+2 : i+6]) {
+			case "003c":
+				out, i = append(out, '<'), i+5
+				continue
+			case "003e":
+				out, i = append(out, '>'), i+5
+				continue
+			case "0026":
+				out, i = append(out, '&'), i+5
+				continue
+			}
+		}
+		// keep the escape sequence and the escaped character
+		out, i = append(out, b[i], b[i+1]), i+1
+	}
+	return out
+}
+
+// omitEmpty removes the fields with an empty object value from the compact json b.
+// The order of the fields is kept, elements of arrays are never removed
+// and the values of examples, enums and defaults are written as is.
+func omitEmpty(b []byte) ([]byte, error) {
+	if len(b) == 0 || (b[0] != '{' && b[0] != '[') {
+		return b, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if b[0] == '[' {
+		buf.WriteByte('[')
+		for i := 0; dec.More(); i++ {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return nil, err
+			}
+			v, err := omitEmpty(raw)
+			if err != nil {
+				return nil, err
+			}
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(v)
+		}
+		buf.WriteByte(']')
+		return buf.Bytes(), nil
+	}
+
+	buf.WriteByte('{')
+	n := 0
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key := t.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		v := []byte(raw)
+		switch key {
+		case "value", "example", "enum", "default":
+		default:
+			if v, err = omitEmpty(raw); err != nil {
+				return nil, err
+			}
+			if string(v) == "{}" {
+				continue
+			}
+		}
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		n++
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// paths writes the Router in the order of the paths returned by order and the methods of
+// each path in the order returned by methods, each operation is encoded separately.
+func (e *jsonWriter) paths(r Router, order func(paths map[string]map[string]*Route) []string, methods func(path string, routes map[string]*Route) []string) {
+	e.key("paths", 1)
+	paths := make(map[string]map[string]*Route)
+	for k, v := range r {
+		path, method, _ := strings.Cut(k, "|")
+		if paths[path] == nil {
+			paths[path] = make(map[string]*Route)
+		}
+		paths[path][method] = v
+	}
+	if len(paths) == 0 {
+		e.write("{}")
+		return
+	}
+	keys := order(paths)
+
+	e.write("{")
+	fields := e.fields
+	e.fields = 0
+	for _, k := range keys {
+		e.key(k, 2)
+		if ref, found := e.pathRefs[k]; found {
+			e.value(reference{Ref: ref}, 2)
+			continue
+		}
+		e.write("{")
+		n := e.fields
+		e.fields = 0
+		for _, m := range methods(k, paths[k]) {
+			e.key(m, 3)
+			e.value(paths[k][m], 3)
+		}
+		e.fields = n
+		e.newline(2)
+		e.write("}")
+	}
+	e.fields = fields
+	e.newline(1)
+	e.write("}")
+}
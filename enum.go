@@ -0,0 +1,186 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// EnumFromConsts returns an enum schema of the named type T with the values of its constants
+// declared in the Go package in dir. The doc comment of the type is the description of the schema,
+// the comments of the constants describe each value and their names are set as x-enum-varnames.
+// Use OpenAPI.OverrideSchema to apply the schema to every field of type T.
+//
+//	// Status of an order
+//	type Status string
+//
+//	const (
+//		Pending Status = "pending" // waiting for payment
+//		Shipped Status = "shipped" // on its way to the customer
+//	)
+//
+//	s, err := openapi.EnumFromConsts[Status]("./order")
+//	doc.OverrideSchema(reflect.TypeOf(order.Pending), s)
+func EnumFromConsts[T any](dir string) (Schema, error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Name() == "" {
+		return Schema{}, fmt.Errorf("%v is not a named type", typ)
+	}
+	s := defaultSchemas.reflectSchema(reflect.Zero(typ).Interface(), nil)
+	switch s.Type {
+	case String, Integer, Number, Boolean:
+	default:
+		return Schema{}, fmt.Errorf("%v is not a primitive type", typ)
+	}
+
+	fset := token.NewFileSet()
+	pkgs, err := parsePackages(fset, dir)
+	if err != nil {
+		return Schema{}, err
+	}
+	for _, name := range sortedKeys(pkgs) {
+		consts, doc, found, err := enumConsts(fset, name, pkgs[name], typ.Name())
+		if err != nil {
+			return Schema{}, err
+		}
+		if !found {
+			continue
+		}
+		if len(consts) == 0 {
+			return Schema{}, fmt.Errorf("no constants of type %v in %v", typ.Name(), dir)
+		}
+		s.Desc = doc
+		var names, descs []string
+		for _, c := range consts {
+			s.Enum = append(s.Enum, c.value)
+			names = append(names, c.name)
+			if c.doc != "" {
+				descs = append(descs, fmt.Sprintf("- `%v`: %v", c.value, c.doc))
+			}
+		}
+		if len(descs) > 0 {
+			s.Desc = strings.TrimSpace(s.Desc + "\n\n" + strings.Join(descs, "\n"))
+		}
+		return s.SetExtension("enum-varnames", names), nil
+	}
+	return Schema{}, fmt.Errorf("type %v not found in %v", typ.Name(), dir)
+}
+
+// parsePackages parses the go files of the directory grouped by package name,
+// the files of a package are sorted by name.
+func parsePackages(fset *token.FileSet, dir string) (map[string][]*ast.File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	pkgs := make(map[string][]*ast.File)
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".go" {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, e.Name()), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		pkgs[f.Name.Name] = append(pkgs[f.Name.Name], f)
+	}
+	return pkgs, nil
+}
+
+type enumConst struct {
+	name  string
+	value any
+	doc   string
+}
+
+// enumConsts type checks the package and returns the constants of the type in order of declaration
+// and the doc comment of the type. Errors of the type checker such as missing imports are ignored
+// as the constants are evaluated regardless.
+func enumConsts(fset *token.FileSet, pkg string, files []*ast.File, typeName string) (consts []enumConst, doc string, found bool, err error) {
+	conf := types.Config{Error: func(error) {}}
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	tpkg, _ := conf.Check(pkg, fset, files, info)
+	if tpkg == nil {
+		return nil, "", false, nil
+	}
+	obj, ok := tpkg.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return nil, "", false, nil
+	}
+
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if info.Defs[spec.Name] == obj {
+						doc = commentText(spec.Doc)
+						if doc == "" && len(gen.Specs) == 1 {
+							doc = commentText(gen.Doc)
+						}
+					}
+				case *ast.ValueSpec:
+					if gen.Tok != token.CONST {
+						continue
+					}
+					for _, ident := range spec.Names {
+						c, ok := info.Defs[ident].(*types.Const)
+						if !ok || ident.Name == "_" || !types.Identical(c.Type(), obj.Type()) {
+							continue
+						}
+						v, err := constValue(c.Val())
+						if err != nil {
+							return nil, "", true, fmt.Errorf("%v: %w", ident.Name, err)
+						}
+						d := commentText(spec.Doc)
+						if d == "" {
+							d = commentText(spec.Comment)
+						}
+						consts = append(consts, enumConst{name: ident.Name, value: v, doc: d})
+					}
+				}
+			}
+		}
+	}
+	return consts, doc, true, nil
+}
+
+// constValue converts the constant into a go value
+func constValue(v constant.Value) (any, error) {
+	switch v.Kind() {
+	case constant.String:
+		return constant.StringVal(v), nil
+	case constant.Bool:
+		return constant.BoolVal(v), nil
+	case constant.Int:
+		if i, exact := constant.Int64Val(v); exact {
+			return i, nil
+		}
+		if u, exact := constant.Uint64Val(v); exact {
+			return u, nil
+		}
+	case constant.Float:
+		f, _ := constant.Float64Val(v)
+		return f, nil
+	}
+	return nil, errors.New("unsupported constant " + v.String())
+}
+
+func commentText(c *ast.CommentGroup) string {
+	if c == nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.ReplaceAll(c.Text(), "\n", " "))
+}
@@ -0,0 +1,70 @@
+package openapi
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hydronica/trial"
+)
+
+// testColor is a color used by TestEnumFromConsts
+type testColor string
+
+const (
+	colorRed testColor = "red" // the color red
+	// the color green
+	colorGreen testColor = "green"
+	colorBlue  testColor = "blue"
+)
+
+type testLevel int
+
+const (
+	levelLow testLevel = iota + 1
+	levelHigh
+	_
+	levelMax = levelHigh * 10
+)
+
+func TestEnumFromConsts(t *testing.T) {
+	fn := func(enum func(dir string) (Schema, error)) (Schema, error) {
+		return enum(".")
+	}
+	cases := trial.Cases[func(string) (Schema, error), Schema]{
+		"string": {
+			Input: EnumFromConsts[testColor],
+			Expected: Schema{
+				Type: String,
+				Desc: "testColor is a color used by TestEnumFromConsts\n\n- `red`: the color red\n- `green`: the color green",
+				Enum: []any{string(colorRed), string(colorGreen), string(colorBlue)},
+				Extensions: Extensions{
+					"x-enum-varnames": []string{"colorRed", "colorGreen", "colorBlue"},
+				},
+			},
+		},
+		"iota": {
+			Input: EnumFromConsts[testLevel],
+			Expected: Schema{
+				Type: Integer,
+				Enum: []any{int64(levelLow), int64(levelHigh), int64(levelMax)},
+				Extensions: Extensions{
+					"x-enum-varnames": []string{"levelLow", "levelHigh", "levelMax"},
+				},
+			},
+		},
+		"not in package": {
+			Input:       EnumFromConsts[time.Duration],
+			ExpectedErr: errors.New("type Duration not found in ."),
+		},
+		"not named": {
+			Input:       EnumFromConsts[[]string],
+			ExpectedErr: errors.New("[]string is not a named type"),
+		},
+		"not primitive": {
+			Input:       EnumFromConsts[Route],
+			ExpectedErr: errors.New("openapi.Route is not a primitive type"),
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
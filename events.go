@@ -0,0 +1,70 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// WithEvent documents a Server-Sent Event of a text/event-stream response.
+// The schema of the stream is an array of events with the event name, id, retry and data fields,
+// the schema of the payload of each event is listed in the x-sse-events extension.
+// The example of the event is written as it is sent on the stream.
+//
+//	Response{Status: 200, Desc: "order updates"}.
+//		WithEvent("created", Order{ID: 1}).
+//		WithEvent("shipped", Shipment{OrderID: 1})
+func (r Response) WithEvent(event string, payload any) Response {
+	r.Content = r.Content.addEvent(event, payload)
+	return r
+}
+
+// addEvent adds the event to the text/event-stream Media of the content
+func (c Content) addEvent(event string, payload any) Content {
+	if c == nil {
+		c = make(Content)
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		c["invalid/json"] = Media{Examples: map[string]Example{"invalid": {Value: fmt.Sprintf("event %v: %v", event, err)}}}
+		return c
+	}
+
+	m := c[Events]
+	prev, _ := m.Schema.Extensions["x-sse-events"].(map[string]Schema)
+	events := make(map[string]Schema, len(prev)+1)
+	for k, v := range prev {
+		events[k] = v
+	}
+	events[event] = buildSchema(payload)
+
+	// the payload schema is only set on the data field when all events share it
+	names := sortedKeys(events)
+	data := events[names[0]]
+	for _, name := range names[1:] {
+		if !sameJSON(events[name], data) {
+			data = Schema{Desc: "the payload of the event, see x-sse-events"}
+			break
+		}
+	}
+	enum := make([]any, len(names))
+	for i, name := range names {
+		enum[i] = name
+	}
+	m.Schema = Schema{
+		Type: Array,
+		Desc: "stream of server-sent events",
+		Items: &Schema{
+			Type: Object,
+			Properties: map[string]Schema{
+				"event": {Type: String, Enum: enum},
+				"id":    {Type: String},
+				"retry": {Type: Integer},
+				"data":  data,
+			},
+		},
+		Extensions: Extensions{"x-sse-events": events},
+	}
+	m.addExample(event, Example{Value: "event: " + event + "\ndata: " + string(b) + "\n\n"})
+	c[Events] = m
+	return c
+}
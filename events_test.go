@@ -0,0 +1,77 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestWithEvent(t *testing.T) {
+	type order struct {
+		ID int `json:"id"`
+	}
+	type shipment struct {
+		OrderID int    `json:"order_id"`
+		Carrier string `json:"carrier"`
+	}
+	type event struct {
+		name    string
+		payload any
+	}
+	fn := func(events []event) (Media, error) {
+		r := Response{Status: 200}
+		for _, e := range events {
+			r = r.WithEvent(e.name, e.payload)
+		}
+		return r.Content[Events], nil
+	}
+	orderSchema := buildSchema(order{})
+	shipmentSchema := buildSchema(shipment{})
+	cases := trial.Cases[[]event, Media]{
+		"same payload": {
+			Input: []event{{"created", order{ID: 1}}, {"updated", order{ID: 1}}},
+			Expected: Media{
+				Schema: Schema{
+					Type: Array,
+					Desc: "stream of server-sent events",
+					Items: &Schema{Type: Object, Properties: map[string]Schema{
+						"event": {Type: String, Enum: []any{"created", "updated"}},
+						"id":    {Type: String},
+						"retry": {Type: Integer},
+						"data":  orderSchema,
+					}},
+					Extensions: Extensions{"x-sse-events": map[string]Schema{"created": orderSchema, "updated": orderSchema}},
+				},
+				Examples: map[string]Example{
+					"created": {Value: "event: created\ndata: {\"id\":1}\n\n"},
+					"updated": {Value: "event: updated\ndata: {\"id\":1}\n\n"},
+				},
+			},
+		},
+		"different payloads": {
+			Input: []event{{"created", order{ID: 1}}, {"shipped", shipment{OrderID: 1, Carrier: "ups"}}},
+			Expected: Media{
+				Schema: Schema{
+					Type: Array,
+					Desc: "stream of server-sent events",
+					Items: &Schema{Type: Object, Properties: map[string]Schema{
+						"event": {Type: String, Enum: []any{"created", "shipped"}},
+						"id":    {Type: String},
+						"retry": {Type: Integer},
+						"data":  {Desc: "the payload of the event, see x-sse-events"},
+					}},
+					Extensions: Extensions{"x-sse-events": map[string]Schema{"created": orderSchema, "shipped": shipmentSchema}},
+				},
+				Examples: map[string]Example{
+					"created": {Value: "event: created\ndata: {\"id\":1}\n\n"},
+					"shipped": {Value: "event: shipped\ndata: {\"order_id\":1,\"carrier\":\"ups\"}\n\n"},
+				},
+			},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	if _, found := (Response{}).WithEvent("bad", make(chan int)).Content["invalid/json"]; !found {
+		t.Error("expected invalid/json for a payload that cannot be encoded")
+	}
+}
@@ -0,0 +1,297 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// ExampleLimits caps the size of the examples in the document,
+// a zero value means no limit.
+type ExampleLimits struct {
+	MaxItems   int  // arrays are truncated to MaxItems elements
+	MaxBytes   int  // strings are truncated to MaxBytes bytes
+	SchemaOnly bool // remove all examples and only keep the schemas
+}
+
+// LimitExamples sets the limits applied to the request and response
+// examples of every route when the document is compiled.
+func (o *OpenAPI) LimitExamples(l ExampleLimits) {
+	o.exampleLimits = &l
+	o.invalidate()
+}
+
+// limitExamples truncates the examples of the media to the limits of the document
+func (o *OpenAPI) limitExamples(m *Media) {
+	l := o.exampleLimits
+	if l == nil || len(m.Examples) == 0 {
+		return
+	}
+	if l.SchemaOnly {
+		m.Examples = nil
+		return
+	}
+	if l.MaxItems <= 0 && l.MaxBytes <= 0 {
+		return
+	}
+	for k, ex := range m.Examples {
+		if ex.Ref != "" {
+			continue
+		}
+		v, err := ex.Decode()
+		if err != nil {
+			continue
+		}
+		ex.Value = l.truncate(v)
+		m.Examples[k] = ex
+	}
+}
+
+// truncate the arrays and strings of v, structs are converted
+// to their generic json representation to be truncated.
+func (l ExampleLimits) truncate(v any) any {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case string:
+		if l.MaxBytes <= 0 || len(t) <= l.MaxBytes {
+			return t
+		}
+		i := l.MaxBytes
+		for i > 0 && !utf8.RuneStart(t[i]) {
+			i--
+		}
+		return t[:i]
+	case []any:
+		if l.MaxItems > 0 && len(t) > l.MaxItems {
+			t = t[:l.MaxItems]
+		}
+		s := make([]any, len(t))
+		for i, v := range t {
+			s[i] = l.truncate(v)
+		}
+		return s
+	case map[string]any:
+		m := make(map[string]any, len(t))
+		for k, v := range t {
+			m[k] = l.truncate(v)
+		}
+		return m
+	}
+
+	if generic, ok := genericJSON(v); ok {
+		return l.truncate(generic)
+	}
+	return v
+}
+
+// genericJSON converts structs, maps, slices and raw json into their generic json
+// representation, false is returned for any other value.
+func genericJSON(v any) (any, bool) {
+	raw, isRaw := v.(json.RawMessage)
+	if !isRaw {
+		switch reflect.Indirect(reflect.ValueOf(v)).Kind() {
+		case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		default:
+			return v, false
+		}
+		var err error
+		if raw, err = json.Marshal(v); err != nil {
+			return v, false
+		}
+	}
+	var generic any
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return v, false
+	}
+	return generic, true
+}
+
+// RegisterExample adds the example to the components with the given name,
+// use WithExampleRef to reference it from responses and requests instead of repeating it.
+//
+//	doc.RegisterExample("StandardError", ErrorResponse{Code: 500, Message: "internal error"})
+//	Response{Status: 500}.WithExampleRef("StandardError")
+func (o *OpenAPI) RegisterExample(name string, v any) {
+	if o.Components.Examples == nil {
+		o.Components.Examples = make(map[string]Example)
+	}
+	o.Components.Examples[name] = Example{Value: v}
+	o.invalidate()
+}
+
+// WithExampleRef adds a reference to an example registered with RegisterExample to the json Content of the Response.
+// The schema of the content is built from the registered example during Compile if it is not set.
+func (r Response) WithExampleRef(name string) Response {
+	r.Content = r.Content.addExampleRef(Json, name)
+	return r
+}
+
+// WithExampleRef adds a reference to an example registered with RegisterExample to the json Content of the RequestBody.
+func (r RequestBody) WithExampleRef(name string) RequestBody {
+	r.Content = r.Content.addExampleRef(Json, name)
+	return r
+}
+
+func (c Content) addExampleRef(mime MIMEType, name string) Content {
+	if c == nil {
+		c = make(Content)
+	}
+	m := c[mime]
+	m.addExample(name, Example{Ref: "#/components/examples/" + name})
+	c[mime] = m
+	return c
+}
+
+// ShareExamples is a CompileOption that moves the examples used by more than one request or response
+// into the components, the content references them with a $ref to #/components/examples/{name}.
+// The component is named after the first example found by path, method and status.
+func ShareExamples(o *OpenAPI) error {
+	if o.Components.Examples == nil {
+		o.Components.Examples = make(map[string]Example)
+	}
+	examples := newPromoter(o.Components.Examples, "#/components/examples/")
+	// each media of the routes in order
+	media := func(fn func(m *Media, name string)) {
+		for _, k := range sortedKeys(o.Paths) {
+			r := o.Paths[k]
+			contents := make([]Content, 0, len(r.Responses)+1)
+			if r.Requests != nil {
+				contents = append(contents, r.Requests.Content)
+			}
+			for _, code := range sortedCodes(r.Responses) {
+				contents = append(contents, r.Responses[code].Content)
+			}
+			for _, c := range contents {
+				for _, mime := range sortedKeys(c) {
+					m := c[mime]
+					for _, name := range sortedKeys(m.Examples) {
+						fn(&m, name)
+					}
+					c[mime] = m
+				}
+			}
+		}
+	}
+	media(func(m *Media, name string) {
+		examples.count(m.Examples[name], m.Examples[name].Ref)
+	})
+	media(func(m *Media, name string) {
+		if ref := examples.ref(m.Examples[name], m.Examples[name].Ref, name); ref != "" {
+			m.Examples[name] = Example{Ref: ref}
+		}
+	})
+	return nil
+}
+
+// resolveExampleRefs checks the referenced examples of the media exist
+// and builds the schema from the first one when the media has none.
+func (o *OpenAPI) resolveExampleRefs(m *Media) error {
+	var errs error
+	for _, k := range sortedKeys(m.Examples) {
+		ref := m.Examples[k].Ref
+		name, found := strings.CutPrefix(ref, "#/components/examples/")
+		if !found {
+			continue
+		}
+		ex, found := o.Components.Examples[name]
+		if !found {
+			errs = errors.Join(errs, fmt.Errorf("example %v not found", ref))
+			continue
+		}
+		if m.Schema.Title == "" && m.Schema.Ref == "" && m.Schema.Type == "" {
+			v, err := ex.Decode()
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("example %v: %w", ref, err))
+				continue
+			}
+			m.Schema = o.buildSchema(v)
+		}
+	}
+	return errs
+}
+
+// ExampleSanitizer returns the value to publish for a value of an example.
+// path is the path of the route and field is the location of the value in the example,
+// the json keys joined by a dot such as user.email. Array elements use the field of the array
+// and the field of a param example is the name of the param.
+type ExampleSanitizer func(path, field string, v any) any
+
+// SetExampleSanitizer sets the function used to redact the examples of every route
+// when the document is compiled, so emails, tokens and other personal data are not published.
+//
+//	doc.SetExampleSanitizer(func(path, field string, v any) any {
+//		if strings.HasSuffix(field, "email") {
+//			return "user@example.com"
+//		}
+//		return v
+//	})
+func (o *OpenAPI) SetExampleSanitizer(fn ExampleSanitizer) {
+	o.exampleSanitizer = fn
+	o.sanitized = nil
+	o.invalidate()
+}
+
+// sanitizeExamples applies the sanitizer of the document to the examples.
+// The checksum of each sanitized value is kept so a value is only sanitized once
+// when the document is compiled again.
+func (o *OpenAPI) sanitizeExamples(path, field string, examples map[string]Example) {
+	if o.exampleSanitizer == nil {
+		return
+	}
+	for k, ex := range examples {
+		if ex.Ref != "" || o.sanitized[sanitizedKey(path, field, ex.Value)] {
+			continue
+		}
+		v, err := ex.Decode()
+		if err != nil {
+			continue
+		}
+		// the generic copy keeps the values of the caller unchanged
+		if generic, ok := genericJSON(v); ok {
+			v = generic
+		}
+		ex.Value = sanitize(o.exampleSanitizer, path, field, v)
+		examples[k] = ex
+		if o.sanitized == nil {
+			o.sanitized = make(map[uint64]bool)
+		}
+		o.sanitized[sanitizedKey(path, field, ex.Value)] = true
+	}
+}
+
+// sanitizedKey is the checksum of the value of an example at the path and field
+func sanitizedKey(path, field string, v any) uint64 {
+	b, _ := json.Marshal(v)
+	return crcHash(append([]byte(path+" "+field+" "), b...))
+}
+
+// sanitize calls fn with v and every value within it
+func sanitize(fn ExampleSanitizer, path, field string, v any) any {
+	v = fn(path, field, v)
+	switch v.(type) {
+	case []any, map[string]any:
+	default:
+		if generic, ok := genericJSON(v); ok {
+			v = generic
+		}
+	}
+	switch t := v.(type) {
+	case []any:
+		for i, val := range t {
+			t[i] = sanitize(fn, path, field, val)
+		}
+	case map[string]any:
+		for k, val := range t {
+			f := k
+			if field != "" {
+				f = field + "." + k
+			}
+			t[k] = sanitize(fn, path, f, val)
+		}
+	}
+	return v
+}
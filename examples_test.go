@@ -0,0 +1,240 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestLimitExamples(t *testing.T) {
+	type item struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+	type input struct {
+		limits ExampleLimits
+		value  any
+	}
+	fn := func(in input) (map[string]Example, error) {
+		doc := New("", "", "")
+		doc.LimitExamples(in.limits)
+		route := doc.GetRoute("/items", "get").
+			AddResponse(Response{Status: 200}.WithNamedExample("ex", in.value))
+		err := doc.Compile()
+		return route.Responses[200].Content[Json].Examples, err
+	}
+	cases := trial.Cases[input, map[string]Example]{
+		"no_limits": {
+			Input: input{value: item{Name: "apple", Tags: []string{"a", "b"}}},
+			Expected: map[string]Example{
+				"ex": {Value: item{Name: "apple", Tags: []string{"a", "b"}}},
+			},
+		},
+		"struct": {
+			Input: input{
+				limits: ExampleLimits{MaxItems: 1, MaxBytes: 3},
+				value:  item{Name: "apple", Tags: []string{"fruit", "b"}},
+			},
+			Expected: map[string]Example{
+				"ex": {Value: map[string]any{"name": "app", "tags": []any{"fru"}}},
+			},
+		},
+		"utf8": {
+			Input: input{
+				limits: ExampleLimits{MaxBytes: 2},
+				value:  map[string]any{"word": "äb"},
+			},
+			Expected: map[string]Example{
+				"ex": {Value: map[string]any{"word": "ä"}},
+			},
+		},
+		"array": {
+			Input: input{
+				limits: ExampleLimits{MaxItems: 2},
+				value:  []any{1.0, 2.0, 3.0},
+			},
+			Expected: map[string]Example{
+				"ex": {Value: []any{1.0, 2.0}},
+			},
+		},
+		"schema_only": {
+			Input: input{
+				limits: ExampleLimits{SchemaOnly: true},
+				value:  item{Name: "apple"},
+			},
+			Expected: nil,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
+func TestSetExampleSanitizer(t *testing.T) {
+	type user struct {
+		Email string   `json:"email"`
+		Name  string   `json:"name"`
+		Token string   `json:"token"`
+		Tags  []string `json:"tags"`
+	}
+	doc := New("", "", "")
+	fields := make(map[string]bool)
+	doc.SetExampleSanitizer(func(path, field string, v any) any {
+		fields[path+" "+field] = true
+		switch {
+		case strings.HasSuffix(field, "email"):
+			return "user@example.com"
+		case field == "token":
+			return "REDACTED"
+		case field == "session":
+			return nil
+		}
+		return v
+	})
+	original := map[string]any{"users": []any{map[string]any{"email": "bob@corp.com"}}}
+	doc.GetRoute("/users", "post").
+		AddRequest(RequestBody{}.WithExample(user{Email: "bob@corp.com", Name: "bob", Token: "secret", Tags: []string{"a"}})).
+		AddResponse(Response{Status: 200}.WithExample(original)).
+		CookieParam("session", "abc123", "")
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := doc.Paths["/users|post"]
+	eq, diff := trial.Equal(r.Requests.Content[Json].Examples["openapi.user"].Value,
+		map[string]any{"email": "user@example.com", "name": "bob", "token": "REDACTED", "tags": []any{"a"}})
+	if !eq {
+		t.Error(diff)
+	}
+	for _, ex := range r.Responses[200].Content[Json].Examples {
+		eq, diff = trial.Equal(ex.Value, map[string]any{"users": []any{map[string]any{"email": "user@example.com"}}})
+		if !eq {
+			t.Error(diff)
+		}
+	}
+	for _, ex := range r.Params["cookie|session"].Examples {
+		if ex.Value != nil {
+			t.Errorf("expected param example to be removed got %v", ex.Value)
+		}
+	}
+	if original["users"].([]any)[0].(map[string]any)["email"] != "bob@corp.com" {
+		t.Error("expected the original example to be unchanged")
+	}
+	for _, f := range []string{"/users ", "/users email", "/users users", "/users users.email", "/users tags", "/users session"} {
+		if !fields[f] {
+			t.Errorf("expected sanitizer to be called with %q", f)
+		}
+	}
+}
+
+func TestSanitizeOnce(t *testing.T) {
+	doc := New("", "", "")
+	doc.SetExampleSanitizer(func(path, field string, v any) any {
+		if s, ok := v.(string); ok {
+			return s + "*"
+		}
+		return v
+	})
+	doc.RegisterExample("name", "bob")
+	r := doc.GetRoute("/users", "get").AddResponse(Response{Status: 200}.WithExample(map[string]string{"name": "bob"}))
+	for i := 0; i < 2; i++ {
+		// a setting of the document compiles every route again
+		doc.SetNamingStrategy(nil)
+		if err := doc.Compile(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, ex := range r.Responses[200].Content[Json].Examples {
+		if eq, diff := trial.Equal(ex.Value, map[string]any{"name": "bob*"}); !eq {
+			t.Error(diff)
+		}
+	}
+	if v := doc.Components.Examples["name"].Value; v != "bob*" {
+		t.Errorf("expected the component example to be sanitized once got %v", v)
+	}
+}
+
+func TestWithExampleRef(t *testing.T) {
+	type apiError struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	doc := New("", "", "")
+	doc.RegisterExample("StandardError", apiError{Code: 500, Message: "internal error"})
+	doc.GetRoute("/users", "get").AddResponse(Response{Status: 500, Desc: "error"}.WithExampleRef("StandardError"))
+	doc.GetRoute("/orders", "get").AddResponse(Response{Status: 500, Desc: "error"}.WithExampleRef("StandardError"))
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(doc.Paths["/users|get"].Responses[500].Content[Json])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(string(b), `{"schema":{"$ref":"#/components/schemas/openapi.apiError"},"examples":{"StandardError":{"$ref":"#/components/examples/StandardError"}}}`); !eq {
+		t.Error(diff)
+	}
+	if _, found := doc.Components.Schemas["openapi.apiError"]; !found {
+		t.Error("schema of the example should be added to the components")
+	}
+
+	// round trip keeps the reference
+	loaded, err := NewFromJson(doc.JSON())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(loaded.Paths["/orders|get"].Responses[500].Content[Json].Examples["StandardError"].Ref, "#/components/examples/StandardError"); !eq {
+		t.Error(diff)
+	}
+
+	doc.GetRoute("/missing", "get").AddResponse(Response{Status: 500}.WithExampleRef("Missing"))
+	if err := doc.Compile(); err == nil || !strings.Contains(err.Error(), "example #/components/examples/Missing not found") {
+		t.Errorf("expected missing example error, got %v", err)
+	}
+}
+
+func TestShareExamples(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	doc := New("", "", "")
+	doc.GetRoute("/users", "post").
+		AddRequest(RequestBody{}.WithNamedExample("bob", user{Name: "bob"})).
+		AddResponse(Response{Status: 201}.WithNamedExample("bob", user{Name: "bob"}))
+	doc.GetRoute("/users/{id}", "get").
+		AddResponse(Response{Status: 200}.WithNamedExample("bob", user{Name: "bob"})).
+		AddResponse(Response{Status: 404}.WithNamedExample("missing", map[string]string{"error": "not found"}))
+	doc.GetRoute("/admins", "get").
+		AddResponse(Response{Status: 200}.WithNamedExample("bob", user{Name: "bob the admin"}))
+	if err := doc.Compile(ShareExamples); err != nil {
+		t.Fatal(err)
+	}
+
+	fn := func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+	cases := trial.Cases[any, string]{
+		"components": {
+			Input:    doc.Components.Examples,
+			Expected: `{"bob":{"value":{"name":"bob"}}}`,
+		},
+		"request": {
+			Input:    doc.GetRoute("/users", "post").Requests.Content[Json].Examples,
+			Expected: `{"bob":{"$ref":"#/components/examples/bob"}}`,
+		},
+		"response": {
+			Input:    doc.GetRoute("/users/{id}", "get").Responses[200].Content[Json].Examples,
+			Expected: `{"bob":{"$ref":"#/components/examples/bob"}}`,
+		},
+		"unique": {
+			Input:    doc.GetRoute("/users/{id}", "get").Responses[404].Content[Json].Examples,
+			Expected: `{"missing":{"value":{"error":"not found"}}}`,
+		},
+		"different value": {
+			Input:    doc.GetRoute("/admins", "get").Responses[200].Content[Json].Examples,
+			Expected: `{"bob":{"value":{"name":"bob the admin"}}}`,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
@@ -0,0 +1,281 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Extensions are Specification Extensions of an object, the keys start with "x-".
+// They are written as fields of the object, for example x-internal or x-owner-team.
+type Extensions map[string]any
+
+// with returns a copy of the extensions with the key set to v,
+// the "x-" prefix is added to the key if missing.
+func (e Extensions) with(key string, v any) Extensions {
+	if !strings.HasPrefix(key, "x-") {
+		key = "x-" + key
+	}
+	ext := make(Extensions, len(e)+1)
+	for k, val := range e {
+		ext[k] = val
+	}
+	ext[key] = v
+	return ext
+}
+
+// SetExtension sets a Specification Extension on the document
+func (o *OpenAPI) SetExtension(key string, v any) {
+	o.Extensions = o.Extensions.with(key, v)
+}
+
+// SetExtension sets a Specification Extension on the info object
+func (i *Info) SetExtension(key string, v any) {
+	i.Extensions = i.Extensions.with(key, v)
+}
+
+// SetExtension sets a Specification Extension on the operation
+func (r *Route) SetExtension(key string, v any) *Route {
+	r.Extensions = r.Extensions.with(key, v)
+	r.compiled = false
+	return r
+}
+
+// SetExtension returns the Response with the Specification Extension set
+func (r Response) SetExtension(key string, v any) Response {
+	r.Extensions = r.Extensions.with(key, v)
+	return r
+}
+
+// SetExtension returns the Param with the Specification Extension set
+func (p Param) SetExtension(key string, v any) Param {
+	p.Extensions = p.Extensions.with(key, v)
+	return p
+}
+
+// SetExtension returns the Schema with the Specification Extension set
+func (s Schema) SetExtension(key string, v any) Schema {
+	s.Extensions = s.Extensions.with(key, v)
+	return s
+}
+
+// SetExtension returns the SecurityScheme with the Specification Extension set
+func (s SecurityScheme) SetExtension(key string, v any) SecurityScheme {
+	s.Extensions = s.Extensions.with(key, v)
+	return s
+}
+
+// marshalExtensions adds the extensions as fields to the json object b
+func marshalExtensions(b []byte, ext Extensions) ([]byte, error) {
+	if len(ext) == 0 {
+		return b, nil
+	}
+	var buf bytes.Buffer
+	buf.Write(bytes.TrimSuffix(bytes.TrimSpace(b), []byte("}")))
+	for _, k := range sortedKeys(ext) {
+		v, err := json.Marshal(ext[k])
+		if err != nil {
+			return nil, err
+		}
+		key, _ := json.Marshal(k)
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// unmarshalExtensions returns the fields of the json object b that start with "x-",
+// the values are kept as json.RawMessage.
+func unmarshalExtensions(b []byte) (Extensions, error) {
+	if !bytes.Contains(b, []byte(`"x-`)) {
+		return nil, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	var ext Extensions
+	for k, v := range fields {
+		if !strings.HasPrefix(k, "x-") {
+			continue
+		}
+		if ext == nil {
+			ext = make(Extensions)
+		}
+		ext[k] = v
+	}
+	return ext, nil
+}
+
+func (o OpenAPI) MarshalJSON() ([]byte, error) {
+	type openAPI OpenAPI
+	b, err := json.Marshal(openAPI(o))
+	if err != nil {
+		return nil, err
+	}
+	if o.is31() {
+		if b, err = to31(b, false); err != nil {
+			return nil, err
+		}
+	}
+	return marshalExtensions(b, o.Extensions)
+}
+
+func (o *OpenAPI) UnmarshalJSON(b []byte) (err error) {
+	type openAPI OpenAPI
+	if err := json.Unmarshal(b, (*openAPI)(o)); err != nil {
+		return err
+	}
+	if o.routeOrder, err = pathKeys(b); err != nil {
+		return err
+	}
+	o.setDoc(o.Paths)
+	o.setDoc(o.Webhooks)
+	o.Extensions, err = unmarshalExtensions(b)
+	return err
+}
+
+func (i Info) MarshalJSON() ([]byte, error) {
+	type info Info
+	b, err := json.Marshal(info(i))
+	if err != nil {
+		return nil, err
+	}
+	return marshalExtensions(b, i.Extensions)
+}
+
+func (i *Info) UnmarshalJSON(b []byte) (err error) {
+	type info Info
+	if err := json.Unmarshal(b, (*info)(i)); err != nil {
+		return err
+	}
+	i.Extensions, err = unmarshalExtensions(b)
+	return err
+}
+
+func (r Route) MarshalJSON() ([]byte, error) {
+	type route Route
+	b, err := json.Marshal(route(r))
+	if err != nil {
+		return nil, err
+	}
+	return marshalExtensions(b, r.Extensions)
+}
+
+func (r *Route) UnmarshalJSON(b []byte) (err error) {
+	type route Route
+	if err := json.Unmarshal(b, (*route)(r)); err != nil {
+		return err
+	}
+	// the status of a response is its key
+	for code, resp := range r.Responses {
+		resp.Status = code
+		r.Responses[code] = resp
+	}
+	r.Extensions, err = unmarshalExtensions(b)
+	return err
+}
+
+func (r *Response) UnmarshalJSON(b []byte) (err error) {
+	type response Response
+	if err := json.Unmarshal(b, (*response)(r)); err != nil {
+		return err
+	}
+	r.Extensions, err = unmarshalExtensions(b)
+	return err
+}
+
+func (p *Param) UnmarshalJSON(b []byte) (err error) {
+	type param Param
+	if err := json.Unmarshal(b, (*param)(p)); err != nil {
+		return err
+	}
+	p.Extensions, err = unmarshalExtensions(b)
+	return err
+}
+
+func (s Schema) MarshalJSON() ([]byte, error) {
+	type schema Schema
+	b, err := json.Marshal(schema(s))
+	if err != nil {
+		return nil, err
+	}
+	// the properties of a struct are written in the order of its fields
+	if keys := propertyOrder(s); keys != nil {
+		if b, err = orderProperties(b, keys); err != nil {
+			return nil, err
+		}
+	}
+	return marshalExtensions(b, s.Extensions)
+}
+
+func (s *Schema) UnmarshalJSON(b []byte) (err error) {
+	type schema Schema
+	// the type array and examples of 3.1 schemas
+	v := struct {
+		*schema
+		Type     any   `json:"type"`
+		Examples []any `json:"examples"`
+	}{schema: (*schema)(s)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	switch t := v.Type.(type) {
+	case string:
+		s.Type = Type(t)
+	case []any:
+		for _, t := range t {
+			if t == "null" {
+				s.Nullable = true
+			} else if str, ok := t.(string); ok && s.Type == "" {
+				s.Type = Type(str)
+			}
+		}
+	}
+	if s.Example == nil && len(v.Examples) > 0 {
+		s.Example = v.Examples[0]
+	}
+	if len(s.Properties) > 1 {
+		// the order of the properties of the document
+		var props struct {
+			Properties json.RawMessage `json:"properties"`
+		}
+		if json.Unmarshal(b, &props) == nil {
+			if fields, err := objectFields(props.Properties); err == nil {
+				order := make([]string, len(fields))
+				for i, f := range fields {
+					order[i] = f.key
+				}
+				if !sort.StringsAreSorted(order) {
+					s.Order = order
+				}
+			}
+		}
+	}
+	s.Extensions, err = unmarshalExtensions(b)
+	return err
+}
+
+func (s SecurityScheme) MarshalJSON() ([]byte, error) {
+	type securityScheme SecurityScheme
+	b, err := json.Marshal(securityScheme(s))
+	if err != nil {
+		return nil, err
+	}
+	return marshalExtensions(b, s.Extensions)
+}
+
+func (s *SecurityScheme) UnmarshalJSON(b []byte) (err error) {
+	type securityScheme SecurityScheme
+	if err := json.Unmarshal(b, (*securityScheme)(s)); err != nil {
+		return err
+	}
+	s.Extensions, err = unmarshalExtensions(b)
+	return err
+}
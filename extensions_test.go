@@ -0,0 +1,85 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestSetExtension(t *testing.T) {
+	fn := func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+	cases := trial.Cases[any, string]{
+		"schema": {
+			Input:    Schema{Type: String}.SetExtension("x-internal", true),
+			Expected: `{"type":"string","x-internal":true}`,
+		},
+		"prefix": {
+			Input:    Schema{}.SetExtension("audience", "partner"),
+			Expected: `{"x-audience":"partner"}`,
+		},
+		"response": {
+			Input:    Response{Desc: "ok"}.SetExtension("x-owner-team", "users"),
+			Expected: `{"description":"ok","x-owner-team":"users"}`,
+		},
+		"response ref": {
+			Input:    Response{Ref: "#/components/responses/OK"}.SetExtension("x-owner-team", "users"),
+			Expected: `{"$ref":"#/components/responses/OK"}`,
+		},
+		"param": {
+			Input:    Param{Name: "id", In: "path"}.SetExtension("x-internal", true),
+			Expected: `{"name":"id","in":"path","examples":null,"x-internal":true}`,
+		},
+		"security scheme": {
+			Input:    SecurityScheme{Type: "http", Scheme: "bearer"}.SetExtension("x-token-url", "https://auth"),
+			Expected: `{"type":"http","scheme":"bearer","x-token-url":"https://auth"}`,
+		},
+		"sorted": {
+			Input:    Schema{}.SetExtension("x-b", 2).SetExtension("x-a", 1),
+			Expected: `{"x-a":1,"x-b":2}`,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
+func TestExtensionsRoundTrip(t *testing.T) {
+	doc := New("ext", "1.0.0", "")
+	doc.SetExtension("x-logo", map[string]string{"url": "logo.png"})
+	doc.Info.SetExtension("x-audience", "public")
+	doc.GetRoute("/users", "get").
+		SetExtension("x-internal", false).
+		AddResponse(Response{Status: 200, Desc: "ok"}.SetExtension("x-cache", 60))
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	b := doc.JSONBytes()
+	for _, s := range []string{`"x-logo"`, `"x-audience": "public"`, `"x-internal": false`, `"x-cache": 60`} {
+		if !bytes.Contains(b, []byte(s)) {
+			t.Errorf("missing %s in %s", s, b)
+		}
+	}
+	// json.Marshal and WriteJSON produce the same document
+	m, err := json.MarshalIndent(doc, "", "    ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(string(b), string(m)); !eq {
+		t.Error(diff)
+	}
+
+	loaded, err := NewFromJson(string(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(loaded.JSON(), string(b)); !eq {
+		t.Error(diff)
+	}
+	if v := string(loaded.GetRoute("/users", "get").Responses[200].Extensions["x-cache"].(json.RawMessage)); v != "60" {
+		t.Errorf("expected x-cache 60 got %v", v)
+	}
+}
@@ -0,0 +1,96 @@
+package openapi
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/token"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// fieldDocs are the doc comments of struct types loaded with LoadFieldDocs
+var fieldDocs = struct {
+	sync.RWMutex
+	types  map[string]string            // [pkg.Type]doc
+	fields map[string]map[string]string // [pkg.Type][Field]doc
+}{types: make(map[string]string), fields: make(map[string]map[string]string)}
+
+// fieldDocsVersion changes each time field docs are loaded so the cached schemas are built again
+var fieldDocsVersion atomic.Uint64
+
+// LoadFieldDocs reads the doc comments of the struct types in the Go package in dir
+// and uses them as the descriptions of the schemas built from those types.
+// The comment of a field describes its property unless the field has a desc tag,
+// the comment of the type describes the object.
+//
+//	// User of the API
+//	type User struct {
+//		// Name is the display name
+//		Name string `json:"name"`
+//		Age  int    `json:"age"` // age in years
+//	}
+//
+//	err := openapi.LoadFieldDocs("./models")
+func LoadFieldDocs(dir string) error {
+	fset := token.NewFileSet()
+	pkgs, err := parsePackages(fset, dir)
+	if err != nil {
+		return err
+	}
+	fieldDocs.Lock()
+	defer fieldDocs.Unlock()
+	for _, name := range sortedKeys(pkgs) {
+		p, err := doc.NewFromFiles(fset, pkgs[name], dir, doc.AllDecls|doc.PreserveAST)
+		if err != nil {
+			return err
+		}
+		for _, t := range p.Types {
+			key := p.Name + "." + t.Name
+			for _, spec := range t.Decl.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok || ts.Name.Name != t.Name {
+					continue
+				}
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				if d := commentText(ts.Doc); d != "" {
+					fieldDocs.types[key] = d
+				} else if d := commentText(t.Decl.Doc); d != "" && len(t.Decl.Specs) == 1 {
+					fieldDocs.types[key] = d
+				}
+				fields := make(map[string]string)
+				for _, f := range st.Fields.List {
+					d := commentText(f.Doc)
+					if d == "" {
+						d = commentText(f.Comment)
+					}
+					for _, n := range f.Names {
+						if d != "" {
+							fields[n.Name] = d
+						}
+					}
+				}
+				fieldDocs.fields[key] = fields
+			}
+		}
+	}
+	fieldDocsVersion.Add(1)
+	return nil
+}
+
+// typeDoc returns the doc comment of the struct type loaded with LoadFieldDocs
+func typeDoc(t reflect.Type) string {
+	fieldDocs.RLock()
+	defer fieldDocs.RUnlock()
+	return fieldDocs.types[t.String()]
+}
+
+// fieldDoc returns the doc comment of the field of the struct type loaded with LoadFieldDocs
+func fieldDoc(t reflect.Type, field string) string {
+	fieldDocs.RLock()
+	defer fieldDocs.RUnlock()
+	return fieldDocs.fields[t.String()][field]
+}
@@ -0,0 +1,76 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+type docUser struct {
+	Name  string `json:"name"`
+	Age   int    `json:"age"`
+	Email string `json:"email" desc:"from the tag"`
+	Admin bool   `json:"admin"`
+}
+
+func TestLoadFieldDocs(t *testing.T) {
+	// the source of docUser with its comments, as LoadFieldDocs reads the package files
+	dir := t.TempDir()
+	src := `package openapi
+
+// docUser is a user of the API
+type docUser struct {
+	// Name is the display name
+	Name  string ` + "`json:\"name\"`" + `
+	Age   int    ` + "`json:\"age\"`" + ` // age in years
+	// ignored as the field has a desc tag
+	Email string ` + "`json:\"email\" desc:\"from the tag\"`" + `
+	Admin bool   ` + "`json:\"admin\"`" + `
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "user.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// cached before the docs are loaded
+	buildSchema(docUser{})
+	if err := LoadFieldDocs(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		fieldDocs.Lock()
+		delete(fieldDocs.types, "openapi.docUser")
+		delete(fieldDocs.fields, "openapi.docUser")
+		fieldDocs.Unlock()
+		fieldDocsVersion.Add(1)
+	})
+
+	eq, diff := trial.Equal(buildSchema(docUser{}), Schema{
+		Title: "openapi.docUser",
+		Type:  Object,
+		Desc:  "docUser is a user of the API",
+		Properties: map[string]Schema{
+			"name":  {Type: String, Desc: "Name is the display name"},
+			"age":   {Type: Integer, Desc: "age in years"},
+			"email": {Type: String, Desc: "from the tag"},
+			"admin": {Type: Boolean},
+		},
+		Order: []string{"name", "age", "email", "admin"},
+	})
+	if !eq {
+		t.Error(diff)
+	}
+
+	// the examples added to a Media that has a schema have the description of their type
+	m := Response{Status: 200}.WithExample(docUser{}).WithNamedExample("admin", &docUser{Admin: true}).Content[Json]
+	for name, ex := range m.Examples {
+		if ex.Desc != "docUser is a user of the API" {
+			t.Errorf("unexpected description of example %v: %q", name, ex.Desc)
+		}
+	}
+
+	if err := LoadFieldDocs(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected error for a missing directory")
+	}
+}
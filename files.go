@@ -0,0 +1,197 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// readFile reads the file from fsys, or from the OS filesystem when fsys is nil.
+func readFile(fsys fs.FS, path string) ([]byte, error) {
+	if fsys == nil {
+		return os.ReadFile(path)
+	}
+	return fs.ReadFile(fsys, path)
+}
+
+// DescFromFile sets the description of the operation to the content of a CommonMark file,
+// the file is read from fsys such as an embed.FS or from disk when fsys is nil.
+func (r *Route) DescFromFile(fsys fs.FS, path string) error {
+	b, err := readFile(fsys, path)
+	if err != nil {
+		return err
+	}
+	r.Desc = strings.TrimSpace(string(b))
+	return nil
+}
+
+// InfoDescFromFile sets the description of the API to the content of a CommonMark file,
+// the file is read from fsys such as an embed.FS or from disk when fsys is nil.
+func (o *OpenAPI) InfoDescFromFile(fsys fs.FS, path string) error {
+	b, err := readFile(fsys, path)
+	if err != nil {
+		return err
+	}
+	o.Info.Desc = strings.TrimSpace(string(b))
+	return nil
+}
+
+// WithExampleFile adds the content of a json or yaml file as a named example to the json Content
+// of the Response. The name of the example is the file name without its extension.
+// The file is read from fsys or from disk when fsys is nil, so the golden files of tests can be used.
+// A file that can not be read is reported by Compile.
+func (r Response) WithExampleFile(fsys fs.FS, path string) Response {
+	r.Content = r.Content.addExampleFile(fsys, path, exampleName(path))
+	return r
+}
+
+// WithExampleFile adds the content of a json or yaml file as a named example to the json Content
+// of the RequestBody, see Response.WithExampleFile.
+func (r RequestBody) WithExampleFile(fsys fs.FS, path string) RequestBody {
+	r.Content = r.Content.addExampleFile(fsys, path, exampleName(path))
+	return r
+}
+
+// exampleName is the file name without its extension
+func exampleName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+func (c Content) addExampleFile(fsys fs.FS, path, name string) Content {
+	v, err := readExample(fsys, path)
+	if err != nil {
+		if c == nil {
+			c = make(Content)
+		}
+		c["invalid/json"] = Media{Examples: map[string]Example{"invalid": {Value: err.Error()}}}
+		return c
+	}
+	return c.addExample(Json, name, v)
+}
+
+// readExample decodes a json or yaml file into a generic value
+func readExample(fsys fs.FS, path string) (any, error) {
+	b, err := readFile(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &v)
+		v = stringKeys(v)
+	default:
+		err = json.Unmarshal(b, &v)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", path, err)
+	}
+	return v, nil
+}
+
+// stringKeys converts yaml maps with keys that are not strings into maps
+// with string keys so the value can be encoded as json.
+func stringKeys(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			t[k] = stringKeys(val)
+		}
+	case map[any]any:
+		m := make(map[string]any, len(t))
+		for k, val := range t {
+			m[fmt.Sprint(k)] = stringKeys(val)
+		}
+		return m
+	case []any:
+		for i, val := range t {
+			t[i] = stringKeys(val)
+		}
+	}
+	return v
+}
+
+// LoadExamplesDir adds all json and yaml examples found in root to the routes of the document.
+// The files are named by the route and status of the example:
+//
+//	{root}/{path}/{method}/{status}-{name}.json  a response example, status is a code or default
+//	{root}/{path}/{method}/request-{name}.json   a request example
+//
+// for example examples/users/{id}/get/200-found.json. Routes that do not exist are created.
+// The files are read from fsys or from disk when fsys is nil.
+func (o *OpenAPI) LoadExamplesDir(fsys fs.FS, root string) error {
+	if fsys == nil {
+		fsys, root = os.DirFS(root), "."
+	}
+	var errs []error
+	err := fs.WalkDir(fsys, root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := strings.ToLower(path.Ext(file))
+		if d.IsDir() || (ext != ".json" && ext != ".yaml" && ext != ".yml") {
+			return nil
+		}
+		if err := o.loadExample(fsys, root, file); err != nil {
+			errs = append(errs, err)
+		}
+		return nil
+	})
+	return errors.Join(append(errs, err)...)
+}
+
+// loadExample adds the example file to the route described by its path relative to root
+func (o *OpenAPI) loadExample(fsys fs.FS, root, file string) error {
+	rel := file
+	if root = path.Clean(root); root != "." {
+		rel = strings.TrimPrefix(file, root+"/")
+	}
+	parts := strings.Split(rel, "/")
+	if len(parts) < 2 {
+		return fmt.Errorf("%v: expected {path}/{method}/{status}-{name}", file)
+	}
+	method := strings.ToLower(parts[len(parts)-2])
+	switch Method(method) {
+	case GET, PUT, POST, DELETE, OPTIONS, HEAD, PATCH, TRACE:
+	default:
+		return fmt.Errorf("%v: unknown method %q", file, method)
+	}
+	status, name, _ := strings.Cut(exampleName(file), "-")
+	if name == "" {
+		name = status
+	}
+
+	v, err := readExample(fsys, file)
+	if err != nil {
+		return err
+	}
+	r := o.GetRoute("/"+strings.Join(parts[:len(parts)-2], "/"), method)
+	if status == "request" {
+		r.AddRequest(RequestBody{}.WithNamedExample(name, v))
+		return nil
+	}
+
+	var code Code
+	if err := code.UnmarshalText([]byte(status)); err != nil {
+		return fmt.Errorf("%v: invalid status %q", file, status)
+	}
+	resp := r.Responses[code]
+	resp.Status = code
+	if resp.Desc == "" {
+		resp.Desc = http.StatusText(int(code))
+	}
+	if resp.Desc == "" {
+		resp.Desc = "default response"
+	}
+	resp.Content = resp.Content.addExample(Json, name, v)
+	r.AddResponse(resp)
+	return nil
+}
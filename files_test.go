@@ -0,0 +1,156 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hydronica/trial"
+)
+
+func TestDescFromFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"docs/users.md": {Data: []byte("# Users\n\nList all **users**.\n")},
+		"docs/api.md":   {Data: []byte("The users API\n")},
+	}
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "orders.md"), []byte("List orders"), 0o644)
+
+	doc := New("", "", "")
+	if err := doc.InfoDescFromFile(fsys, "docs/api.md"); err != nil {
+		t.Fatal(err)
+	}
+	users := doc.GetRoute("/users", "get")
+	if err := users.DescFromFile(fsys, "docs/users.md"); err != nil {
+		t.Fatal(err)
+	}
+	orders := doc.GetRoute("/orders", "get")
+	if err := orders.DescFromFile(nil, filepath.Join(dir, "orders.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := orders.DescFromFile(fsys, "docs/missing.md"); err == nil {
+		t.Error("expected error for missing file")
+	}
+
+	eq, diff := trial.Equal([]string{doc.Info.Desc, users.Desc, orders.Desc},
+		[]string{"The users API", "# Users\n\nList all **users**.", "List orders"})
+	if !eq {
+		t.Error(diff)
+	}
+}
+
+func TestWithExampleFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"testdata/user.json":   {Data: []byte(`{"name": "bob", "age": 42}`)},
+		"testdata/admin.yaml":  {Data: []byte("name: alice\nage: 30\nroles:\n  - admin\n")},
+		"testdata/codes.yml":   {Data: []byte("200: ok\n404: not found\n")},
+		"testdata/broken.json": {Data: []byte(`{"name": `)},
+	}
+	fn := func(path string) (Content, error) {
+		return Response{Status: 200}.WithExampleFile(fsys, path).Content, nil
+	}
+	cases := trial.Cases[string, Content]{
+		"json": {
+			Input: "testdata/user.json",
+			Expected: Content{Json: {
+				Schema:   buildSchema(map[string]any{"name": "bob", "age": 42.0}),
+				Examples: map[string]Example{"user": {Value: map[string]any{"name": "bob", "age": 42.0}}},
+				source:   map[string]any{"name": "bob", "age": 42.0},
+			}},
+		},
+		"yaml": {
+			Input: "testdata/admin.yaml",
+			Expected: Content{Json: {
+				Schema:   buildSchema(map[string]any{"name": "alice", "age": 30, "roles": []any{"admin"}}),
+				Examples: map[string]Example{"admin": {Value: map[string]any{"name": "alice", "age": 30, "roles": []any{"admin"}}}},
+				source:   map[string]any{"name": "alice", "age": 30, "roles": []any{"admin"}},
+			}},
+		},
+		"yaml int keys": {
+			Input: "testdata/codes.yml",
+			Expected: Content{Json: {
+				Schema:   buildSchema(map[string]any{"200": "ok", "404": "not found"}),
+				Examples: map[string]Example{"codes": {Value: map[string]any{"200": "ok", "404": "not found"}}},
+				source:   map[string]any{"200": "ok", "404": "not found"},
+			}},
+		},
+		"invalid": {
+			Input: "testdata/broken.json",
+			Expected: Content{"invalid/json": {
+				Examples: map[string]Example{"invalid": {Value: "testdata/broken.json: unexpected end of JSON input"}},
+			}},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	// the request body uses the same examples and errors are reported by Compile
+	doc := New("", "", "")
+	doc.GetRoute("/users", "post").
+		AddRequest(RequestBody{}.WithExampleFile(fsys, "testdata/user.json").WithExampleFile(fsys, "testdata/admin.yaml")).
+		AddResponse(Response{Status: 200}.WithExampleFile(fsys, "testdata/missing.json"))
+	if len(doc.Paths["/users|post"].Requests.Content[Json].Examples) != 2 {
+		t.Error("expected 2 request examples")
+	}
+	if err := doc.Compile(); err == nil {
+		t.Error("expected compile error for missing example file")
+	}
+}
+
+func TestLoadExamplesDir(t *testing.T) {
+	fsys := fstest.MapFS{
+		"examples/users/get/200-list.json":          {Data: []byte(`[{"name": "bob"}]`)},
+		"examples/users/post/request-new.yaml":      {Data: []byte("name: alice\n")},
+		"examples/users/post/201-created.json":      {Data: []byte(`{"id": 1}`)},
+		"examples/users/{id}/get/200-found.json":    {Data: []byte(`{"name": "bob"}`)},
+		"examples/users/{id}/get/404-missing.json":  {Data: []byte(`{"error": "not found"}`)},
+		"examples/users/{id}/get/default-error.yml": {Data: []byte("error: failed\n")},
+		"examples/users/{id}/get/README.md":         {Data: []byte("ignored")},
+	}
+	doc := New("", "", "")
+	doc.GetRoute("/users/{id}", "get").AddResponse(Response{Status: 200, Desc: "the user"})
+	if err := doc.LoadExamplesDir(fsys, "examples"); err != nil {
+		t.Fatal(err)
+	}
+
+	type example struct {
+		Route  string
+		Status Code
+		Desc   string
+		Names  []string
+	}
+	var got []example
+	for _, k := range sortedKeys(doc.Paths) {
+		r := doc.Paths[k]
+		if r.Requests != nil {
+			got = append(got, example{Route: k, Desc: "request", Names: sortedKeys(r.Requests.Content[Json].Examples)})
+		}
+		for _, code := range sortedCodes(r.Responses) {
+			resp := r.Responses[code]
+			got = append(got, example{Route: k, Status: code, Desc: resp.Desc, Names: sortedKeys(resp.Content[Json].Examples)})
+		}
+	}
+	eq, diff := trial.Equal(got, []example{
+		{Route: "/users/{id}|get", Status: DefaultStatus, Desc: "default response", Names: []string{"error"}},
+		{Route: "/users/{id}|get", Status: 200, Desc: "the user", Names: []string{"found"}},
+		{Route: "/users/{id}|get", Status: 404, Desc: "Not Found", Names: []string{"missing"}},
+		{Route: "/users|get", Status: 200, Desc: "OK", Names: []string{"list"}},
+		{Route: "/users|post", Desc: "request", Names: []string{"new"}},
+		{Route: "/users|post", Status: 201, Desc: "Created", Names: []string{"created"}},
+	})
+	if !eq {
+		t.Error(diff)
+	}
+	if _, found := doc.Paths["/users/{id}|get"].Params["path|id"]; !found {
+		t.Error("expected path param id")
+	}
+
+	err := doc.LoadExamplesDir(fstest.MapFS{
+		"examples/users/fetch/200-x.json": {Data: []byte(`{}`)},
+		"examples/users/get/ok-x.json":    {Data: []byte(`{}`)},
+		"examples/users/get/200-y.json":   {Data: []byte(`{`)},
+	}, "examples")
+	if err == nil {
+		t.Error("expected errors for unknown method, invalid status and invalid json")
+	}
+}
@@ -0,0 +1,38 @@
+package openapi
+
+import "fmt"
+
+// WithFormExample adds the example to the RequestBody as application/x-www-form-urlencoded content,
+// such as the body of a login or token request. The schema is the object of the example and
+// the encoding serializes the nested objects with the deepObject style, user[name]=bob.
+//
+//	RequestBody{}.WithFormExample(TokenRequest{GrantType: "client_credentials", Scope: "read"})
+func (r RequestBody) WithFormExample(i any) RequestBody {
+	r.Content = r.Content.addExample(XForm, "", i)
+	m := r.Content[XForm]
+	if m.Schema.Type != Object && m.Schema.Ref == "" {
+		delete(r.Content, XForm)
+		r.Content["invalid/json"] = Media{Examples: map[string]Example{"invalid": {Value: fmt.Sprintf("form example must be an object not %T", i)}}}
+		return r
+	}
+	m.Encoding = formEncoding(m.Schema, m.Encoding)
+	r.Content[XForm] = m
+	return r
+}
+
+// formEncoding adds the encoding of the object properties of the schema to enc
+func formEncoding(s Schema, enc map[string]Encoding) map[string]Encoding {
+	for k, p := range s.Properties {
+		if p.Type != Object && p.Ref == "" {
+			continue
+		}
+		if enc == nil {
+			enc = make(map[string]Encoding)
+		}
+		if _, found := enc[k]; !found {
+			explode := true
+			enc[k] = Encoding{Style: StyleDeepObject, Explode: &explode}
+		}
+	}
+	return enc
+}
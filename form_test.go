@@ -0,0 +1,49 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestWithFormExample(t *testing.T) {
+	type client struct {
+		ID     string `json:"id"`
+		Secret string `json:"secret"`
+	}
+	type token struct {
+		GrantType string   `json:"grant_type"`
+		Scope     []string `json:"scope"`
+		Client    client   `json:"client"`
+	}
+	fn := func(example any) (string, error) {
+		c := RequestBody{}.WithFormExample(example).Content
+		if m, found := c["invalid/json"]; found {
+			return "", errors.New(m.Examples["invalid"].Value.(string))
+		}
+		b, err := json.Marshal(c)
+		return string(b), err
+	}
+	cases := trial.Cases[any, string]{
+		"struct": {
+			Input: token{GrantType: "client_credentials", Scope: []string{"read"}, Client: client{ID: "abc", Secret: "s"}},
+			Expected: `{"application/x-www-form-urlencoded":{"schema":{"title":"openapi.token","type":"object","properties":{` +
+				`"grant_type":{"type":"string"},"scope":{"type":"array","items":{"type":"string"}},` +
+				`"client":{"title":"openapi.client","type":"object","properties":{"id":{"type":"string"},"secret":{"type":"string"}}}}},` +
+				`"examples":{"openapi.token":{"value":{"grant_type":"client_credentials","scope":["read"],"client":{"id":"abc","secret":"s"}}}},` +
+				`"encoding":{"client":{"style":"deepObject","explode":true}}}}`,
+		},
+		"flat": {
+			Input: client{ID: "abc"},
+			Expected: `{"application/x-www-form-urlencoded":{"schema":{"title":"openapi.client","type":"object","properties":{"id":{"type":"string"},"secret":{"type":"string"}}},` +
+				`"examples":{"openapi.client":{"value":{"id":"abc","secret":""}}}}}`,
+		},
+		"not an object": {
+			Input:       "grant_type=password",
+			ExpectedErr: errors.New("form example must be an object not string"),
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
@@ -0,0 +1,122 @@
+package openapi
+
+import "strings"
+
+// GlobalHeaderParam adds a header param to every operation of the document during Compile.
+// A param of the operation with the same name is kept, see Route.NoGlobalParams to opt out.
+//
+//	doc.GlobalHeaderParam("X-Request-ID", "3f2a9c", "id to trace the request")
+func (o *OpenAPI) GlobalHeaderParam(name string, value any, desc string) {
+	o.globalParam("header", name, value, desc)
+}
+
+// GlobalQueryParam adds a query param to every operation of the document during Compile.
+func (o *OpenAPI) GlobalQueryParam(name string, value any, desc string) {
+	o.globalParam("query", name, value, desc)
+}
+
+// GlobalCookieParam adds a cookie param to every operation of the document during Compile.
+func (o *OpenAPI) GlobalCookieParam(name string, value any, desc string) {
+	o.globalParam("cookie", name, value, desc)
+}
+
+func (o *OpenAPI) globalParam(pType, name string, value any, desc string) {
+	r := &Route{Params: make(Params), doc: o}
+	r.AddParam(pType, name, value, desc)
+	params := make(Params, len(o.globalParams)+1)
+	for k, p := range o.globalParams {
+		params[k] = p
+	}
+	params[pType+"|"+name] = r.Params[pType+"|"+name]
+	o.globalParams = params
+	o.invalidate()
+}
+
+// NoGlobalParams skips the global params with the names on the route, all global params without a name.
+func (r *Route) NoGlobalParams(names ...string) *Route {
+	if r.noGlobals == nil {
+		r.noGlobals = make(map[string]bool)
+	}
+	if len(names) == 0 {
+		names = []string{""}
+	}
+	for _, n := range names {
+		r.noGlobals[n] = true
+	}
+	r.compiled = false
+	return r
+}
+
+// applyGlobalParams adds the global params to the routes that changed since the last Compile,
+// global params a route opted out of are removed.
+func (o *OpenAPI) applyGlobalParams() {
+	for _, r := range o.Paths {
+		if !r.pending() {
+			continue
+		}
+		if r.Params == nil {
+			r.Params = make(Params)
+		}
+		for k, p := range o.globalParams {
+			existing, found := r.Params[k]
+			if r.noGlobals[""] || r.noGlobals[p.Name] {
+				if found && sameJSON(existing, p) {
+					delete(r.Params, k)
+				}
+				continue
+			}
+			if !found {
+				// each route has its own examples as Compile updates them in place
+				examples := make(map[string]Example, len(p.Examples))
+				for name, ex := range p.Examples {
+					examples[name] = ex
+				}
+				p.Examples = examples
+				r.Params[k] = p
+			}
+		}
+	}
+}
+
+// DefaultResponseHeaders adds the headers of v to every response of the document during Compile,
+// such as the rate limit headers or a request id. v is a struct or map read like Response.WithHeadersFrom.
+// A header of the response with the same name is kept.
+//
+//	doc.DefaultResponseHeaders(map[string]any{"X-Request-Id": "3f2a9c"})
+func (o *OpenAPI) DefaultResponseHeaders(v any) {
+	headers := make(map[string]Header, len(o.defaultHeaders))
+	for k, h := range o.defaultHeaders {
+		headers[k] = h
+	}
+	r := &Route{doc: o}
+	for k, h := range r.buildHeaders((Response{}).WithHeadersFrom(v).Headers) {
+		headers[k] = h
+	}
+	o.defaultHeaders = headers
+	o.invalidate()
+}
+
+// applyDefaultHeaders adds the default response headers to the responses of the routes that changed since the last Compile,
+// header names are case insensitive.
+func (o *OpenAPI) applyDefaultHeaders() {
+	for _, r := range o.Paths {
+		if !r.pending() {
+			continue
+		}
+		for code, resp := range r.Responses {
+			if resp.Ref != "" {
+				continue
+			}
+		headers:
+			for name, h := range o.defaultHeaders {
+				for k := range resp.Headers {
+					if strings.EqualFold(k, name) {
+						continue headers
+					}
+				}
+				resp = resp.WithHeader(name, h)
+			}
+			r.Responses[code] = resp
+		}
+	}
+}
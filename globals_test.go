@@ -0,0 +1,131 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestGlobalParams(t *testing.T) {
+	doc := New("", "", "")
+	doc.GetRoute("/users", "get")
+	doc.GetRoute("/users", "post").HeaderParam("X-Request-ID", "abc", "set by the client")
+	doc.GetRoute("/health", "get").NoGlobalParams()
+	doc.GetRoute("/login", "post").NoGlobalParams("session")
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	doc.GlobalHeaderParam("X-Request-ID", "3f2a9c", "id to trace the request")
+	doc.GlobalCookieParam("session", "s1", "")
+	doc.GlobalQueryParam("lang", "en", "")
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	fn := func(key string) ([]string, error) {
+		var l []string
+		for _, p := range doc.Paths[key].Params.List() {
+			l = append(l, p.In+"|"+p.Name+"|"+p.Desc)
+		}
+		return l, nil
+	}
+	cases := trial.Cases[string, []string]{
+		"all globals": {
+			Input:    "/users|get",
+			Expected: []string{"cookie|session|", "header|X-Request-ID|id to trace the request", "query|lang|"},
+		},
+		"route param is kept": {
+			Input:    "/users|post",
+			Expected: []string{"cookie|session|", "header|X-Request-ID|set by the client", "query|lang|"},
+		},
+		"opt out": {
+			Input:    "/health|get",
+			Expected: nil,
+		},
+		"opt out of one": {
+			Input:    "/login|post",
+			Expected: []string{"header|X-Request-ID|id to trace the request", "query|lang|"},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	// opting out after compile removes the global params
+	doc.Paths["/users|get"].NoGlobalParams("lang", "session")
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	l, _ := fn("/users|get")
+	if eq, diff := trial.Equal(l, []string{"header|X-Request-ID|id to trace the request"}); !eq {
+		t.Error(diff)
+	}
+}
+
+func TestDefaultResponseHeaders(t *testing.T) {
+	type rateLimit struct {
+		Limit     int `header:"X-RateLimit-Limit" desc:"requests per hour"`
+		Remaining int `header:"X-RateLimit-Remaining"`
+	}
+	doc := New("", "", "")
+	doc.GetRoute("/users", "get").
+		AddResponse(Response{Status: 200}).
+		AddResponse(Response{Status: 500}.WithHeader("X-Request-ID", Header{Desc: "set by the route"})).
+		AddResponse(Response{Status: 404, Ref: "#/components/responses/NotFound"})
+	doc.Components.Responses = map[string]Response{"NotFound": {Desc: "not found"}}
+	doc.DefaultResponseHeaders(rateLimit{Limit: 1000, Remaining: 999})
+	doc.DefaultResponseHeaders(map[string]any{"X-Request-Id": "3f2a9c"})
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	fn := func(code Code) ([]string, error) {
+		resp := doc.Paths["/users|get"].Responses[code]
+		var l []string
+		for _, name := range sortedKeys(resp.Headers) {
+			l = append(l, name+"|"+resp.Headers[name].Desc)
+		}
+		return l, nil
+	}
+	cases := trial.Cases[Code, []string]{
+		"defaults": {
+			Input:    200,
+			Expected: []string{"X-RateLimit-Limit|requests per hour", "X-RateLimit-Remaining|", "X-Request-Id|"},
+		},
+		"response header is kept": {
+			Input:    500,
+			Expected: []string{"X-RateLimit-Limit|requests per hour", "X-RateLimit-Remaining|", "X-Request-ID|set by the route"},
+		},
+		"ref": {
+			Input:    404,
+			Expected: nil,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
+func TestGlobalsFieldChanges(t *testing.T) {
+	doc := New("", "", "")
+	doc.DetectFieldChanges(true)
+	doc.GenerateHeadOptions()
+	doc.GlobalHeaderParam("X-Tenant", "acme", "")
+	doc.DefaultResponseHeaders(map[string]any{"X-Request-Id": "3f2a9c"})
+	r := doc.GetRoute("/users", "get").AddResponse(Response{Status: 200})
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the direct writes get the globals and the HEAD route is generated again
+	r.Responses[201] = Response{Status: 201}
+	r.Params = Params{}
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := r.Responses[201].Headers["X-Request-Id"]; !found {
+		t.Error("expected the default header on the new response")
+	}
+	if _, found := r.Params["header|X-Tenant"]; !found {
+		t.Errorf("expected the global param got %v", sortedKeys(r.Params))
+	}
+	if _, found := doc.Paths["/users|head"].Responses[201]; !found {
+		t.Error("expected the HEAD route to have the new response")
+	}
+}
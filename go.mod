@@ -2,6 +2,9 @@ module github.com/hydronica/go-openapi
 
 go 1.20
 
-require github.com/hydronica/trial v0.7.2
+require (
+	github.com/hydronica/trial v0.7.2
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require github.com/google/go-cmp v0.6.0
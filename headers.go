@@ -0,0 +1,181 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Header describes a header of a response, it is a Param without a name and location.
+type Header struct {
+	Desc     string             `json:"description,omitempty"` // A brief description of the header.
+	Required bool               `json:"required,omitempty"`    // Determines whether this header is mandatory.
+	Schema   *Schema            `json:"schema,omitempty"`      // The schema defining the type used for the header.
+	Examples map[string]Example `json:"examples,omitempty"`    // Examples of the header’s potential value.
+
+	Ref string `json:"$ref,omitempty"` // link to a header in the components, #/components/headers/{name}
+
+	source any // the value the schema was built from, see Route.buildHeaders
+}
+
+// MarshalJSON writes only the $ref of a referenced Header
+func (h Header) MarshalJSON() ([]byte, error) {
+	if h.Ref != "" {
+		return json.Marshal(reference{Ref: h.Ref})
+	}
+	type header Header
+	return json.Marshal(header(h))
+}
+
+// WithHeader returns the Response with the header set
+func (r Response) WithHeader(name string, h Header) Response {
+	headers := make(map[string]Header, len(r.Headers)+1)
+	for k, v := range r.Headers {
+		headers[k] = v
+	}
+	headers[name] = h
+	r.Headers = headers
+	return r
+}
+
+// CookieOptions are the attributes of a cookie set by a response
+type CookieOptions struct {
+	Desc     string // description of the cookie
+	Example  string // example value of the cookie
+	Path     string
+	Domain   string
+	MaxAge   int // seconds until the cookie expires, 0 for a session cookie
+	Secure   bool
+	HttpOnly bool
+	SameSite http.SameSite
+}
+
+// WithSetCookie documents a cookie set by the response with the Set-Cookie header.
+// A header can only be defined once so multiple cookies are listed in the description
+// of the Set-Cookie header, each with an example.
+//
+//	Response{Status: 200, Desc: "logged in"}.WithSetCookie("session", openapi.CookieOptions{
+//		Desc: "the session id", Path: "/", HttpOnly: true, Secure: true, SameSite: http.SameSiteLaxMode,
+//	})
+func (r Response) WithSetCookie(name string, opts CookieOptions) Response {
+	c := http.Cookie{
+		Name:     name,
+		Value:    opts.Example,
+		Path:     opts.Path,
+		Domain:   opts.Domain,
+		MaxAge:   opts.MaxAge,
+		Secure:   opts.Secure,
+		HttpOnly: opts.HttpOnly,
+		SameSite: opts.SameSite,
+	}
+	if c.Value == "" {
+		c.Value = "..."
+	}
+	// the attributes as written in the header
+	attrs := strings.TrimPrefix(strings.TrimPrefix(c.String(), name+"="+c.Value), "; ")
+
+	line := "`" + name + "`"
+	if opts.Desc != "" {
+		line += ": " + opts.Desc
+	}
+	if attrs != "" {
+		line += " (" + attrs + ")"
+	}
+
+	h := r.Headers["Set-Cookie"]
+	if h.Desc == "" {
+		h.Desc = "cookies set by the response:"
+	}
+	h.Desc += "\n- " + line
+	h.Schema = &Schema{Type: String}
+	examples := make(map[string]Example, len(h.Examples)+1)
+	for k, v := range h.Examples {
+		examples[k] = v
+	}
+	examples[name] = Example{Value: c.String()}
+	h.Examples = examples
+	return r.WithHeader("Set-Cookie", h)
+}
+
+// WithHeadersFrom adds a header for each field of the struct to the Response.
+// The name of the header is set with a header tag, the field name is used otherwise.
+// The desc tag describes the header and the openapi tag can mark it as required.
+// The value of the field is used as an example, a slice adds each value as an example.
+//
+//	type RateLimit struct {
+//		Limit     int `header:"X-RateLimit-Limit" desc:"requests per hour"`
+//		Remaining int `header:"X-RateLimit-Remaining" openapi:"required"`
+//	}
+//	Response{Status: 200}.WithHeadersFrom(RateLimit{Limit: 1000, Remaining: 999})
+func (r Response) WithHeadersFrom(v any) Response {
+	val := reflect.ValueOf(v)
+	if val.Kind() == reflect.Pointer {
+		val = val.Elem()
+	}
+	switch val.Kind() {
+	case reflect.Struct:
+		typ := val.Type()
+		for i := 0; i < val.NumField(); i++ {
+			field := typ.Field(i)
+			name := field.Tag.Get("header")
+			if name == "-" || !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			h := headerFrom(val.Field(i).Interface(), field.Tag.Get("desc"))
+			h.Required, _ = strconv.ParseBool(parseTag(field.Tag.Get("openapi"))["required"])
+			r = r.WithHeader(name, h)
+		}
+	case reflect.Map:
+		iter := val.MapRange()
+		for iter.Next() {
+			r = r.WithHeader(iter.Key().String(), headerFrom(iter.Value().Interface(), ""))
+		}
+	}
+	return r
+}
+
+// headerFrom creates the header with the schema and examples of the primitive value.
+func headerFrom(value any, desc string) Header {
+	h := Header{Desc: desc}
+	if value == nil {
+		h.Desc = "err: invalid header type nil"
+		return h
+	}
+	val := reflect.ValueOf(value)
+	if val.Kind() == reflect.Pointer {
+		val = reflect.Indirect(val)
+		if !val.IsValid() {
+			val = reflect.New(reflect.TypeOf(value).Elem()).Elem()
+		}
+	}
+	values := []reflect.Value{val}
+	if val.Kind() == reflect.Slice || val.Kind() == reflect.Array {
+		values = make([]reflect.Value, val.Len())
+		for i := range values {
+			values[i] = val.Index(i)
+		}
+		val = reflect.New(val.Type().Elem()).Elem()
+	}
+	if !isPrimitive(val.Interface()) || val.Kind() == reflect.Struct {
+		h.Desc = "err: invalid header type " + val.Type().String()
+		return h
+	}
+	s := buildSchema(val.Interface())
+	h.Schema, h.source = &s, val.Interface()
+	for _, v := range values {
+		if v.IsZero() {
+			continue
+		}
+		if h.Examples == nil {
+			h.Examples = make(map[string]Example)
+		}
+		h.Examples[fmt.Sprint(v.Interface())] = Example{Value: v.Interface()}
+	}
+	return h
+}
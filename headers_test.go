@@ -0,0 +1,111 @@
+package openapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestWithSetCookie(t *testing.T) {
+	type input struct {
+		name string
+		opts CookieOptions
+	}
+	fn := func(in []input) (Header, error) {
+		r := Response{Status: 200}
+		for _, c := range in {
+			r = r.WithSetCookie(c.name, c.opts)
+		}
+		return r.Headers["Set-Cookie"], nil
+	}
+	cases := trial.Cases[[]input, Header]{
+		"session": {
+			Input: []input{{"session", CookieOptions{
+				Desc: "the session id", Example: "abc123", Path: "/",
+				HttpOnly: true, Secure: true, SameSite: http.SameSiteLaxMode,
+			}}},
+			Expected: Header{
+				Desc:     "cookies set by the response:\n- `session`: the session id (Path=/; HttpOnly; Secure; SameSite=Lax)",
+				Schema:   &Schema{Type: String},
+				Examples: map[string]Example{"session": {Value: "session=abc123; Path=/; HttpOnly; Secure; SameSite=Lax"}},
+			},
+		},
+		"multiple cookies": {
+			Input: []input{
+				{"session", CookieOptions{HttpOnly: true}},
+				{"theme", CookieOptions{Desc: "ui theme", Example: "dark", MaxAge: 3600}},
+			},
+			Expected: Header{
+				Desc:   "cookies set by the response:\n- `session` (HttpOnly)\n- `theme`: ui theme (Max-Age=3600)",
+				Schema: &Schema{Type: String},
+				Examples: map[string]Example{
+					"session": {Value: "session=...; HttpOnly"},
+					"theme":   {Value: "theme=dark; Max-Age=3600"},
+				},
+			},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	// the headers of the original response are not changed
+	r := Response{Status: 200}.WithSetCookie("a", CookieOptions{})
+	r.WithSetCookie("b", CookieOptions{})
+	if eq, diff := trial.Equal(len(r.Headers["Set-Cookie"].Examples), 1); !eq {
+		t.Error(diff)
+	}
+}
+
+func TestWithHeadersFrom(t *testing.T) {
+	type rateLimit struct {
+		Limit     int      `header:"X-RateLimit-Limit" desc:"requests per hour"`
+		Remaining int      `header:"X-RateLimit-Remaining" openapi:"required"`
+		Reset     *int64   `header:"X-RateLimit-Reset"`
+		Version   []string `desc:"api versions"`
+		Ignored   string   `header:"-"`
+		internal  string
+	}
+	fn := func(v any) (map[string]Header, error) {
+		return Response{Status: 200}.WithHeadersFrom(v).Headers, nil
+	}
+	cases := trial.Cases[any, map[string]Header]{
+		"struct": {
+			Input: rateLimit{Limit: 1000, Remaining: 999, Version: []string{"v1", "v2"}, Ignored: "x", internal: "y"},
+			Expected: map[string]Header{
+				"X-RateLimit-Limit": {
+					Desc:     "requests per hour",
+					Schema:   &Schema{Type: Integer},
+					Examples: map[string]Example{"1000": {Value: 1000}},
+					source:   1000,
+				},
+				"X-RateLimit-Remaining": {
+					Required: true,
+					Schema:   &Schema{Type: Integer},
+					Examples: map[string]Example{"999": {Value: 999}},
+					source:   999,
+				},
+				"X-RateLimit-Reset": {Schema: &Schema{Type: Integer, Format: Int64}, source: int64(0)},
+				"Version": {
+					Desc:     "api versions",
+					Schema:   &Schema{Type: String},
+					Examples: map[string]Example{"v1": {Value: "v1"}, "v2": {Value: "v2"}},
+					source:   "",
+				},
+			},
+		},
+		"map": {
+			Input: map[string]any{"X-Request-ID": "abc", "X-Bad": map[string]string{}},
+			Expected: map[string]Header{
+				"X-Request-ID": {Schema: &Schema{Type: String}, Examples: map[string]Example{"abc": {Value: "abc"}}, source: "abc"},
+				"X-Bad":        {Desc: "err: invalid header type map[string]string"},
+			},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	doc := New("", "", "")
+	doc.GetRoute("/items", "get").AddResponse(Response{Status: 200}.WithHeadersFrom(map[string]any{"X-Bad": nil}))
+	if err := doc.Compile(); err == nil {
+		t.Error("expected compile error for an invalid header")
+	}
+}
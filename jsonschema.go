@@ -0,0 +1,95 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// Draft202012 is the JSON Schema dialect of the exported schemas
+const Draft202012 = "https://json-schema.org/draft/2020-12/schema"
+
+var schemaRef = regexp.MustCompile(`"\$ref":"#/components/schemas/([^"]+)"`)
+
+// ExportJSONSchema returns the component schema name as a self-contained JSON Schema (2020-12) document,
+// so the models can validate messages outside of http. The component schemas it references are
+// added to $defs and nullable and example are written as a type array and examples.
+// Compile should be called first so the schemas are moved to the components.
+//
+//	b, err := doc.ExportJSONSchema("main.user")
+func (o *OpenAPI) ExportJSONSchema(name string) ([]byte, error) {
+	if _, found := o.Components.Schemas[name]; !found {
+		return nil, fmt.Errorf("schema %q not found", name)
+	}
+	schemas := map[string][]byte{}
+	queue := []string{name}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if _, found := schemas[n]; found {
+			continue
+		}
+		s, found := o.Components.Schemas[n]
+		if !found {
+			return nil, fmt.Errorf("%v: #/components/schemas/%v not found", name, n)
+		}
+		b, err := json.Marshal(s)
+		if err != nil {
+			return nil, fmt.Errorf("schema %q: %w", n, err)
+		}
+		if b, err = to31(b, true); err != nil {
+			return nil, fmt.Errorf("schema %q: %w", n, err)
+		}
+		for _, m := range schemaRef.FindAllSubmatch(b, -1) {
+			queue = append(queue, string(m[1]))
+		}
+		schemas[n] = b
+	}
+
+	// refs to the exported schema point to the root, the others to $defs
+	refs := func(b []byte) []byte {
+		return schemaRef.ReplaceAllFunc(b, func(ref []byte) []byte {
+			if string(schemaRef.FindSubmatch(ref)[1]) == name {
+				return []byte(`"$ref":"#"`)
+			}
+			return bytes.Replace(ref, []byte("#/components/schemas/"), []byte("#/$defs/"), 1)
+		})
+	}
+	var buf bytes.Buffer
+	buf.WriteString(`{"$schema":"` + Draft202012 + `"`)
+	if root := refs(schemas[name]); len(root) > 2 {
+		buf.WriteByte(',')
+		buf.Write(root[1 : len(root)-1])
+	}
+	delete(schemas, name)
+	if len(schemas) > 0 {
+		buf.WriteString(`,"$defs":{`)
+		for i, n := range sortedKeys(schemas) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			k, _ := json.Marshal(n)
+			buf.Write(k)
+			buf.WriteByte(':')
+			buf.Write(refs(schemas[n]))
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// ExportJSONSchemas returns every component schema as a self-contained JSON Schema document by its name,
+// see ExportJSONSchema.
+func (o *OpenAPI) ExportJSONSchemas() (map[string][]byte, error) {
+	docs := make(map[string][]byte, len(o.Components.Schemas))
+	for _, name := range sortedKeys(o.Components.Schemas) {
+		b, err := o.ExportJSONSchema(name)
+		if err != nil {
+			return nil, err
+		}
+		docs[name] = b
+	}
+	return docs, nil
+}
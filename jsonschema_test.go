@@ -0,0 +1,59 @@
+package openapi
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestExportJSONSchema(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	doc := New("", "", "")
+	doc.GetRoute("/users", "get").
+		AddResponse(Response{Status: 200}.WithExample(user{Name: "bob"}))
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	doc.Components.Schemas["group"] = Schema{Type: Object, Properties: map[string]Schema{
+		"users": {Type: Array, Items: &Schema{Ref: "#/components/schemas/openapi.user"}},
+	}}
+	doc.Components.Schemas["node"] = Schema{Type: Object, Nullable: true, Properties: map[string]Schema{
+		"next": {Ref: "#/components/schemas/node"},
+	}}
+
+	fn := func(name string) (string, error) {
+		b, err := doc.ExportJSONSchema(name)
+		return string(b), err
+	}
+	cases := trial.Cases[string, string]{
+		"defs": {
+			Input: "group",
+			Expected: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"users":{"type":"array","items":{"$ref":"#/$defs/openapi.user"}}},` +
+				`"$defs":{"openapi.user":{"title":"openapi.user","type":"object","properties":{"name":{"type":"string"}}}}}`,
+		},
+		"no refs": {
+			Input:    "openapi.user",
+			Expected: `{"$schema":"https://json-schema.org/draft/2020-12/schema","title":"openapi.user","type":"object","properties":{"name":{"type":"string"}}}`,
+		},
+		"self ref": {
+			Input:    "node",
+			Expected: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":["object","null"],"properties":{"next":{"$ref":"#"}}}`,
+		},
+		"not found": {
+			Input:       "missing",
+			ExpectedErr: errors.New(`schema "missing" not found`),
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	docs, err := doc.ExportJSONSchemas()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(sortedKeys(docs), []string{"group", "node", "openapi.user"}); !eq {
+		t.Error(diff)
+	}
+}
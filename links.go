@@ -0,0 +1,40 @@
+package openapi
+
+import "encoding/json"
+
+// Link describes a possible design-time link for a response, the values of the response
+// are used as the parameters of another operation with runtime expressions such as $response.body#/id.
+type Link struct {
+	OperationRef string         `json:"operationRef,omitempty"` // a relative or absolute URI reference to an operation, mutually exclusive with OperationID
+	OperationID  string         `json:"operationId,omitempty"`  // the name of an existing operation with a unique operationId
+	Params       map[string]any `json:"parameters,omitempty"`   // the values or runtime expressions passed to the parameters of the operation
+	RequestBody  any            `json:"requestBody,omitempty"`  // a value or runtime expression used as the request body of the operation
+	Desc         string         `json:"description,omitempty"`  // A description of the link. CommonMark syntax MAY be used for rich text representation.
+	Server       *Server        `json:"server,omitempty"`       // a server used by the target operation
+
+	Ref string `json:"$ref,omitempty"` // link to a link in the components, #/components/links/{name}
+}
+
+// MarshalJSON writes only the $ref of a referenced Link
+func (l Link) MarshalJSON() ([]byte, error) {
+	if l.Ref != "" {
+		return json.Marshal(reference{Ref: l.Ref})
+	}
+	type link Link
+	return json.Marshal(link(l))
+}
+
+// WithLink returns the Response with a link to the operation, params map the parameter names
+// of the operation to values or runtime expressions.
+//
+//	// the id returned by POST /users feeds GET /users/{id}
+//	Response{Status: 201}.WithLink("GetUser", "getUser", map[string]any{"id": "$response.body#/id"})
+func (r Response) WithLink(name, operationID string, params map[string]any) Response {
+	links := make(map[string]Link, len(r.Links)+1)
+	for k, v := range r.Links {
+		links[k] = v
+	}
+	links[name] = Link{OperationID: operationID, Params: params}
+	r.Links = links
+	return r
+}
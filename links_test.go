@@ -0,0 +1,36 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestWithLink(t *testing.T) {
+	fn := func(resp Response) (string, error) {
+		b, err := json.Marshal(resp)
+		return string(b), err
+	}
+	base := Response{Status: 201, Desc: "created"}
+	linked := base.WithLink("GetUser", "getUser", map[string]any{"id": "$response.body#/id"})
+	cases := trial.Cases[Response, string]{
+		"link": {
+			Input:    linked,
+			Expected: `{"description":"created","links":{"GetUser":{"operationId":"getUser","parameters":{"id":"$response.body#/id"}}}}`,
+		},
+		"original unchanged": {
+			Input:    base,
+			Expected: `{"description":"created"}`,
+		},
+		"second link": {
+			Input:    linked.WithLink("DeleteUser", "deleteUser", map[string]any{"id": "$response.body#/id"}),
+			Expected: `{"description":"created","links":{"DeleteUser":{"operationId":"deleteUser","parameters":{"id":"$response.body#/id"}},"GetUser":{"operationId":"getUser","parameters":{"id":"$response.body#/id"}}}}`,
+		},
+		"ref": {
+			Input:    Response{Desc: "ok", Links: map[string]Link{"GetUser": {Ref: "#/components/links/GetUser", Desc: "ignored"}}},
+			Expected: `{"description":"ok","links":{"GetUser":{"$ref":"#/components/links/GetUser"}}}`,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
@@ -0,0 +1,168 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Localized are translations of a text keyed by language tag (de, de-AT).
+type Localized map[string]string
+
+// get the text for the language, a regional tag such as de-AT falls back to de.
+func (l Localized) get(lang string) (string, bool) {
+	if s, found := l[lang]; found {
+		return s, true
+	}
+	if base, _, found := strings.Cut(lang, "-"); found {
+		s, found := l[base]
+		return s, found
+	}
+	return "", false
+}
+
+func (l Localized) with(lang, s string) Localized {
+	m := make(Localized, len(l)+1)
+	for k, v := range l {
+		m[k] = v
+	}
+	m[lang] = s
+	return m
+}
+
+// Translate sets the description of the API for the language
+func (i *Info) Translate(lang, desc string) {
+	i.Translations = i.Translations.with(lang, desc)
+}
+
+// Translate sets the summary of the operation for the language
+func (r *Route) Translate(lang, summary string) *Route {
+	r.Translations = r.Translations.with(lang, summary)
+	return r
+}
+
+// Translate returns the schema with the description set for the language.
+// Descriptions of struct fields are translated with a desc.{lang} tag.
+//
+//	Name string `json:"name" desc:"name of the user" desc.de:"Name des Benutzers"`
+func (s Schema) Translate(lang, desc string) Schema {
+	s.Translations = s.Translations.with(lang, desc)
+	return s
+}
+
+// Localize returns a copy of the document with the descriptions of the info and schemas
+// and the summaries of the operations in the language. Texts without a translation are kept.
+func (o *OpenAPI) Localize(lang string) (*OpenAPI, error) {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := NewFromJson(string(b))
+	if err != nil {
+		return nil, err
+	}
+	o.copySettings(doc)
+
+	if s, found := o.Info.Translations.get(lang); found {
+		doc.Info.Desc = s
+	}
+	for k, r := range o.Paths {
+		dst := doc.Paths[k]
+		if s, found := r.Translations.get(lang); found {
+			dst.Summary = s
+		}
+		if r.Requests != nil {
+			localizeContent(dst.Requests.Content, r.Requests.Content, lang)
+		}
+		for code, resp := range r.Responses {
+			localizeContent(dst.Responses[code].Content, resp.Content, lang)
+		}
+		for key, p := range r.Params {
+			if p.Schema != nil && dst.Params[key].Schema != nil {
+				localizeSchema(dst.Params[key].Schema, *p.Schema, lang)
+			}
+		}
+	}
+	for name, s := range o.Components.Schemas {
+		dst := doc.Components.Schemas[name]
+		localizeSchema(&dst, s, lang)
+		doc.Components.Schemas[name] = dst
+	}
+	return doc, nil
+}
+
+func localizeContent(dst, src Content, lang string) {
+	for mime, m := range src {
+		d, found := dst[mime]
+		if !found {
+			continue
+		}
+		localizeSchema(&d.Schema, m.Schema, lang)
+		dst[mime] = d
+	}
+}
+
+// localizeSchema sets the translated descriptions of src on dst, which is a copy of src.
+func localizeSchema(dst *Schema, src Schema, lang string) {
+	if s, found := src.Translations.get(lang); found {
+		dst.Desc = s
+	}
+	if src.Items != nil && dst.Items != nil {
+		localizeSchema(dst.Items, *src.Items, lang)
+	}
+	for k, p := range src.Properties {
+		d, found := dst.Properties[k]
+		if !found {
+			continue
+		}
+		localizeSchema(&d, p, lang)
+		dst.Properties[k] = d
+	}
+}
+
+// localizedTag returns the values of the tags named key.{lang}
+func localizedTag(tag reflect.StructTag, key string) Localized {
+	var l Localized
+	prefix := key + "."
+	// parse the tag the same way as reflect.StructTag.Lookup
+	for tag != "" {
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		name := string(tag[:i])
+		tag = tag[i+1:]
+
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		qvalue := string(tag[:i+1])
+		tag = tag[i+1:]
+
+		if lang, found := strings.CutPrefix(name, prefix); found {
+			if value, err := strconv.Unquote(qvalue); err == nil {
+				l = l.with(lang, value)
+			}
+		}
+	}
+	return l
+}
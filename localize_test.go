@@ -0,0 +1,62 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestLocalize(t *testing.T) {
+	type user struct {
+		Name string `json:"name" desc:"name of the user" desc.de:"Name des Benutzers"`
+		Age  int    `json:"age" desc:"age in years"`
+	}
+	doc := New("users", "1.0.0", "manage users")
+	doc.Info.Translate("de", "Benutzer verwalten")
+	doc.GetRoute("/users", "get").
+		Translate("de", "Benutzer auflisten").
+		AddResponse(Response{Status: 200}.WithExample(user{Name: "bob"}))
+	doc.Paths["/users|get"].Summary = "list users"
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	type output struct {
+		Info    string
+		Summary string
+		Name    string
+		Age     string
+	}
+	fn := func(lang string) (output, error) {
+		d, err := doc.Localize(lang)
+		if err != nil {
+			return output{}, err
+		}
+		props := d.Components.Schemas["openapi.user"].Properties
+		return output{
+			Info:    d.Info.Desc,
+			Summary: d.Paths["/users|get"].Summary,
+			Name:    props["name"].Desc,
+			Age:     props["age"].Desc,
+		}, nil
+	}
+	cases := trial.Cases[string, output]{
+		"de": {
+			Input:    "de",
+			Expected: output{Info: "Benutzer verwalten", Summary: "Benutzer auflisten", Name: "Name des Benutzers", Age: "age in years"},
+		},
+		"region falls back": {
+			Input:    "de-AT",
+			Expected: output{Info: "Benutzer verwalten", Summary: "Benutzer auflisten", Name: "Name des Benutzers", Age: "age in years"},
+		},
+		"untranslated": {
+			Input:    "fr",
+			Expected: output{Info: "manage users", Summary: "list users", Name: "name of the user", Age: "age in years"},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	if doc.Info.Desc != "manage users" {
+		t.Errorf("expected the document to be unchanged got %q", doc.Info.Desc)
+	}
+}
@@ -0,0 +1,202 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Merge adds the routes, webhooks, components, tags, servers and security of other to the document.
+// The info of the document is kept. Routes, webhooks and components that exist in both documents
+// with a different value are conflicts, they are not merged and returned as a joined error.
+// The routes of other follow the routes of the document in the order they were added to other.
+func (o *OpenAPI) Merge(other *OpenAPI) error {
+	if o.Paths == nil {
+		o.Paths = make(Router)
+	}
+	added, errs := mergeRoutes(o.Paths, other.Paths, other.routeOrder, "route")
+	o.routeOrder = append(o.routeOrder, added...)
+	if len(other.Webhooks) > 0 && o.Webhooks == nil {
+		o.Webhooks = make(Router)
+	}
+	_, webhookErrs := mergeRoutes(o.Webhooks, other.Webhooks, nil, "webhook")
+	errs = append(errs, webhookErrs...)
+	o.setDoc(o.Paths)
+	o.setDoc(o.Webhooks)
+
+	errs = append(errs, mergeComponents(&o.Components.Schemas, other.Components.Schemas, "schema")...)
+	errs = append(errs, mergeComponents(&o.Components.Responses, other.Components.Responses, "response")...)
+	errs = append(errs, mergeComponents(&o.Components.Parameters, other.Components.Parameters, "parameter")...)
+	errs = append(errs, mergeComponents(&o.Components.RequestBodies, other.Components.RequestBodies, "request body")...)
+	errs = append(errs, mergeComponents(&o.Components.SecuritySchemes, other.Components.SecuritySchemes, "security scheme")...)
+	errs = append(errs, mergeComponents(&o.Components.Examples, other.Components.Examples, "example")...)
+	errs = append(errs, mergeComponents(&o.Components.Headers, other.Components.Headers, "header")...)
+	errs = append(errs, mergeComponents(&o.Components.Links, other.Components.Links, "link")...)
+
+	for _, t := range other.Tags {
+		found := false
+		for _, tag := range o.Tags {
+			found = found || tag.Name == t.Name
+		}
+		if !found {
+			o.Tags = append(o.Tags, t)
+		}
+	}
+	for _, s := range other.Servers {
+		found := false
+		for _, server := range o.Servers {
+			found = found || server.URL == s.URL
+		}
+		if !found {
+			o.Servers = append(o.Servers, s)
+		}
+	}
+	for _, req := range other.Security {
+		found := false
+		for _, r := range o.Security {
+			found = found || reflect.DeepEqual(r, req)
+		}
+		if !found {
+			o.Security = append(o.Security, req)
+		}
+	}
+	for k, v := range other.Extensions {
+		if _, found := o.Extensions[k]; !found {
+			o.Extensions = o.Extensions.with(k, v)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// mergeRoutes adds the routes of other to m, routes with the same key and a different value
+// are conflicts. The routes are added in order, then the routes not in order by key,
+// the keys of the added routes are returned.
+func mergeRoutes(m, other Router, order []string, kind string) (added []string, errs []error) {
+	keys := make([]string, 0, len(other))
+	seen := make(map[string]bool, len(other))
+	for _, l := range [][]string{order, sortedKeys(other)} {
+		for _, k := range l {
+			if _, found := other[k]; found && !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	for _, k := range keys {
+		r := *other[k]
+		r.compiled = false
+		if existing, found := m[k]; found {
+			if !sameJSON(existing, &r) {
+				name, method, _ := strings.Cut(k, "|")
+				errs = append(errs, fmt.Errorf("conflicting %v %v %v", kind, method, name))
+			}
+			continue
+		}
+		m[k] = &r
+		added = append(added, k)
+	}
+	return added, errs
+}
+
+// mergeComponents adds the components of other to m, components
+// with the same name and a different value are conflicts.
+func mergeComponents[V any](m *map[string]V, other map[string]V, kind string) (errs []error) {
+	if len(other) == 0 {
+		return nil
+	}
+	if *m == nil {
+		*m = make(map[string]V, len(other))
+	}
+	for _, k := range sortedKeys(other) {
+		if v, found := (*m)[k]; found {
+			if !sameJSON(v, other[k]) {
+				errs = append(errs, fmt.Errorf("conflicting %v %q", kind, k))
+			}
+			continue
+		}
+		(*m)[k] = other[k]
+	}
+	return errs
+}
+
+// sameJSON reports if a and b have the same json value
+func sameJSON(a, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
+
+// Change is a difference between two documents at the json pointer Path.
+type Change struct {
+	Type string // added, removed or changed
+	Path string // json pointer of the value, /paths/~1users/get
+}
+
+func (c Change) String() string {
+	return c.Type + " " + c.Path
+}
+
+// Diff compares the json values of the documents and returns the changes
+// from base to head sorted by path.
+func Diff(base, head *OpenAPI) ([]Change, error) {
+	a, err := base.generic()
+	if err != nil {
+		return nil, err
+	}
+	b, err := head.generic()
+	if err != nil {
+		return nil, err
+	}
+	changes := diffJSON(a, b, "", nil)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+func diffJSON(a, b any, path string, changes []Change) []Change {
+	switch va := a.(type) {
+	case map[string]any:
+		vb, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		for k, v := range va {
+			p := path + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(k)
+			if w, found := vb[k]; found {
+				changes = diffJSON(v, w, p, changes)
+			} else {
+				changes = append(changes, Change{Type: "removed", Path: p})
+			}
+		}
+		for k := range vb {
+			if _, found := va[k]; !found {
+				p := path + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(k)
+				changes = append(changes, Change{Type: "added", Path: p})
+			}
+		}
+		return changes
+	case []any:
+		vb, ok := b.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < len(va) || i < len(vb); i++ {
+			p := fmt.Sprintf("%v/%d", path, i)
+			switch {
+			case i >= len(vb):
+				changes = append(changes, Change{Type: "removed", Path: p})
+			case i >= len(va):
+				changes = append(changes, Change{Type: "added", Path: p})
+			default:
+				changes = diffJSON(va[i], vb[i], p, changes)
+			}
+		}
+		return changes
+	}
+	if !reflect.DeepEqual(a, b) {
+		changes = append(changes, Change{Type: "changed", Path: path})
+	}
+	return changes
+}
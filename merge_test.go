@@ -0,0 +1,108 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestMerge(t *testing.T) {
+	a := New("a", "1.0.0", "")
+	a.Servers = []Server{{URL: "https://a.example.com"}}
+	a.AddTags(Tag{Name: "users"})
+	a.GetRoute("/users", "get").AddResponse(Response{Status: 200, Desc: "ok"})
+	a.Components.Schemas = map[string]Schema{"User": {Type: Object}}
+
+	b := New("b", "2.0.0", "")
+	b.Servers = []Server{{URL: "https://a.example.com"}, {URL: "https://b.example.com"}}
+	b.AddTags(Tag{Name: "users"}, Tag{Name: "orders"})
+	b.GetRoute("/users", "get").AddResponse(Response{Status: 200, Desc: "other"})
+	b.GetRoute("/orders", "get").AddResponse(Response{Status: 200, Desc: "ok"})
+	b.Components.Schemas = map[string]Schema{"User": {Type: String}, "Order": {Type: Object}}
+
+	err := a.Merge(b)
+	if eq, diff := trial.Equal(err.Error(), "conflicting route get /users\nconflicting schema \"User\""); !eq {
+		t.Error(diff)
+	}
+	if eq, diff := trial.Equal(sortedKeys(a.Paths), []string{"/orders|get", "/users|get"}); !eq {
+		t.Error(diff)
+	}
+	if eq, diff := trial.Equal(a.Components.Schemas, map[string]Schema{"User": {Type: Object}, "Order": {Type: Object}}); !eq {
+		t.Error(diff)
+	}
+	if eq, diff := trial.Equal(a.Tags, []Tag{{Name: "users"}, {Name: "orders"}}); !eq {
+		t.Error(diff)
+	}
+	if eq, diff := trial.Equal(a.Servers, []Server{{URL: "https://a.example.com"}, {URL: "https://b.example.com"}}); !eq {
+		t.Error(diff)
+	}
+	if a.Info.Title != "a" {
+		t.Errorf("expected info to be kept got %q", a.Info.Title)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	base := New("api", "1.0.0", "")
+	base.GetRoute("/users", "get").AddResponse(Response{Status: 200, Desc: "ok"})
+	base.GetRoute("/internal", "get").AddResponse(Response{Status: 200, Desc: "ok"})
+
+	head := New("api", "1.1.0", "")
+	head.GetRoute("/users", "get").AddResponse(Response{Status: 200, Desc: "ok"})
+	head.GetRoute("/users", "post").AddResponse(Response{Status: 201, Desc: "created"})
+
+	changes, err := Diff(base, head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(changes, []Change{
+		{Type: "changed", Path: "/info/version"},
+		{Type: "removed", Path: "/paths/~1internal"},
+		{Type: "added", Path: "/paths/~1users/post"},
+	}); !eq {
+		t.Error(diff)
+	}
+}
+
+func TestMergeWebhooks(t *testing.T) {
+	a := New("a", "1.0.0", "")
+	a.GetWebhook("created", "post").AddResponse(Response{Status: 200, Desc: "received"})
+	a.GetWebhook("deleted", "post").AddResponse(Response{Status: 200, Desc: "received"})
+
+	b := New("b", "1.0.0", "")
+	b.GetWebhook("created", "post").AddResponse(Response{Status: 204, Desc: "no content"})
+	b.GetWebhook("deleted", "post").AddResponse(Response{Status: 200, Desc: "received"})
+	b.GetWebhook("updated", "post").AddResponse(Response{Status: 200, Desc: "received"})
+
+	err := a.Merge(b)
+	if eq, diff := trial.Equal(err.Error(), "conflicting webhook post created"); !eq {
+		t.Error(diff)
+	}
+	if eq, diff := trial.Equal(sortedKeys(a.Webhooks), []string{"created|post", "deleted|post", "updated|post"}); !eq {
+		t.Error(diff)
+	}
+	if d := a.Webhooks["created|post"].Responses[200].Desc; d != "received" {
+		t.Errorf("expected the webhook of the document to be kept got %q", d)
+	}
+}
+
+func TestMergeRouteOrder(t *testing.T) {
+	a := New("a", "1.0.0", "")
+	a.OrderPaths(PathsInsertion)
+	a.GetRoute("/users", "get")
+
+	b := New("b", "1.0.0", "")
+	b.GetRoute("/orders", "get")
+	b.GetRoute("/accounts", "get")
+	b.GetRoute("/users", "get")
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := pathKeys(a.JSONBytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(paths, []string{"/users|get", "/orders|get", "/accounts|get"}); !eq {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,116 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+)
+
+// GenerateHeadOptions adds a HEAD and an OPTIONS operation for every GET route during Compile.
+// The HEAD operation has the params and responses of the GET route without a body,
+// the OPTIONS operation responds with the allowed methods of the path.
+// Routes that are defined explicitly are not replaced, use Route.NoHeadOptions to skip a GET route.
+func (o *OpenAPI) GenerateHeadOptions() {
+	o.headOptions = true
+	o.invalidate()
+}
+
+// NoHeadOptions skips the route when HEAD and OPTIONS operations are generated.
+func (r *Route) NoHeadOptions() *Route {
+	r.skipHeadOptions = true
+	r.compiled = false
+	return r
+}
+
+// generateHeadOptions derives the HEAD and OPTIONS operations of the GET routes.
+// Generated routes are replaced when the GET route changes and removed when it opts out.
+func (o *OpenAPI) generateHeadOptions() {
+	for _, k := range sortedKeys(o.Paths) {
+		r, found := o.Paths[k]
+		if !found || !strings.EqualFold(r.method, "get") {
+			continue
+		}
+		head, options := methodCase(r.method, "head"), methodCase(r.method, "options")
+		for _, method := range []string{head, options} {
+			existing, found := o.Paths[r.path+"|"+method]
+			if found && !existing.generated {
+				continue
+			}
+			if r.skipHeadOptions {
+				delete(o.Paths, r.path+"|"+method)
+				continue
+			}
+			if found && !r.pending() && method == head {
+				continue
+			}
+			if method == head {
+				o.Paths[r.path+"|"+method] = o.headRoute(r, method)
+			} else {
+				o.Paths[r.path+"|"+method] = o.optionsRoute(r, method)
+			}
+		}
+	}
+}
+
+// headRoute copies the GET route with the content of the responses removed, the headers are kept
+func (o *OpenAPI) headRoute(get *Route, method string) *Route {
+	r := &Route{
+		path:       get.path,
+		method:     method,
+		generated:  true,
+		doc:        o,
+		Tag:        get.Tag,
+		Summary:    get.Summary,
+		Params:     make(Params, len(get.Params)),
+		Security:   get.Security,
+		Deprecated: get.Deprecated,
+	}
+	for k, p := range get.Params {
+		r.Params[k] = p
+	}
+	for code, resp := range get.Responses {
+		desc, headers := resp.Desc, resp.Headers
+		if name, found := strings.CutPrefix(resp.Ref, "#/components/responses/"); found {
+			desc, headers = o.Components.Responses[name].Desc, o.Components.Responses[name].Headers
+		}
+		r.AddResponse(Response{Status: code, Desc: desc, Headers: headers, Extensions: resp.Extensions})
+	}
+	return r
+}
+
+// optionsRoute responds with the methods allowed on the path of the GET route
+func (o *OpenAPI) optionsRoute(get *Route, method string) *Route {
+	var allowed []string
+	for _, rt := range o.Paths {
+		if rt.path == get.path {
+			allowed = append(allowed, strings.ToUpper(rt.method))
+		}
+	}
+	if _, found := o.Paths[get.path+"|"+method]; !found {
+		allowed = append(allowed, strings.ToUpper(method))
+	}
+	sort.Strings(allowed)
+
+	r := &Route{
+		path:      get.path,
+		method:    method,
+		generated: true,
+		doc:       o,
+		Tag:       get.Tag,
+		Summary:   "allowed methods",
+		Params:    make(Params),
+	}
+	for k, p := range get.Params {
+		if p.In == "path" {
+			r.Params[k] = p
+		}
+	}
+	return r.AddResponse(Response{Status: 204, Desc: "allowed methods: " + strings.Join(allowed, ", ")})
+}
+
+// methodCase returns the method in upper case when the GET method is upper case
+func methodCase(get, method string) string {
+	if get == strings.ToUpper(get) {
+		return strings.ToUpper(method)
+	}
+	return method
+}
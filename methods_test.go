@@ -0,0 +1,96 @@
+package openapi
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestGenerateHeadOptions(t *testing.T) {
+	doc := New("", "", "")
+	doc.GenerateHeadOptions()
+	doc.GetRoute("/users/{id}", "get").Tags("users").
+		QueryParam("fields", "name", "fields to return").
+		AddResponse(Response{Status: 200, Desc: "the user"}.WithExample(map[string]string{"name": "bob"}))
+	doc.GetRoute("/users/{id}", "delete").AddResponse(Response{Status: 204, Desc: "deleted"})
+	doc.GetRoute("/items", "get").AddResponse(Response{Status: 200, Desc: "items"})
+	doc.GetRoute("/items", "head").AddResponse(Response{Status: 200, Desc: "explicit"})
+	doc.GetRoute("/health", "get").NoHeadOptions().AddResponse(Response{Status: 200, Desc: "ok"})
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	type output struct {
+		Tags      []string
+		Params    []string
+		Responses map[Code]string
+		Content   bool
+	}
+	fn := func(key string) (output, error) {
+		r, found := doc.Paths[key]
+		if !found {
+			return output{}, errors.New("not found")
+		}
+		out := output{Tags: r.Tag, Responses: make(map[Code]string)}
+		for _, p := range r.Params.List() {
+			out.Params = append(out.Params, p.In+"|"+p.Name)
+		}
+		for code, resp := range r.Responses {
+			out.Responses[code] = resp.Desc
+			out.Content = out.Content || len(resp.Content) > 0
+		}
+		return out, nil
+	}
+	cases := trial.Cases[string, output]{
+		"head without body": {
+			Input: "/users/{id}|head",
+			Expected: output{
+				Tags:      []string{"users"},
+				Params:    []string{"path|id", "query|fields"},
+				Responses: map[Code]string{200: "the user"},
+			},
+		},
+		"options": {
+			Input: "/users/{id}|options",
+			Expected: output{
+				Tags:      []string{"users"},
+				Params:    []string{"path|id"},
+				Responses: map[Code]string{204: "allowed methods: DELETE, GET, HEAD, OPTIONS"},
+			},
+		},
+		"explicit head is kept": {
+			Input:    "/items|head",
+			Expected: output{Responses: map[Code]string{200: "explicit"}},
+		},
+		"options with explicit head": {
+			Input:    "/items|options",
+			Expected: output{Responses: map[Code]string{204: "allowed methods: GET, HEAD, OPTIONS"}},
+		},
+		"opt out": {
+			Input:     "/health|head",
+			ShouldErr: true,
+		},
+		"opt out options": {
+			Input:     "/health|options",
+			ShouldErr: true,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	// generated routes follow changes of the GET route
+	doc.GetRoute("/users/{id}", "get").AddResponse(Response{Status: 404, Desc: "not found"})
+	doc.GetRoute("/items", "get").NoHeadOptions()
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(len(doc.Paths["/users/{id}|head"].Responses), 2); !eq {
+		t.Error(diff)
+	}
+	if _, found := doc.Paths["/items|options"]; found {
+		t.Error("options of /items should be removed")
+	}
+	if _, found := doc.Paths["/items|head"]; !found {
+		t.Error("explicit head of /items should be kept")
+	}
+}
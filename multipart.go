@@ -0,0 +1,58 @@
+package openapi
+
+// File marks a file part of a multipart request body, see WithMultipart.
+type File struct {
+	Desc        string // description of the file
+	ContentType string // the media types of the file such as image/png, a comma separated list for several
+}
+
+// WithMultipart adds a multipart/form-data content to the RequestBody, such as the body of an upload.
+// A File value is a binary part with the content type of its Encoding, a []File several files with the same name.
+// The other values are parts with their json schema and are the example of the content.
+//
+//	RequestBody{}.WithMultipart(map[string]any{
+//		"avatar": openapi.File{ContentType: "image/png, image/jpeg"},
+//		"name":   "bob",
+//	})
+func (r RequestBody) WithMultipart(fields map[string]any) RequestBody {
+	if r.Content == nil {
+		r.Content = make(Content)
+	}
+	m := r.Content[Form]
+	s := Schema{Type: Object, Properties: make(map[string]Schema, len(fields))}
+	example := make(map[string]any, len(fields))
+	for _, k := range sortedKeys(fields) {
+		v := fields[k]
+		var f File
+		switch t := v.(type) {
+		case File:
+			f = t
+			s.Properties[k] = Schema{Type: String, Format: Binary, Desc: f.Desc}
+		case []File:
+			if len(t) > 0 {
+				f = t[0]
+			}
+			s.Properties[k] = Schema{Type: Array, Items: &Schema{Type: String, Format: Binary}, Desc: f.Desc}
+		default:
+			s.Properties[k] = buildSchema(v)
+			example[k] = v
+			continue
+		}
+		if f.ContentType != "" {
+			if m.Encoding == nil {
+				m.Encoding = make(map[string]Encoding)
+			}
+			enc := m.Encoding[k]
+			enc.ContentType = f.ContentType
+			m.Encoding[k] = enc
+		}
+	}
+	if m.Schema.Type == "" && m.Schema.Ref == "" {
+		m.Schema = s
+	}
+	if len(example) > 0 {
+		m.addExample("", Example{Value: example})
+	}
+	r.Content[Form] = m
+	return r
+}
@@ -0,0 +1,39 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestWithMultipart(t *testing.T) {
+	fn := func(fields map[string]any) (string, error) {
+		doc := New("", "", "")
+		doc.GetRoute("/upload", "post").
+			AddRequest(RequestBody{}.WithMultipart(fields)).
+			AddResponse(Response{Status: 204})
+		if err := doc.Compile(); err != nil {
+			return "", err
+		}
+		b, err := json.Marshal(doc.Paths["/upload|post"].Requests)
+		return string(b), err
+	}
+	cases := trial.Cases[map[string]any, string]{
+		"file": {
+			Input: map[string]any{
+				"avatar": File{Desc: "the profile picture", ContentType: "image/png, image/jpeg"},
+				"name":   "bob",
+			},
+			Expected: `{"content":{"multipart/form-data":{"schema":{"type":"object","properties":{` +
+				`"avatar":{"type":"string","format":"binary","description":"the profile picture"},"name":{"type":"string"}}},` +
+				`"examples":{"":{"value":{"name":"bob"}}},"encoding":{"avatar":{"contentType":"image/png, image/jpeg"}}}}}`,
+		},
+		"files": {
+			Input: map[string]any{"attachments": []File{{}}},
+			Expected: `{"content":{"multipart/form-data":{"schema":{"type":"object","properties":{` +
+				`"attachments":{"type":"array","items":{"type":"string","format":"binary"}}}}}}}`,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
@@ -0,0 +1,92 @@
+package openapi
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SchemaUse is where an object schema of a request or response is used when it is named
+type SchemaUse struct {
+	Path    string
+	Method  string
+	Request bool // the schema of the request body, otherwise of the response with the Status
+	Status  Code
+	MIME    MIMEType
+	Schema  Schema // the schema with the title it was built with
+}
+
+// NamingStrategy returns the name of the component schema of a request or response,
+// it is set on the document with SetNamingStrategy.
+type NamingStrategy func(use SchemaUse) string
+
+// HashNaming is the default NamingStrategy, schemas keep the title they were built with:
+// the name of the type or a hash of the keys of a map.
+func HashNaming(use SchemaUse) string {
+	return use.Schema.Title
+}
+
+// RouteNaming is a NamingStrategy that names the schemas of maps and anonymous structs
+// after the method, path and status such as GetUsersIdResponse200 or PostUsersRequest,
+// so the names do not change when a key is added. Named types keep their title.
+func RouteNaming(use SchemaUse) string {
+	if !generatedTitle(use.Schema.Title) {
+		return use.Schema.Title
+	}
+	var b strings.Builder
+	b.WriteString(pascalCase(use.Method))
+	for _, segment := range strings.Split(use.Path, "/") {
+		b.WriteString(pascalCase(segment))
+	}
+	if use.Request {
+		b.WriteString("Request")
+		return b.String()
+	}
+	b.WriteString("Response")
+	if use.Status == DefaultStatus {
+		b.WriteString("Default")
+	} else {
+		b.WriteString(strconv.Itoa(int(use.Status)))
+	}
+	return b.String()
+}
+
+// SetNamingStrategy sets how the object schemas of the requests and responses are named
+// when Compile moves them to the components, nil restores HashNaming.
+//
+//	doc.SetNamingStrategy(openapi.RouteNaming)
+func (o *OpenAPI) SetNamingStrategy(fn NamingStrategy) {
+	o.namingStrategy = fn
+	o.invalidate()
+}
+
+// generatedTitle reports if the title was not named after a type,
+// such as the hash of the keys of a map or an anonymous struct.
+// A hash is up to 16 lower case hex digits, the names of types have a package or an upper case letter.
+func generatedTitle(title string) bool {
+	if title == "" || strings.HasPrefix(title, "struct {") || strings.HasPrefix(title, "map[") {
+		return true
+	}
+	if len(title) > 16 {
+		return false
+	}
+	for _, r := range title {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// pascalCase joins the words of s with their first letter in upper case
+func pascalCase(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		r, size := utf8.DecodeRuneInString(w)
+		words[i] = string(unicode.ToUpper(r)) + strings.ToLower(w[size:])
+	}
+	return strings.Join(words, "")
+}
@@ -0,0 +1,76 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestNamingStrategy(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	fn := func(strategy NamingStrategy) ([]string, error) {
+		doc := New("", "", "")
+		doc.SetNamingStrategy(strategy)
+		doc.GetRoute("/users/{id}", "get").
+			AddResponse(Response{Status: 200}.WithExample(user{Name: "bob"})).
+			AddResponse(Response{Status: 404}.WithExample(map[string]any{"error": "not found"}))
+		doc.GetRoute("/users", "post").
+			AddRequest(RequestBody{}.WithExample(struct {
+				Name string `json:"name"`
+			}{Name: "bob"})).
+			AddResponse(Response{Status: 201})
+		err := doc.Compile()
+		return sortedKeys(doc.Components.Schemas), err
+	}
+	cases := trial.Cases[NamingStrategy, []string]{
+		"hash": {
+			Input:    nil,
+			Expected: []string{"2dcc2dddc2e00000", "openapi.user", `struct { Name string "json:\"name\"" }`},
+		},
+		"route": {
+			Input:    RouteNaming,
+			Expected: []string{"GetUsersIdResponse404", "PostUsersRequest", "openapi.user"},
+		},
+		"callback": {
+			Input: func(use SchemaUse) string {
+				return strings.ToLower(use.Method) + "." + use.Schema.Title
+			},
+			Expected: []string{"get.2dcc2dddc2e00000", "get.openapi.user", `post.struct { Name string "json:\"name\"" }`},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
+func TestGeneratedTitle(t *testing.T) {
+	fn := func(title string) (bool, error) {
+		return generatedTitle(title), nil
+	}
+	cases := trial.Cases[string, bool]{
+		"empty":      {Input: "", Expected: true},
+		"hash":       {Input: "2dcc2dddc2e00000", Expected: true},
+		"short":      {Input: "2", Expected: true},
+		"anonymous":  {Input: "struct { Name string }", Expected: true},
+		"map":        {Input: "map[string]int", Expected: true},
+		"type":       {Input: "openapi.user", Expected: false},
+		"short name": {Input: "User", Expected: false},
+		"long":       {Input: "2dcc2dddc2e000001", Expected: false},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
+func TestPascalCase(t *testing.T) {
+	fn := func(s string) (string, error) {
+		return pascalCase(s), nil
+	}
+	cases := trial.Cases[string, string]{
+		"method":     {Input: "get", Expected: "Get"},
+		"path":       {Input: "user-accounts", Expected: "UserAccounts"},
+		"param":      {Input: "{id}", Expected: "Id"},
+		"non ascii":  {Input: "état", Expected: "État"},
+		"multi byte": {Input: "ünits_ÖL", Expected: "ÜnitsÖl"},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
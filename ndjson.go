@@ -0,0 +1,57 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// WithNDJSON documents a newline-delimited json response, the schema describes each line.
+// A slice is written as one line per element, with the schema of the elements.
+//
+//	Response{Status: 200, Desc: "export of all users"}.WithNDJSON([]User{{ID: 1}, {ID: 2}})
+func (r Response) WithNDJSON(item any) Response {
+	r.Content = r.Content.addNDJSON(item)
+	return r
+}
+
+// addNDJSON adds the lines of the item as an example to the application/x-ndjson Media
+func (c Content) addNDJSON(item any) Content {
+	if c == nil {
+		c = make(Content)
+	}
+	items := []any{item}
+	if v := reflect.ValueOf(item); v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		items = make([]any, v.Len())
+		for i := range items {
+			items[i] = v.Index(i).Interface()
+		}
+		item = reflect.New(v.Type().Elem()).Elem().Interface()
+		if len(items) > 0 {
+			item = items[0]
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, v := range items {
+		if err := enc.Encode(v); err != nil {
+			c["invalid/json"] = Media{Examples: map[string]Example{"invalid": {Value: fmt.Sprintf("ndjson: %v", err)}}}
+			return c
+		}
+	}
+
+	m := c[NDJSON]
+	s := buildSchema(item)
+	if m.Schema.Title == "" && m.Schema.Ref == "" && m.Schema.Type == "" {
+		m.Schema = s
+	}
+	name := s.Title
+	if name == "" {
+		name = "lines"
+	}
+	m.addExample(name, Example{Value: buf.String()})
+	c[NDJSON] = m
+	return c
+}
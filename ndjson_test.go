@@ -0,0 +1,45 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestWithNDJSON(t *testing.T) {
+	type user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	fn := func(item any) (Media, error) {
+		return Response{Status: 200}.WithNDJSON(item).Content[NDJSON], nil
+	}
+	cases := trial.Cases[any, Media]{
+		"object": {
+			Input: user{ID: 1, Name: "bob"},
+			Expected: Media{
+				Schema:   buildSchema(user{}),
+				Examples: map[string]Example{"openapi.user": {Value: "{\"id\":1,\"name\":\"bob\"}\n"}},
+			},
+		},
+		"slice": {
+			Input: []user{{ID: 1, Name: "bob"}, {ID: 2, Name: "amy"}},
+			Expected: Media{
+				Schema:   buildSchema(user{}),
+				Examples: map[string]Example{"openapi.user": {Value: "{\"id\":1,\"name\":\"bob\"}\n{\"id\":2,\"name\":\"amy\"}\n"}},
+			},
+		},
+		"primitive lines": {
+			Input: []int{1, 2},
+			Expected: Media{
+				Schema:   Schema{Type: Integer},
+				Examples: map[string]Example{"lines": {Value: "1\n2\n"}},
+			},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	if _, found := (Response{}).WithNDJSON(func() {}).Content["invalid/json"]; !found {
+		t.Error("expected invalid/json for an item that cannot be encoded")
+	}
+}
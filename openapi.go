@@ -1,18 +1,48 @@
 package openapi
 
 import (
+	"encoding/json"
 	"strconv"
 )
 
-// OpenAPI represents the definition of the openapi specification 3.0.3
+// OpenAPI represents the definition of the openapi specification 3.0.3, see SetSpecVersion for 3.1
 type OpenAPI struct {
-	Version      string        `json:"openapi"`                // the  semantic version number of the OpenAPI Specification version
-	Servers      []Server      `json:"servers,omitempty"`      // Array of Server Objects, which provide connectivity information to a target server.
-	Info         Info          `json:"info"`                   // REQUIRED. Provides metadata about the API. The metadata MAY be used by tooling as required.
-	Tags         []Tag         `json:"tags,omitempty"`         // A list of tags used by the specification with additional metadata
-	Paths        Router        `json:"paths"`                  // key= path|method
-	Components   Components    `json:"components,omitempty"`   // reuseable components
-	ExternalDocs *ExternalDocs `json:"externalDocs,omitempty"` //Additional external documentation.
+	Version           string                `json:"openapi"`                     // the  semantic version number of the OpenAPI Specification version
+	Servers           []Server              `json:"servers,omitempty"`           // Array of Server Objects, which provide connectivity information to a target server.
+	Info              Info                  `json:"info"`                        // REQUIRED. Provides metadata about the API. The metadata MAY be used by tooling as required.
+	JSONSchemaDialect string                `json:"jsonSchemaDialect,omitempty"` // 3.1 only, the default $schema of the Schema Objects, see SetSpecVersion
+	Tags              []Tag                 `json:"tags,omitempty"`              // A list of tags used by the specification with additional metadata
+	Paths             Router                `json:"paths"`                       // key= path|method
+	Webhooks          Router                `json:"webhooks,omitempty"`          // 3.1 only, requests the API sends to its consumers, key= name|method
+	Components        Components            `json:"components,omitempty"`        // reuseable components
+	Security          []SecurityRequirement `json:"security,omitempty"`          // A declaration of which security mechanisms can be used across the API.
+	ExternalDocs      *ExternalDocs         `json:"externalDocs,omitempty"`      //Additional external documentation.
+	Extensions        Extensions            `json:"-"`                           // Specification Extensions, fields starting with x-
+
+	exampleLimits   *ExampleLimits // size limits applied to examples during Compile
+	requireSecurity bool           // Validate flags operations without security
+	securityAllow   []string       // path patterns allowed without security
+
+	exampleSanitizer ExampleSanitizer  // applied to the examples during Compile
+	sanitized        map[uint64]bool   // checksums of the examples already sanitized, see sanitizeExamples
+	fieldRedactor    FieldRedactor     // removes fields from the schemas and examples during Compile
+	headOptions      bool              // generate HEAD and OPTIONS operations for GET routes
+	globalParams     Params            // params added to every operation during Compile
+	defaultHeaders   map[string]Header // headers added to every response during Compile
+	autoTag          int               // number of path segments used to tag untagged operations
+	autoOperationIDs bool              // generate the missing operationIds during Compile
+	fieldChanges     bool              // compile the routes changed by a direct write to their fields, see DetectFieldChanges
+	tagLess          func(a, b Tag) bool
+	tagDescs         map[string]string // descriptions of the tags added during Compile, see DescribeTag
+	pathOrder        PathOrder
+	routeOrder       []string // keys of the routes in the order they were added
+	processors       []DocumentProcessor
+	resolved         bool            // the component refs are restored during Compile, see Resolve
+	resolvedSchemas  map[string]bool // component schemas inlined by Resolve
+	schemas          *schemaBuilder  // settings used to build the schemas, see OverrideSchema
+	namingStrategy   NamingStrategy
+	schemaOrigins    map[string]string // where the component schemas were first used, see Compile
+	warnings         []string          // issues found by the last Compile that do not make the document invalid, see Warnings
 }
 
 type Server struct {
@@ -34,6 +64,9 @@ type Info struct {
 	Terms   string   `json:"termsOfService,omitempty"` // A URL to the Terms of Service for the API. MUST be in the format of a URL.
 	Contact *Contact `json:"contact,omitempty"`        // The contact information for the exposed API.
 	License *License `json:"license,omitempty"`        // The license information for the exposed API.
+
+	Extensions   Extensions `json:"-"` // Specification Extensions, fields starting with x-
+	Translations Localized  `json:"-"` // descriptions by language, see Localize
 }
 
 type Contact struct {
@@ -54,7 +87,7 @@ type Tag struct {
 }
 
 type ExternalDocs struct {
-	Desc string `json:"description,omitempty""`        // A short description of the target documentation. CommonMark syntax MAY be used for rich text representation.
+	Desc string `json:"description,omitempty"`         // A short description of the target documentation. CommonMark syntax MAY be used for rich text representation.
 	URL  string `json:"url,omitempty" required:"true"` // REQUIRED. The URL for the target documentation. Value MUST be in the format of a URL.
 }
 
@@ -80,6 +113,11 @@ func (c *Code) UnmarshalText(b []byte) error {
 	return err
 }
 
+// reference is a Reference Object that links to a component of the document
+type reference struct {
+	Ref string `json:"$ref"`
+}
+
 type MIMEType string
 type Content map[MIMEType]Media
 
@@ -87,35 +125,39 @@ type Media struct {
 	Schema Schema `json:"schema,omitempty"` // The schema defining the content of the request, response, or parameter.
 	// Examples of the media type. Each example object SHOULD match the media type and specified schema if present. The examples field is mutually exclusive of the example field. Furthermore, if referencing a schema which contains an example, the examples value SHALL override the example provided by the schema.
 	Examples map[string]Example `json:"examples,omitempty"`
+	// A map between a property name and its encoding information. The key, being the property name, MUST exist in the schema as a property.
+	// Only used by form-urlencoded and multipart content, see WithFormExample.
+	Encoding map[string]Encoding `json:"encoding,omitempty"`
+
+	source any // the value the schema was built from, see Route.buildContent
 
 	// NOT Supported:
 	//Example of the media type. The example object SHOULD be in the correct format as specified by the media type. The example field is mutually exclusive of the examples field. Furthermore, if referencing a schema which contains an example, the example value SHALL override the example provided by the schema.
 	//Example  any                 `json:"example,omitempty"` -> uses examples even for one example
-	//A map between a property name and its encoding information. The key, being the property name, MUST exist in the schema as a property.
-	//Encoding map[string]Encoding `json:"encoding,omitempty"`
 }
 
 type Components struct {
-	Schemas map[string]Schema `json:"schemas,omitempty"`
+	Schemas         map[string]Schema         `json:"schemas,omitempty"`
+	Responses       map[string]Response       `json:"responses,omitempty"`
+	Parameters      map[string]Param          `json:"parameters,omitempty"`
+	RequestBodies   map[string]RequestBody    `json:"requestBodies,omitempty"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+	Examples        map[string]Example        `json:"examples,omitempty"`
+	Links           map[string]Link           `json:"links,omitempty"`
+	Headers         map[string]Header         `json:"headers,omitempty"`
 
 	//NOT implemented
 	/*
-		Parameters []Params
-		SecuritySchemes struct{}
-		RequestBodies []RequestBody
-		Responses Responses
-		Headers []Params
-		Examples []Example
-		Links []string
 		Callbacks struct{} */
 }
 
+// Encoding describes how a property of a form-urlencoded or multipart request body is serialized
 type Encoding struct {
-	ContentType string `json:"contentType,omitempty"` // The Content-Type for encoding a specific property.
-	// headers  map[string]headerObject :  not implemented needed if media is multipart
-	Style string `json:"style"` // Describes how a specific property value will be serialized depending on its type.
-	// explode       bool not implemented needed if media is application/x-www-form-urlencoded
-	// allowReserved bool not implemented needed if media is application/x-www-form-urlencoded
+	ContentType   string            `json:"contentType,omitempty"`   // The Content-Type for encoding a specific property, such as image/png for a multipart part.
+	Headers       map[string]Header `json:"headers,omitempty"`       // The headers of a multipart part.
+	Style         Style             `json:"style,omitempty"`         // Describes how a specific property value will be serialized depending on its type, such as deepObject for a nested object.
+	Explode       *bool             `json:"explode,omitempty"`       // The values of an array or object generate separate parameters.
+	AllowReserved bool              `json:"allowReserved,omitempty"` // Reserved characters such as /?#[]@ are sent without percent-encoding.
 }
 
 // Example object MAY be extended with Specification Extensions.
@@ -124,25 +166,102 @@ type Example struct {
 	Desc    string `json:"description,omitempty"` // Long description for the example. CommonMark syntax MAY be used for rich text representation.
 	//ExternalValue string `json:"externalValue,omitempty"` // A URL that points to the literal example. This provides the capability to reference examples that cannot easily be included in JSON or YAML documents. The value field and externalValue field are mutually exclusive.
 	Value any `json:"value"` // Embedded literal example. The value field and externalValue field are mutually exclusive. To represent examples of media types that cannot naturally represented in JSON or YAML, use a string value to contain the example, escaping where necessary.
+
+	Ref string `json:"$ref,omitempty"` // link to an example in the components, #/components/examples/{name}
+}
+
+// MarshalJSON writes only the $ref of a referenced Example
+func (e Example) MarshalJSON() ([]byte, error) {
+	if e.Ref != "" {
+		return json.Marshal(reference{Ref: e.Ref})
+	}
+	type example Example
+	return json.Marshal(example(e))
+}
+
+// UnmarshalJSON keeps the value of the example as a json.RawMessage,
+// so large examples of a loaded document are only decoded when accessed with Decode.
+func (e *Example) UnmarshalJSON(b []byte) error {
+	type example Example
+	var raw struct {
+		example
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*e = Example(raw.example)
+	if len(raw.Value) > 0 {
+		e.Value = raw.Value
+	}
+	return nil
+}
+
+// Decode returns the value of the example, a value that is still raw json,
+// such as an example of a loaded document, is unmarshalled into a generic value.
+// Read the value of an example with Decode rather than Value.
+func (e Example) Decode() (any, error) {
+	raw, ok := e.Value.(json.RawMessage)
+	if !ok {
+		return e.Value, nil
+	}
+	var v any
+	err := json.Unmarshal(raw, &v)
+	return v, err
 }
 
 // Schema Object defines data types. objects (structs), maps, primitives and arrays
 // This object is an extended subset of the JSON Schema Specification
 type Schema struct {
-	Title string `json:"title,omitempty"`
-	Type  Type   `json:"type,omitempty"`
-	//Format string `json:"format,omitempty"`
-	Desc string `json:"description,omitempty"`
+	Title   string `json:"title,omitempty"`
+	Type    Type   `json:"type,omitempty"`
+	Format  string `json:"format,omitempty"` // the format of the type such as date-time, uuid or int64
+	Desc    string `json:"description,omitempty"`
+	Enum    []any  `json:"enum,omitempty"`    // the allowed values
+	Example any    `json:"example,omitempty"` // an example of the value
+
+	Nullable   bool `json:"nullable,omitempty"`   // null is allowed, written as a type array in 3.1 documents
+	ReadOnly   bool `json:"readOnly,omitempty"`   // the property is only sent in responses, such as an id set by the server
+	WriteOnly  bool `json:"writeOnly,omitempty"`  // the property is only sent in requests, such as a password
+	Deprecated bool `json:"deprecated,omitempty"` // the property should no longer be used
+
+	// validation of the value, see the min, max, minLength, maxLength and pattern struct tags
+	Minimum   *float64 `json:"minimum,omitempty"`
+	Maximum   *float64 `json:"maximum,omitempty"`
+	MinLength *int     `json:"minLength,omitempty"`
+	MaxLength *int     `json:"maxLength,omitempty"`
+	MinItems  *int     `json:"minItems,omitempty"`
+	MaxItems  *int     `json:"maxItems,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"` // a regular expression the string matches
 
-	// Enum []string
 	// Default any
-	// Pattern string
-	// Example any
 	Items *Schema `json:"items,omitempty"`
+	XML   *XML    `json:"xml,omitempty"`  // describes the xml representation of the property
 	Ref   string  `json:"$ref,omitempty"` // link to object, #/components/schemas/{object}
 
 	// Property definitions MUST be a Schema Object and not a standard JSON Schema (inline or referenced).
 	Properties map[string]Schema `json:"properties,omitempty"`
+	Required   []string          `json:"required,omitempty"` // the properties that are required
+	Order      []string          `json:"-"`                  // the keys of the properties in order, such as the fields of a struct
+
+	AdditionalProperties any `json:"additionalProperties,omitempty"` // true or the Schema of the properties not listed
+
+	// the value matches exactly one, any or all of the schemas, see OneOf, AnyOf and AllOf
+	OneOf []Schema `json:"oneOf,omitempty"`
+	AnyOf []Schema `json:"anyOf,omitempty"`
+	AllOf []Schema `json:"allOf,omitempty"`
+
+	Extensions   Extensions `json:"-"` // Specification Extensions, fields starting with x-
+	Translations Localized  `json:"-"` // descriptions by language, see Localize
 }
 
 type Properties map[string]Schema
+
+// XML describes the xml representation of a schema
+type XML struct {
+	Name      string `json:"name,omitempty"`      // the name of the element or attribute, the schema name by default
+	Namespace string `json:"namespace,omitempty"` // the URI of the namespace
+	Prefix    string `json:"prefix,omitempty"`    // the prefix used for the name
+	Attribute bool   `json:"attribute,omitempty"` // the property is an attribute instead of an element
+	Wrapped   bool   `json:"wrapped,omitempty"`   // arrays are wrapped in an element, only used with arrays
+}
@@ -1,9 +1,16 @@
 package openapi
 
 import (
+	"bytes"
 	_ "embed"
+	"encoding/json"
+	"errors"
 	"os"
+	"runtime/debug"
 	"testing"
+
+	"github.com/hydronica/trial"
+	"gopkg.in/yaml.v3"
 )
 
 //go:embed swagger.example.json
@@ -24,6 +31,48 @@ func TestNewFromJson(t *testing.T) {
 	f.Close()
 }
 
+func TestResponseRoundTrip(t *testing.T) {
+	fn := func(resps []Response) (map[Code]Response, error) {
+		doc := New("", "", "")
+		r := doc.GetRoute("/users", "get")
+		for _, resp := range resps {
+			r.AddResponse(resp)
+		}
+		doc2, err := NewFromJson(doc.JSON())
+		if err != nil {
+			return nil, err
+		}
+		rt := doc2.GetRoute("/users", "get")
+		// adding the loaded responses to another route keeps their status
+		r2 := doc2.GetRoute("/accounts", "get")
+		for _, resp := range rt.Responses {
+			r2.AddResponse(resp)
+		}
+		if ok, diff := trial.Equal(r2.Responses, rt.Responses); !ok {
+			return nil, errors.New(diff)
+		}
+		return rt.Responses, nil
+	}
+	cases := trial.Cases[[]Response, map[Code]Response]{
+		"description only": {
+			Input: []Response{{Status: 204, Desc: "deleted"}, {Status: 404, Desc: "not found"}},
+			Expected: map[Code]Response{
+				204: {Status: 204, Desc: "deleted"},
+				404: {Status: 404, Desc: "not found"},
+			},
+		},
+		"default": {
+			Input:    []Response{{Status: DefaultStatus, Desc: "unexpected error"}},
+			Expected: map[Code]Response{DefaultStatus: {Status: DefaultStatus, Desc: "unexpected error"}},
+		},
+		"ref": {
+			Input:    []Response{{Status: 401, Ref: "#/components/responses/Unauthorized"}},
+			Expected: map[Code]Response{401: {Status: 401, Ref: "#/components/responses/Unauthorized"}},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
 func ExampleNew() {
 	type tStruct struct {
 		Name string `json:"name"`
@@ -47,3 +96,283 @@ func ExampleNew() {
 
 	doc.JSON()
 }
+
+func TestWriteJSON(t *testing.T) {
+	doc, err := NewFromJson(jsonfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := doc.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected, err := json.MarshalIndent(doc, "", "    ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(buf.String(), string(expected)); !eq {
+		t.Error(diff)
+	}
+
+	// empty document
+	buf.Reset()
+	doc = New("title", "v1", "")
+	if err := doc.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected, _ = json.MarshalIndent(doc, "", "    ")
+	if eq, diff := trial.Equal(buf.String(), string(expected)); !eq {
+		t.Error(diff)
+	}
+}
+
+func TestJSONWith(t *testing.T) {
+	doc := New("a <b> & c", "v1", "")
+	doc.GetRoute("/users/{id}", "get").
+		AddResponse(Response{Status: 200, Desc: "ok"}.WithExample(map[string]any{"empty": map[string]any{}}))
+
+	expected, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := func(opts MarshalOptions) (string, error) {
+		b, err := doc.JSONWith(opts)
+		return string(b), err
+	}
+	cases := trial.Cases[MarshalOptions, string]{
+		"compact": {
+			Input: MarshalOptions{},
+			Expected: `{"openapi":"3.0.3","info":{"title":"a <b> & c","version":"v1","description":""},"paths":{"/users/{id}":{"get":{"responses":{"200":{"description":"ok","content":{"application/json":{"schema":{"title":"22887eaf52e00000","type":"object","properties":{"empty":{"title":"map[string]interface {}","type":"object"}}},"examples":{"22887eaf52e00000":{"value":{"empty":{}}}}}}}},` +
+				`"parameters":[{"name":"id","in":"path","examples":{}}]}}},"components":{}}`,
+		},
+		"omit empty and escape": {
+			Input: MarshalOptions{EscapeHTML: true, OmitEmpty: true},
+			Expected: `{"openapi":"3.0.3","info":{"title":"a \u003cb\u003e \u0026 c","version":"v1","description":""},"paths":{"/users/{id}":{"get":{"responses":{"200":{"description":"ok","content":{"application/json":{"schema":{"title":"22887eaf52e00000","type":"object","properties":{"empty":{"title":"map[string]interface {}","type":"object"}}},"examples":{"22887eaf52e00000":{"value":{"empty":{}}}}}}}},` +
+				`"parameters":[{"name":"id","in":"path"}]}}}}`,
+		},
+		"indent": {
+			Input:    MarshalOptions{Indent: "  ", EscapeHTML: true},
+			Expected: string(expected),
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
+func TestWriteSplit(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	doc := New("split", "v1", "")
+	doc.GetRoute("/users/{id}", "get").
+		AddResponse(Response{Status: 200}.WithExample(user{Name: "bob"}))
+	doc.GetRoute("/users/{id}", "delete").AddResponse(Response{Status: 204})
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err := doc.WriteSplit(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(name string) map[string]any {
+		b, err := os.ReadFile(dir + "/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := make(map[string]any)
+		if err := json.Unmarshal(b, &m); err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	root := read("openapi.json")
+	if eq, diff := trial.Equal(root["paths"], map[string]any{
+		"/users/{id}": map[string]any{"$ref": "paths/users_id.json"},
+	}); !eq {
+		t.Error(diff)
+	}
+	if eq, diff := trial.Equal(root["components"], map[string]any{
+		"schemas": map[string]any{
+			"openapi.user": map[string]any{"$ref": "components/schemas/openapi.user.json"},
+		},
+	}); !eq {
+		t.Error(diff)
+	}
+
+	path := read("paths/users_id.json")
+	if _, found := path["delete"]; !found {
+		t.Error("expected delete method in path file")
+	}
+	b, _ := json.Marshal(path["get"])
+	if !bytes.Contains(b, []byte(`"$ref":"../components/schemas/openapi.user.json"`)) {
+		t.Errorf("expected relative schema ref got %s", b)
+	}
+	if s := read("components/schemas/openapi.user.json"); s["title"] != "openapi.user" {
+		t.Errorf("unexpected schema %v", s)
+	}
+}
+
+func TestWriteSplitLoad(t *testing.T) {
+	type group struct {
+		Name   string `json:"name"`
+		Parent *group `json:"parent"`
+	}
+	type user struct {
+		Name  string `json:"name"`
+		Group group  `json:"group"`
+	}
+	doc := New("split", "v1", "")
+	doc.RegisterExample("bob", user{Name: "bob"})
+	doc.GetRoute("/users/{id}", "get").
+		AddResponse(Response{Status: 200}.WithExample(user{Name: "bob", Group: group{Name: "admin"}})).
+		AddResponse(Response{Status: 404}.WithExampleRef("bob"))
+	doc.GetRoute("/users/{id}", "delete").AddResponse(Response{Status: 204})
+	doc.GetRoute("/groups", "post").AddRequest(RequestBody{}.WithExample(group{Name: "admin"}))
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	generic := func(b []byte) any {
+		var v any
+		if err := json.Unmarshal(b, &v); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	dir := t.TempDir()
+	if err := doc.WriteSplitWith(dir, SplitOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := NewResolver().Load(dir + "/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(generic(loaded.JSONBytes()), generic(doc.JSONBytes())); !eq {
+		t.Error(diff)
+	}
+
+	// the yaml files have the content of the json files
+	yamlDir := t.TempDir()
+	if err := doc.WriteSplitWith(yamlDir, SplitOptions{YAML: true}); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err = NewResolver().Load(yamlDir + "/openapi.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(generic(loaded.JSONBytes()), generic(doc.JSONBytes())); !eq {
+		t.Error(diff)
+	}
+	for _, file := range []string{"openapi", "paths/users_id", "paths/groups", "components/schemas/openapi.group", "components/schemas/openapi.user"} {
+		j, err := os.ReadFile(dir + "/" + file + ".json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		y, err := os.ReadFile(yamlDir + "/" + file + ".yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v any
+		if err := yaml.Unmarshal(y, &v); err != nil {
+			t.Fatal(err)
+		}
+		b, _ := json.Marshal(v)
+		if eq, diff := trial.Equal(generic(b), generic(bytes.ReplaceAll(j, []byte(".json"), []byte(".yaml")))); !eq {
+			t.Errorf("%v: %v", file, diff)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	doc := New("stats", "v1", "")
+	doc.GetRoute("/items/{id}", "get").
+		PathParam("id", []int{1, 2}, "").
+		AddResponse(Response{Status: 200}.WithExample(struct{ Name string }{"apple"})).
+		AddResponse(Response{Status: 404})
+	doc.GetRoute("/items", "post").
+		AddRequest(RequestBody{}.WithJSONString(`{"name":"apple"}`))
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	eq, diff := trial.Equal(doc.Stats(), Stats{
+		Routes:   2,
+		Schemas:  2,
+		Examples: 4,
+		Bytes:    len(doc.JSONBytes()),
+	})
+	if !eq {
+		t.Error(diff)
+	}
+}
+
+func TestLazyExamples(t *testing.T) {
+	doc := New("lazy", "v1", "")
+	doc.GetRoute("/items", "get").
+		AddResponse(Response{Status: 200}.WithNamedExample("item", map[string]any{
+			"name": "apple",
+			"tags": []any{"fruit", "red"},
+		}))
+	expected := doc.JSON()
+
+	loaded, err := NewFromJson(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ex := loaded.Paths["/items|get"].Responses[200].Content[Json].Examples["item"]
+	if _, ok := ex.Value.(json.RawMessage); !ok {
+		t.Errorf("expected example to be raw json, got %T", ex.Value)
+	}
+	v, err := ex.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(v, map[string]any{"name": "apple", "tags": []any{"fruit", "red"}}); !eq {
+		t.Error(diff)
+	}
+
+	// raw examples are written unchanged
+	if eq, diff := trial.Equal(loaded.JSON(), expected); !eq {
+		t.Error(diff)
+	}
+
+	// raw examples are decoded by the redactor, sanitizer and limits
+	loaded.RedactFields("secret")
+	loaded.LimitExamples(ExampleLimits{MaxItems: 1})
+	loaded.GetRoute("/items", "get").compiled = false
+	if err := loaded.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	v, _ = loaded.Paths["/items|get"].Responses[200].Content[Json].Examples["item"].Decode()
+	if eq, diff := trial.Equal(v, map[string]any{"name": "apple", "tags": []any{"fruit"}}); !eq {
+		t.Error(diff)
+	}
+}
+
+func TestStampBuildInfo(t *testing.T) {
+	fn := func(info *debug.BuildInfo) (any, error) {
+		doc := New("", "", "")
+		doc.stampBuildInfo(info, info != nil)
+		return doc.Info.Extensions["x-build"], nil
+	}
+	cases := trial.Cases[*debug.BuildInfo, any]{
+		"vcs": {
+			Input: &debug.BuildInfo{GoVersion: "go1.21.0", Settings: []debug.BuildSetting{
+				{Key: "vcs", Value: "git"},
+				{Key: "vcs.revision", Value: "6ed7c54"},
+				{Key: "vcs.time", Value: "2024-01-02T15:04:05Z"},
+				{Key: "vcs.modified", Value: "true"},
+			}},
+			Expected: BuildInfo{Revision: "6ed7c54", Time: "2024-01-02T15:04:05Z", Modified: true, GoVersion: "go1.21.0"},
+		},
+		"no vcs": {
+			Input:    &debug.BuildInfo{GoVersion: "go1.21.0"},
+			Expected: BuildInfo{GoVersion: "go1.21.0"},
+		},
+		"no build info": {
+			Input:    nil,
+			Expected: nil,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
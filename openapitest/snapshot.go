@@ -0,0 +1,81 @@
+// Package openapitest provides test helpers for documents built with the openapi package,
+// it is separate so the openapi package does not depend on the testing package.
+package openapitest
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hydronica/go-openapi"
+)
+
+// Snapshot compiles the document and compares it with the snapshot file,
+// the test fails with the list of changes when they differ.
+// The snapshot is written instead when the test binary has an -update flag that is set
+// or the UPDATE_SNAPSHOTS environment variable is true.
+//
+//	var _ = flag.Bool("update", false, "update snapshots")
+//
+//	func TestAPI(t *testing.T) {
+//		openapitest.Snapshot(t, NewDoc(), "testdata/openapi.json")
+//	}
+func Snapshot(t testing.TB, doc *openapi.OpenAPI, path string) {
+	t.Helper()
+	if err := doc.Compile(); err != nil {
+		t.Errorf("compile: %v", err)
+	}
+	got := doc.JSONBytes()
+	if updateSnapshots() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("snapshot %v does not exist, run the test with -update to create it", path)
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(want, got) {
+		return
+	}
+	snapshot, err := openapi.NewFromJson(string(want))
+	if err != nil {
+		t.Fatalf("snapshot %v: %v", path, err)
+	}
+	changes, err := openapi.Diff(snapshot, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s strings.Builder
+	for _, c := range changes {
+		s.WriteString("\n\t" + c.String())
+	}
+	if len(changes) == 0 {
+		s.WriteString("\n\tformatting changed")
+	}
+	t.Errorf("document does not match snapshot %v, run the test with -update to accept the changes:%v", path, s.String())
+}
+
+// updateSnapshots reports if an -update flag or the UPDATE_SNAPSHOTS environment variable is set
+func updateSnapshots() bool {
+	if f := flag.Lookup("update"); f != nil {
+		if b, _ := strconv.ParseBool(f.Value.String()); b {
+			return true
+		}
+	}
+	b, _ := strconv.ParseBool(os.Getenv("UPDATE_SNAPSHOTS"))
+	return b
+}
@@ -0,0 +1,83 @@
+package openapitest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hydronica/go-openapi"
+	"github.com/hydronica/trial"
+)
+
+// recorder is a testing.TB that records failures, Fatal stops the snapshot with a panic
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatal(args ...any) {
+	r.errs = append(r.errs, fmt.Sprint(args...))
+	panic(r)
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	panic(r)
+}
+
+func snapshot(doc *openapi.OpenAPI, path string) (errs []string) {
+	r := &recorder{}
+	defer func() {
+		if v := recover(); v != nil && v != r {
+			panic(v)
+		}
+		errs = r.errs
+	}()
+	Snapshot(r, doc, path)
+	return r.errs
+}
+
+func TestSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "openapi.json")
+	newDoc := func() *openapi.OpenAPI {
+		doc := openapi.New("snapshot", "1.0.0", "")
+		doc.GetRoute("/users", "get").AddResponse(openapi.Response{Status: 200, Desc: "ok"})
+		return doc
+	}
+
+	errs := snapshot(newDoc(), path)
+	if len(errs) != 1 || !strings.Contains(errs[0], "does not exist") {
+		t.Errorf("expected missing snapshot error got %v", errs)
+	}
+
+	t.Setenv("UPDATE_SNAPSHOTS", "true")
+	if errs := snapshot(newDoc(), path); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("UPDATE_SNAPSHOTS", "")
+	if errs := snapshot(newDoc(), path); len(errs) > 0 {
+		t.Errorf("expected snapshot to match got %v", errs)
+	}
+
+	changed := newDoc()
+	changed.Info.Version = "1.1.0"
+	changed.GetRoute("/users", "post").AddResponse(openapi.Response{Status: 201, Desc: "created"})
+	errs = snapshot(changed, path)
+	eq, diff := trial.Equal(errs, []string{"document does not match snapshot " + path +
+		", run the test with -update to accept the changes:\n\tchanged /info/version\n\tadded /paths/~1users/post"})
+	if !eq {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,82 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// OpenIDConfiguration is the subset of the OpenID Connect discovery document
+// used to configure security schemes.
+type OpenIDConfiguration struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	ScopesSupported       []string `json:"scopes_supported"`
+	GrantTypesSupported   []string `json:"grant_types_supported"`
+}
+
+// Flows derives the OAuth2 flows supported by the identity provider.
+// The authorization code flow is used when no grant types are listed.
+func (c OpenIDConfiguration) Flows() *OAuthFlows {
+	scopes := make(map[string]string)
+	for _, s := range c.ScopesSupported {
+		scopes[s] = ""
+	}
+	grants := c.GrantTypesSupported
+	if len(grants) == 0 {
+		grants = []string{"authorization_code"}
+	}
+	f := &OAuthFlows{}
+	for _, g := range grants {
+		switch g {
+		case "authorization_code":
+			f.AuthorizationCode = &OAuthFlow{AuthorizationURL: c.AuthorizationEndpoint, TokenURL: c.TokenEndpoint, Scopes: scopes}
+		case "implicit":
+			f.Implicit = &OAuthFlow{AuthorizationURL: c.AuthorizationEndpoint, Scopes: scopes}
+		case "client_credentials":
+			f.ClientCredentials = &OAuthFlow{TokenURL: c.TokenEndpoint, Scopes: scopes}
+		case "password":
+			f.Password = &OAuthFlow{TokenURL: c.TokenEndpoint, Scopes: scopes}
+		}
+	}
+	return f
+}
+
+// AddOpenIDConnectAuthFromDiscovery fetches the .well-known/openid-configuration of the issuer
+// with the client, http.DefaultClient if nil, and adds an openIdConnect security scheme with the discovery url.
+// The issuer of the configuration must be the issuerURL as required by OpenID Connect Discovery.
+// The configuration is returned so OAuth2 flows can be derived from it with Flows.
+func (o *OpenAPI) AddOpenIDConnectAuthFromDiscovery(ctx context.Context, client *http.Client, name, issuerURL string) (*OpenIDConfiguration, error) {
+	discovery := strings.TrimSuffix(issuerURL, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discovery, nil)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("openid discovery %q: %w", discovery, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("openid discovery %q: unexpected status %v", discovery, resp.Status)
+	}
+	c := &OpenIDConfiguration{}
+	if err := json.NewDecoder(resp.Body).Decode(c); err != nil {
+		return nil, fmt.Errorf("openid discovery %q: %w", discovery, err)
+	}
+	if strings.TrimSuffix(c.Issuer, "/") != strings.TrimSuffix(issuerURL, "/") {
+		return nil, fmt.Errorf("openid discovery %q: issuer %q does not match %q", discovery, c.Issuer, issuerURL)
+	}
+
+	o.AddSecurityScheme(name, SecurityScheme{
+		Type:             "openIdConnect",
+		OpenIDConnectURL: discovery,
+	})
+	return c, nil
+}
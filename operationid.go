@@ -0,0 +1,76 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// AutoOperationIDs generates an operationId for the operations without one during Compile,
+// the id is derived from the method and path and made unique with a number suffix.
+//
+//	GET /users/{id}        → getUsersById
+//	POST /user-groups      → postUserGroups
+func (o *OpenAPI) AutoOperationIDs() {
+	o.autoOperationIDs = true
+	o.invalidate()
+}
+
+// generateOperationIDs sets the generated operationId of the routes without one
+func (o *OpenAPI) generateOperationIDs() {
+	used := make(map[string]bool)
+	for _, r := range o.Paths {
+		used[r.OperationID] = true
+	}
+	for _, k := range sortedKeys(o.Paths) {
+		r := o.Paths[k]
+		if r.OperationID != "" {
+			continue
+		}
+		id := operationID(r.method, r.path)
+		for i := 2; used[id]; i++ {
+			id = fmt.Sprintf("%v%d", operationID(r.method, r.path), i)
+		}
+		used[id] = true
+		r.OperationID = id
+		r.compiled = false
+	}
+}
+
+// operationID joins the method and the segments of the path in camel case,
+// path params are prefixed with By.
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, s := range strings.Split(path, "/") {
+		if m := regexPathParam.FindStringSubmatch(s); m != nil {
+			b.WriteString("By")
+			s = m[1]
+		}
+		// words are split on any character that is not a letter or digit
+		for _, w := range strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+			r, size := utf8.DecodeRuneInString(w)
+			b.WriteRune(unicode.ToUpper(r))
+			b.WriteString(w[size:])
+		}
+	}
+	return b.String()
+}
+
+// validateOperationIDs returns an error for every operationId used by more than one operation
+func (o *OpenAPI) validateOperationIDs() []error {
+	ops := make(map[string][]string)
+	for _, k := range sortedKeys(o.Paths) {
+		if r := o.Paths[k]; r.OperationID != "" {
+			ops[r.OperationID] = append(ops[r.OperationID], r.method+" "+r.path)
+		}
+	}
+	var errs []error
+	for _, id := range sortedKeys(ops) {
+		if len(ops[id]) > 1 {
+			errs = append(errs, fmt.Errorf("operationId %q is not unique: %v", id, strings.Join(ops[id], ", ")))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,57 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestOperationID(t *testing.T) {
+	fn := func(key string) (string, error) {
+		method, path, _ := strings.Cut(key, " ")
+		return operationID(method, path), nil
+	}
+	cases := trial.Cases[string, string]{
+		"param":     {Input: "GET /users/{id}", Expected: "getUsersById"},
+		"nested":    {Input: "delete /users/{userId}/orders/{order_id}", Expected: "deleteUsersByUserIdOrdersByOrderId"},
+		"separator": {Input: "POST /user-groups/v2.members", Expected: "postUserGroupsV2Members"},
+		"root":      {Input: "get /", Expected: "get"},
+		"unicode":   {Input: "get /état/{ünit}", Expected: "getÉtatByÜnit"},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
+func TestAutoOperationIDs(t *testing.T) {
+	doc := New("", "", "")
+	doc.AutoOperationIDs()
+	doc.GetRoute("/users/{id}", "get").AddResponse(Response{Status: 200, Desc: "ok"})
+	doc.GetRoute("/user-groups", "get").AddResponse(Response{Status: 200, Desc: "ok"})
+	doc.GetRoute("/user_groups", "get").AddResponse(Response{Status: 200, Desc: "ok"})
+	custom := doc.GetRoute("/users", "post").AddResponse(Response{Status: 201, Desc: "created"})
+	custom.OperationID = "createUser"
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[string]string)
+	for k, r := range doc.Paths {
+		ids[k] = r.OperationID
+	}
+	if eq, diff := trial.Equal(ids, map[string]string{
+		"/users/{id}|get":  "getUsersById",
+		"/user-groups|get": "getUserGroups",
+		"/user_groups|get": "getUserGroups2",
+		"/users|post":      "createUser",
+	}); !eq {
+		t.Error(diff)
+	}
+	if err := doc.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	custom.OperationID = "getUsersById"
+	err := doc.Validate()
+	if err == nil || !strings.Contains(err.Error(), `operationId "getUsersById" is not unique: get /users/{id}, post /users`) {
+		t.Errorf("expected duplicate operationId error, got %v", err)
+	}
+}
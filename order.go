@@ -0,0 +1,220 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// PathOrder is the order of the paths in the json output
+type PathOrder int
+
+const (
+	PathsAlphabetical PathOrder = iota // sorted by path, the default
+	PathsByTag                         // grouped by the first tag of their operations in the order of the document tags
+	PathsInsertion                     // paths and their methods in the order the routes were added with GetRoute or unmarshaled, the webhooks and callbacks as well
+)
+
+// SortTags sorts the tags of the document with less when it is written.
+//
+//	doc.SortTags(func(a, b openapi.Tag) bool { return a.Name < b.Name })
+func (o *OpenAPI) SortTags(less func(a, b Tag) bool) {
+	o.tagLess = less
+}
+
+// OrderPaths sets the order of the paths when the document is written,
+// Swagger UI renders the operations in the order of the document.
+func (o *OpenAPI) OrderPaths(order PathOrder) {
+	o.pathOrder = order
+}
+
+// sortedTags returns a copy of the tags sorted by the tagLess func of the document
+func (o *OpenAPI) sortedTags() []Tag {
+	if o.tagLess == nil {
+		return o.Tags
+	}
+	tags := append([]Tag(nil), o.Tags...)
+	sort.SliceStable(tags, func(i, j int) bool { return o.tagLess(tags[i], tags[j]) })
+	return tags
+}
+
+// orderPaths returns the paths in the PathOrder of the document
+func (o *OpenAPI) orderPaths(paths map[string]map[string]*Route) []string {
+	keys := sortedKeys(paths)
+	switch o.pathOrder {
+	case PathsByTag:
+		rank := make(map[string]int)
+		for i, t := range o.sortedTags() {
+			rank[t.Name] = i + 1
+		}
+		// the first tag of the first tagged operation by method
+		tag := func(path string) string {
+			for _, m := range sortedKeys(paths[path]) {
+				if t := paths[path][m].Tag; len(t) > 0 {
+					return t[0]
+				}
+			}
+			return ""
+		}
+		sort.SliceStable(keys, func(i, j int) bool {
+			ti, tj := tag(keys[i]), tag(keys[j])
+			ri, rj := rank[ti], rank[tj]
+			// tags not in the document follow by name, untagged paths are last
+			switch {
+			case ti == tj:
+				return false
+			case ti == "" || tj == "":
+				return tj == ""
+			case ri == 0 || rj == 0:
+				if ri != rj {
+					return rj == 0
+				}
+				return ti < tj
+			}
+			return ri < rj
+		})
+	case PathsInsertion:
+		pos := make(map[string]int)
+		for _, k := range o.routeOrder {
+			path, _, _ := strings.Cut(k, "|")
+			if _, found := pos[path]; !found && paths[path] != nil {
+				pos[path] = len(pos)
+			}
+		}
+		insertionSort(keys, pos)
+	}
+	return keys
+}
+
+// orderMethods returns the methods of the path in the PathOrder of the document,
+// methods are sorted by name unless the paths are in insertion order.
+func (o *OpenAPI) orderMethods(path string, routes map[string]*Route) []string {
+	keys := sortedKeys(routes)
+	if o.pathOrder != PathsInsertion {
+		return keys
+	}
+	pos := make(map[string]int)
+	for _, k := range o.routeOrder {
+		p, m, _ := strings.Cut(k, "|")
+		if _, found := pos[m]; !found && p == path && routes[m] != nil {
+			pos[m] = len(pos)
+		}
+	}
+	insertionSort(keys, pos)
+	return keys
+}
+
+// insertion reports if the routes of the router are written in the order they were added,
+// the order of the paths of the document of the routes, see PathsInsertion.
+func (r Router) insertion() bool {
+	for _, rt := range r {
+		if rt.doc != nil {
+			return rt.doc.pathOrder == PathsInsertion
+		}
+	}
+	return false
+}
+
+// marshalInsertion writes the routes in the order they were added to the router,
+// the paths by their first added method, such as the webhooks and callbacks of the document.
+func (r Router) marshalInsertion() ([]byte, error) {
+	paths := make(map[string]map[string]*Route)
+	pos := make(map[string]int) // [path]position of the first added method
+	for k, rt := range r {
+		path, method, _ := strings.Cut(k, "|")
+		if paths[path] == nil {
+			paths[path] = make(map[string]*Route)
+		}
+		paths[path][method] = rt
+		if p, found := pos[path]; rt.seq > 0 && (!found || rt.seq < p) {
+			pos[path] = rt.seq
+		}
+	}
+	keys := sortedKeys(paths)
+	insertionSort(keys, pos)
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, path := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(path)
+		buf.Write(k)
+		buf.WriteString(":{")
+		methods := sortedKeys(paths[path])
+		seqs := make(map[string]int)
+		for _, m := range methods {
+			if seq := paths[path][m].seq; seq > 0 {
+				seqs[m] = seq
+			}
+		}
+		insertionSort(methods, seqs)
+		for j, m := range methods {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			b, err := json.Marshal(paths[path][m])
+			if err != nil {
+				return nil, err
+			}
+			k, _ := json.Marshal(m)
+			buf.Write(k)
+			buf.WriteByte(':')
+			buf.Write(b)
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// insertionSort orders the sorted keys by their position,
+// keys without a position such as generated routes follow in alphabetical order.
+func insertionSort(keys []string, pos map[string]int) {
+	sort.SliceStable(keys, func(i, j int) bool {
+		pi, fi := pos[keys[i]]
+		pj, fj := pos[keys[j]]
+		if fi && fj {
+			return pi < pj
+		}
+		return fi && !fj
+	})
+}
+
+// pathKeys returns the keys of the routes in the order of the paths object in the json document b
+func pathKeys(b []byte) ([]string, error) {
+	var doc struct {
+		Paths json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	return routerKeys(doc.Paths)
+}
+
+// routerKeys returns the keys of the routes in the order of the json object b of a Router
+func routerKeys(b []byte) ([]string, error) {
+	if len(b) == 0 || b[0] != '{' {
+		return nil, nil
+	}
+	paths, err := objectFields(b)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, path := range paths {
+		if len(path.value) == 0 || path.value[0] != '{' {
+			continue
+		}
+		methods, err := objectFields(path.value)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range methods {
+			keys = append(keys, path.key+"|"+m.key)
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,172 @@
+package openapi
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestOrderPaths(t *testing.T) {
+	newDoc := func() *OpenAPI {
+		doc := New("", "", "")
+		doc.AddTags(Tag{Name: "users"}, Tag{Name: "orders"})
+		doc.GetRoute("/users", "get").Tags("users")
+		doc.GetRoute("/health", "get")
+		doc.GetRoute("/orders", "get").Tags("orders")
+		doc.GetRoute("/billing", "get").Tags("billing")
+		doc.GetRoute("/accounts", "get").Tags("users")
+		doc.Paths["/added|get"] = &Route{path: "/added", method: "get"}
+		return doc
+	}
+	type output struct {
+		Tags  []string
+		Paths []string
+	}
+	fn := func(order PathOrder) (output, error) {
+		doc := newDoc()
+		doc.OrderPaths(order)
+		if order == PathsByTag {
+			doc.SortTags(func(a, b Tag) bool { return a.Name < b.Name })
+		}
+		var out output
+		for _, tag := range doc.sortedTags() {
+			out.Tags = append(out.Tags, tag.Name)
+		}
+		// the paths are the keys at the second level of the indented output
+		for _, m := range regexp.MustCompile(`(?m)^ {8}"(/[^"]*)": `).FindAllStringSubmatch(doc.JSON(), -1) {
+			out.Paths = append(out.Paths, m[1])
+		}
+		return out, nil
+	}
+	cases := trial.Cases[PathOrder, output]{
+		"alphabetical": {
+			Input: PathsAlphabetical,
+			Expected: output{
+				Tags:  []string{"users", "orders"},
+				Paths: []string{"/accounts", "/added", "/billing", "/health", "/orders", "/users"},
+			},
+		},
+		"by tag": {
+			Input: PathsByTag,
+			Expected: output{
+				Tags:  []string{"orders", "users"},
+				Paths: []string{"/orders", "/accounts", "/users", "/billing", "/added", "/health"},
+			},
+		},
+		"insertion": {
+			Input: PathsInsertion,
+			Expected: output{
+				Tags:  []string{"users", "orders"},
+				Paths: []string{"/users", "/health", "/orders", "/billing", "/accounts", "/added"},
+			},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
+func TestOrderMethods(t *testing.T) {
+	newDoc := func() *OpenAPI {
+		doc := New("", "", "")
+		doc.GetRoute("/users", "post")
+		doc.GetRoute("/health", "get")
+		doc.GetRoute("/users", "get")
+		doc.GetRoute("/users", "delete")
+		return doc
+	}
+	// the path and method keys of the indented output
+	keys := func(doc *OpenAPI) []string {
+		var out []string
+		for _, m := range regexp.MustCompile(`(?m)^ {8}"(/[^"]*)": |^ {12}"([a-z]+)": `).FindAllStringSubmatch(doc.JSON(), -1) {
+			out = append(out, m[1]+m[2])
+		}
+		return out
+	}
+	fn := func(order PathOrder) ([]string, error) {
+		doc := newDoc()
+		doc.OrderPaths(order)
+		return keys(doc), nil
+	}
+	cases := trial.Cases[PathOrder, []string]{
+		"alphabetical": {
+			Input:    PathsAlphabetical,
+			Expected: []string{"/health", "get", "/users", "delete", "get", "post"},
+		},
+		"insertion": {
+			Input:    PathsInsertion,
+			Expected: []string{"/users", "post", "get", "delete", "/health", "get"},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	// the order of the paths and methods is kept when the document is read back
+	doc := newDoc()
+	doc.OrderPaths(PathsInsertion)
+	spec := doc.JSON()
+	doc2, err := NewFromJson(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc2.OrderPaths(PathsInsertion)
+	if ok, diff := trial.Equal(keys(doc2), keys(doc)); !ok {
+		t.Errorf("unmarshaled order: %v", diff)
+	}
+}
+
+func TestOrderWebhooks(t *testing.T) {
+	newDoc := func() *OpenAPI {
+		doc := New("", "", "")
+		doc.GetWebhook("users", "post")
+		doc.GetWebhook("orders", "post")
+		doc.GetWebhook("users", "delete")
+		cb := doc.GetRoute("/orders", "post")
+		cb.Callback("shipped", "{$request.body#/url}", "put")
+		cb.Callback("shipped", "{$request.body#/fallback}", "post")
+		return doc
+	}
+	// the keys of the webhooks and the callbacks as they are written
+	fn := func(order PathOrder) ([]string, error) {
+		doc := newDoc()
+		doc.OrderPaths(order)
+		b, err := json.Marshal(doc.Webhooks)
+		if err != nil {
+			return nil, err
+		}
+		webhooks, err := routerKeys(b)
+		if err != nil {
+			return nil, err
+		}
+		b, err = json.Marshal(doc.GetRoute("/orders", "post").Callbacks["shipped"])
+		if err != nil {
+			return nil, err
+		}
+		callbacks, err := routerKeys(b)
+		return append(webhooks, callbacks...), err
+	}
+	cases := trial.Cases[PathOrder, []string]{
+		"alphabetical": {
+			Input:    PathsAlphabetical,
+			Expected: []string{"orders|post", "users|delete", "users|post", "{$request.body#/fallback}|post", "{$request.body#/url}|put"},
+		},
+		"insertion": {
+			Input:    PathsInsertion,
+			Expected: []string{"users|post", "users|delete", "orders|post", "{$request.body#/url}|put", "{$request.body#/fallback}|post"},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	// the order of the webhooks is kept when the document is read back
+	doc := newDoc()
+	doc.OrderPaths(PathsInsertion)
+	doc2, err := NewFromJson(doc.JSON())
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc2.OrderPaths(PathsInsertion)
+	b, _ := json.Marshal(doc.Webhooks)
+	b2, _ := json.Marshal(doc2.Webhooks)
+	if ok, diff := trial.Equal(string(b2), string(b)); !ok {
+		t.Errorf("unmarshaled order: %v", diff)
+	}
+}
@@ -0,0 +1,66 @@
+package openapi
+
+import (
+	"reflect"
+)
+
+// OverrideSchema uses the schema s for every value of type t instead of reflecting the type,
+// pointers to t are overridden as well. This is useful for third-party types that
+// marshal differently from their fields such as decimal.Decimal or bson.ObjectID.
+// The override only applies to this document. A route builds the schemas of its examples
+// with the settings of its document when they are added, so it must be set before the routes are added.
+//
+//	doc.OverrideSchema(reflect.TypeOf(decimal.Decimal{}), openapi.Schema{Type: openapi.String})
+func (o *OpenAPI) OverrideSchema(t reflect.Type, s Schema) {
+	b := o.schemaBuilder()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.types == nil {
+		b.types = make(map[reflect.Type]Schema)
+	}
+	b.types[t] = s.clone()
+	b.cache.clear()
+}
+
+// OverrideSchemaName is like OverrideSchema but matches the type by its name
+// as returned by reflect.Type.String, for example "decimal.Decimal".
+func (o *OpenAPI) OverrideSchemaName(name string, s Schema) {
+	b := o.schemaBuilder()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.names == nil {
+		b.names = make(map[string]Schema)
+	}
+	b.names[name] = s.clone()
+	b.cache.clear()
+}
+
+// RemoveOverride removes the schema set for the type with OverrideSchema
+// or for its name with OverrideSchemaName, the type is reflected again.
+func (o *OpenAPI) RemoveOverride(t reflect.Type) {
+	b := o.schemaBuilder()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.types, t)
+	delete(b.names, t.String())
+	b.cache.clear()
+}
+
+// override returns a copy of the schema registered for the type or its pointer element.
+func (b *schemaBuilder) override(t reflect.Type) (Schema, bool) {
+	if len(b.types) == 0 && len(b.names) == 0 {
+		return Schema{}, false
+	}
+	for {
+		if s, found := b.types[t]; found {
+			return s.clone(), true
+		}
+		if s, found := b.names[t.String()]; found {
+			return s.clone(), true
+		}
+		if t.Kind() != reflect.Pointer {
+			return Schema{}, false
+		}
+		t = t.Elem()
+	}
+}
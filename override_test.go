@@ -0,0 +1,96 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+type decimal struct {
+	neg   bool
+	value []byte
+}
+
+type objectID [12]byte
+
+func TestOverrideSchema(t *testing.T) {
+	type input struct {
+		example any
+		remove  bool // remove the override of decimal
+	}
+	fn := func(in input) (Schema, error) {
+		doc := New("", "", "")
+		doc.OverrideSchema(reflect.TypeOf(decimal{}), Schema{Type: String})
+		doc.OverrideSchemaName("openapi.objectID", Schema{Type: String, Desc: "hex encoded id", ReadOnly: true})
+		if in.remove {
+			doc.RemoveOverride(reflect.TypeOf(decimal{}))
+		}
+		doc.GetRoute("/prices", "get").AddResponse(Response{Status: 200}.WithExample(in.example))
+		if err := doc.Compile(); err != nil {
+			return Schema{}, err
+		}
+		s := doc.GetRoute("/prices", "get").Responses[200].Content[Json].Schema
+		if name, found := strings.CutPrefix(s.Ref, "#/components/schemas/"); found {
+			return doc.Components.Schemas[name], nil
+		}
+		return s, nil
+	}
+	cases := trial.Cases[input, Schema]{
+		"type": {
+			Input:    input{example: decimal{}},
+			Expected: Schema{Type: String},
+		},
+		"pointer": {
+			Input:    input{example: &decimal{}},
+			Expected: Schema{Type: String},
+		},
+		"name": {
+			Input:    input{example: objectID{}},
+			Expected: Schema{Type: String, Desc: "hex encoded id", ReadOnly: true},
+		},
+		"field": {
+			Input: input{example: struct {
+				ID    objectID
+				Price decimal `desc:"unit price"`
+			}{}},
+			Expected: Schema{
+				Title: "struct { ID openapi.objectID; Price openapi.decimal \"desc:\\\"unit price\\\"\" }",
+				Type:  Object,
+				Properties: Properties{
+					"ID":    {Type: String, Desc: "hex encoded id", ReadOnly: true},
+					"Price": {Type: String, Desc: "unit price"},
+				},
+			},
+		},
+		"tagged field": {
+			Input: input{example: struct {
+				ID objectID `json:"id" openapi:"required" deprecated:"true"`
+			}{}},
+			Expected: Schema{
+				Title: "struct { ID openapi.objectID \"json:\\\"id\\\" openapi:\\\"required\\\" deprecated:\\\"true\\\"\" }",
+				Type:  Object,
+				Properties: Properties{
+					"id": {Type: String, Desc: "hex encoded id", ReadOnly: true, Deprecated: true},
+				},
+				Required: []string{"id"},
+			},
+		},
+		"removed": {
+			Input:    input{example: decimal{}, remove: true},
+			Expected: Schema{Title: "openapi.decimal", Type: Object, Properties: Properties{}},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	// the override does not change the other documents
+	other := New("", "", "")
+	other.GetRoute("/prices", "get").AddResponse(Response{Status: 200}.WithExample(decimal{}))
+	if err := other.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := other.Components.Schemas["openapi.decimal"]; !found {
+		t.Errorf("expected the decimal schema to be reflected got %v", other.Components.Schemas)
+	}
+}
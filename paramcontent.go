@@ -0,0 +1,31 @@
+package openapi
+
+import "fmt"
+
+// ContentParam is a param value serialized with a media type, such as a json object
+// in a query param filter={"status":"active"}. AddParam documents it with the content
+// of the param instead of a schema. See JSONParam.
+type ContentParam struct {
+	MIME  MIMEType
+	Value any
+}
+
+// JSONParam is a param value serialized as json, the example and schema of the value
+// are the application/json content of the param.
+//
+//	r.QueryParam("filter", openapi.JSONParam(filter{Status: "active"}), "the search filter")
+func JSONParam(value any) ContentParam {
+	return ContentParam{MIME: Json, Value: value}
+}
+
+// addContent adds the value as an example of the content of the param,
+// a param is described by either a schema or a content with a single media type
+// so the schema and the examples of the schema are removed.
+func (p *Param) addContent(c ContentParam) {
+	p.Schema, p.Style, p.Explode = nil, "", nil
+	p.Examples = make(map[string]Example)
+	p.Content = p.Content.addExample(c.MIME, "", c.Value)
+	if len(p.Content) > 1 {
+		p.Desc = fmt.Sprintf("err: param content has %d media types", len(p.Content))
+	}
+}
@@ -0,0 +1,43 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestJSONParam(t *testing.T) {
+	type filter struct {
+		Status string `json:"status"`
+	}
+	fn := func(values []any) (string, error) {
+		r := &Route{}
+		for _, v := range values {
+			r.QueryParam("filter", v, "the search filter")
+		}
+		b, err := json.Marshal(r.Params["query|filter"])
+		return string(b), err
+	}
+	cases := trial.Cases[[]any, string]{
+		"json": {
+			Input: []any{JSONParam(filter{Status: "active"})},
+			Expected: `{"name":"filter","description":"the search filter","in":"query","content":{"application/json":` +
+				`{"schema":{"title":"openapi.filter","type":"object","properties":{"status":{"type":"string"}}},` +
+				`"examples":{"openapi.filter":{"value":{"status":"active"}}}}},"examples":{}}`,
+		},
+		"replaces schema": {
+			Input: []any{"active", JSONParam(filter{Status: "active"})},
+			Expected: `{"name":"filter","description":"the search filter","in":"query","content":{"application/json":` +
+				`{"schema":{"title":"openapi.filter","type":"object","properties":{"status":{"type":"string"}}},` +
+				`"examples":{"openapi.filter":{"value":{"status":"active"}}}}},"examples":{}}`,
+		},
+		"media types": {
+			Input: []any{JSONParam(filter{}), ContentParam{MIME: "application/xml", Value: filter{}}},
+			Expected: `{"name":"filter","description":"err: param content has 2 media types","in":"query","content":{` +
+				`"application/json":{"schema":{"title":"openapi.filter","type":"object","properties":{"status":{"type":"string"}}},"examples":{"openapi.filter":{"value":{"status":""}}}},` +
+				`"application/xml":{"schema":{"title":"openapi.filter","type":"object","properties":{"status":{"type":"string"}}},"examples":{"openapi.filter":{"value":{"status":""}}}}},"examples":{}}`,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
@@ -0,0 +1,319 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// patchOp is a single operation of a JSON Patch (RFC 6902)
+type patchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ApplyJSONPatch applies a JSON Patch (RFC 6902) to the document.
+// The patch is a list of add, remove, replace, move, copy and test operations,
+// the document is unchanged if any operation fails.
+//
+//	[{"op": "replace", "path": "/servers/0/url", "value": "https://staging.example.com"},
+//	 {"op": "remove", "path": "/paths/~1internal"}]
+func (o *OpenAPI) ApplyJSONPatch(patch []byte) error {
+	var ops []patchOp
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return fmt.Errorf("invalid json patch: %w", err)
+	}
+	doc, err := o.generic()
+	if err != nil {
+		return err
+	}
+	for i, op := range ops {
+		if doc, err = op.apply(doc); err != nil {
+			return fmt.Errorf("json patch operation %d %v %q: %w", i, op.Op, op.Path, err)
+		}
+	}
+	return o.replace(doc)
+}
+
+// ApplyMergePatch applies a JSON Merge Patch (RFC 7386) to the document.
+// Objects in the patch are merged into the document and null values remove fields.
+//
+//	{"servers": [{"url": "https://staging.example.com"}], "paths": {"/internal": null}}
+func (o *OpenAPI) ApplyMergePatch(patch []byte) error {
+	var p any
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return fmt.Errorf("invalid merge patch: %w", err)
+	}
+	doc, err := o.generic()
+	if err != nil {
+		return err
+	}
+	return o.replace(mergePatch(doc, p))
+}
+
+// generic returns the document as generic json values
+func (o *OpenAPI) generic() (any, error) {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	err = json.Unmarshal(b, &doc)
+	return doc, err
+}
+
+// replace the content of the document with the generic json doc,
+// the settings of the document are kept.
+func (o *OpenAPI) replace(doc any) error {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	n, err := NewFromJson(string(b))
+	if err != nil {
+		return err
+	}
+	o.copySettings(n)
+	*o = *n
+	return nil
+}
+
+// copySettings sets the unexported settings of the document on n
+func (o *OpenAPI) copySettings(n *OpenAPI) {
+	n.exampleLimits = o.exampleLimits
+	n.requireSecurity = o.requireSecurity
+	n.securityAllow = o.securityAllow
+	n.exampleSanitizer = o.exampleSanitizer
+	n.sanitized = o.sanitized
+	n.fieldRedactor = o.fieldRedactor
+	n.headOptions = o.headOptions
+	n.globalParams = o.globalParams
+	n.defaultHeaders = o.defaultHeaders
+	n.autoTag = o.autoTag
+	n.autoOperationIDs = o.autoOperationIDs
+	n.fieldChanges = o.fieldChanges
+	n.tagLess = o.tagLess
+	n.tagDescs = o.tagDescs
+	n.pathOrder = o.pathOrder
+	n.routeOrder = o.routeOrder
+	n.processors = o.processors
+	n.resolved = o.resolved
+	n.resolvedSchemas = o.resolvedSchemas
+	n.schemas = o.schemas
+	n.namingStrategy = o.namingStrategy
+	n.schemaOrigins = o.schemaOrigins
+}
+
+func (op patchOp) apply(doc any) (any, error) {
+	path, err := pointerTokens(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	switch op.Op {
+	case "add", "replace", "test":
+		if len(op.Value) == 0 {
+			return nil, errors.New("missing value")
+		}
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, err
+		}
+	case "move", "copy":
+		from, err := pointerTokens(op.From)
+		if err != nil {
+			return nil, err
+		}
+		if value, err = getPointer(doc, from); err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if strings.HasPrefix(op.Path+"/", op.From+"/") && op.Path != op.From {
+				return nil, errors.New("cannot move a value into itself")
+			}
+			if doc, err = removePointer(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			value = copyJSON(value)
+		}
+	}
+
+	switch op.Op {
+	case "add", "move", "copy":
+		return addPointer(doc, path, value)
+	case "remove":
+		return removePointer(doc, path)
+	case "replace":
+		if _, err := getPointer(doc, path); err != nil {
+			return nil, err
+		}
+		if doc, err = removePointer(doc, path); err != nil {
+			return nil, err
+		}
+		return addPointer(doc, path, value)
+	case "test":
+		v, err := getPointer(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(v, value) {
+			return nil, errors.New("test failed")
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unknown operation %q", op.Op)
+}
+
+// pointerTokens splits a json pointer (RFC 6901) into its unescaped tokens
+func pointerTokens(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if ptr[0] != '/' {
+		return nil, fmt.Errorf("invalid json pointer %q", ptr)
+	}
+	tokens := strings.Split(ptr[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+	return tokens, nil
+}
+
+func getPointer(doc any, tokens []string) (any, error) {
+	v := doc
+	for _, t := range tokens {
+		switch c := v.(type) {
+		case map[string]any:
+			val, found := c[t]
+			if !found {
+				return nil, fmt.Errorf("%q not found", t)
+			}
+			v = val
+		case []any:
+			i, err := arrayIndex(t, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+			v = c[i]
+		default:
+			return nil, fmt.Errorf("%q not found", t)
+		}
+	}
+	return v, nil
+}
+
+func addPointer(doc any, tokens []string, value any) (any, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return updatePointer(doc, tokens, func(parent any, key string) (any, error) {
+		switch c := parent.(type) {
+		case map[string]any:
+			c[key] = value
+			return c, nil
+		case []any:
+			if key == "-" {
+				return append(c, value), nil
+			}
+			i, err := arrayIndex(key, len(c))
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, nil)
+			copy(c[i+1:], c[i:])
+			c[i] = value
+			return c, nil
+		}
+		return nil, fmt.Errorf("cannot add %q to a %T", key, parent)
+	})
+}
+
+func removePointer(doc any, tokens []string) (any, error) {
+	if len(tokens) == 0 {
+		return nil, errors.New("cannot remove the document")
+	}
+	return updatePointer(doc, tokens, func(parent any, key string) (any, error) {
+		switch c := parent.(type) {
+		case map[string]any:
+			if _, found := c[key]; !found {
+				return nil, fmt.Errorf("%q not found", key)
+			}
+			delete(c, key)
+			return c, nil
+		case []any:
+			i, err := arrayIndex(key, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+			return append(c[:i], c[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("%q not found", key)
+	})
+}
+
+// updatePointer calls fn with the parent of the value at tokens and the last token,
+// the parent is replaced with the value returned by fn.
+func updatePointer(doc any, tokens []string, fn func(parent any, key string) (any, error)) (any, error) {
+	if len(tokens) == 1 {
+		return fn(doc, tokens[0])
+	}
+	switch c := doc.(type) {
+	case map[string]any:
+		child, found := c[tokens[0]]
+		if !found {
+			return nil, fmt.Errorf("%q not found", tokens[0])
+		}
+		v, err := updatePointer(child, tokens[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		c[tokens[0]] = v
+		return c, nil
+	case []any:
+		i, err := arrayIndex(tokens[0], len(c)-1)
+		if err != nil {
+			return nil, err
+		}
+		v, err := updatePointer(c[i], tokens[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		c[i] = v
+		return c, nil
+	}
+	return nil, fmt.Errorf("%q not found", tokens[0])
+}
+
+// arrayIndex parses the token as an array index between 0 and max
+func arrayIndex(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid index %q", token)
+	}
+	return i, nil
+}
+
+// mergePatch merges the patch into the doc as described by RFC 7386
+func mergePatch(doc, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	d, ok := doc.(map[string]any)
+	if !ok {
+		d = make(map[string]any)
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(d, k)
+			continue
+		}
+		d[k] = mergePatch(d[k], v)
+	}
+	return d
+}
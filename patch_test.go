@@ -0,0 +1,117 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func patchDoc() *OpenAPI {
+	doc := New("patch", "1.0.0", "")
+	doc.Servers = []Server{{URL: "https://api.example.com"}}
+	doc.GetRoute("/users", "get").AddResponse(Response{Status: 200, Desc: "ok"})
+	doc.GetRoute("/internal", "get").AddResponse(Response{Status: 200, Desc: "ok"})
+	return doc
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	type output struct {
+		Servers []Server
+		Paths   []string
+	}
+	fn := func(patch string) (output, error) {
+		doc := patchDoc()
+		err := doc.ApplyJSONPatch([]byte(patch))
+		return output{Servers: doc.Servers, Paths: sortedKeys(doc.Paths)}, err
+	}
+	cases := trial.Cases[string, output]{
+		"replace": {
+			Input: `[{"op": "replace", "path": "/servers/0/url", "value": "https://staging.example.com"}]`,
+			Expected: output{
+				Servers: []Server{{URL: "https://staging.example.com"}},
+				Paths:   []string{"/internal|get", "/users|get"},
+			},
+		},
+		"remove": {
+			Input: `[{"op": "remove", "path": "/paths/~1internal"}]`,
+			Expected: output{
+				Servers: []Server{{URL: "https://api.example.com"}},
+				Paths:   []string{"/users|get"},
+			},
+		},
+		"add and copy": {
+			Input: `[{"op": "add", "path": "/servers/-", "value": {"url": "https://eu.example.com", "description": "eu"}},
+				{"op": "copy", "from": "/paths/~1users", "path": "/paths/~1v2~1users"}]`,
+			Expected: output{
+				Servers: []Server{{URL: "https://api.example.com"}, {URL: "https://eu.example.com", Desc: "eu"}},
+				Paths:   []string{"/internal|get", "/users|get", "/v2/users|get"},
+			},
+		},
+		"move": {
+			Input: `[{"op": "move", "from": "/paths/~1internal", "path": "/paths/~1admin"}]`,
+			Expected: output{
+				Servers: []Server{{URL: "https://api.example.com"}},
+				Paths:   []string{"/admin|get", "/users|get"},
+			},
+		},
+		"test": {
+			Input: `[{"op": "test", "path": "/info/title", "value": "patch"}]`,
+			Expected: output{
+				Servers: []Server{{URL: "https://api.example.com"}},
+				Paths:   []string{"/internal|get", "/users|get"},
+			},
+		},
+		"failed test": {
+			Input:     `[{"op": "remove", "path": "/paths/~1internal"}, {"op": "test", "path": "/info/title", "value": "other"}]`,
+			ShouldErr: true,
+		},
+		"missing path": {
+			Input:     `[{"op": "replace", "path": "/servers/3/url", "value": "x"}]`,
+			ShouldErr: true,
+		},
+		"unknown op": {
+			Input:     `[{"op": "rename", "path": "/info"}]`,
+			ShouldErr: true,
+		},
+		"invalid": {
+			Input:     `{"op": "add"}`,
+			ShouldErr: true,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	// the document is unchanged when the patch fails
+	doc := patchDoc()
+	doc.ApplyJSONPatch([]byte(`[{"op": "remove", "path": "/servers"}, {"op": "remove", "path": "/missing"}]`))
+	if len(doc.Servers) != 1 {
+		t.Error("expected document to be unchanged")
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	doc := patchDoc()
+	doc.LimitExamples(ExampleLimits{MaxItems: 1})
+	err := doc.ApplyMergePatch([]byte(`{
+		"servers": [{"url": "https://staging.example.com"}],
+		"info": {"description": "staging"},
+		"paths": {"/internal": null}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(doc.Servers, []Server{{URL: "https://staging.example.com"}}); !eq {
+		t.Error(diff)
+	}
+	if eq, diff := trial.Equal(doc.Info, Info{Title: "patch", Version: "1.0.0", Desc: "staging"}); !eq {
+		t.Error(diff)
+	}
+	if eq, diff := trial.Equal(sortedKeys(doc.Paths), []string{"/users|get"}); !eq {
+		t.Error(diff)
+	}
+	if doc.exampleLimits == nil {
+		t.Error("expected document settings to be kept")
+	}
+	if err := doc.ApplyMergePatch([]byte(`{"info": `)); err == nil {
+		t.Error("expected error for invalid patch")
+	}
+}
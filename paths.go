@@ -3,6 +3,7 @@ package openapi
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"reflect"
 	"regexp"
 	"sort"
@@ -18,21 +19,34 @@ type Route struct {
 	// internal reference
 	path   string
 	method string
-
-	Tag       []string          `json:"tags,omitempty"`
-	Summary   string            `json:"summary,omitempty"`
-	Responses map[Code]Response `json:"responses,omitempty"`   // [status_code]Response
-	Params    Params            `json:"parameters,omitempty"`  // key reference for params. key is name of Param
-	Requests  *RequestBody      `json:"requestBody,omitempty"` // key reference for requests
-
-	/* NOT CURRENTLY SUPPORT VALUES
-	// operationId is an optional unique string used to identify an operation
-	OperationID string  json:"operationId,omitempty"`
-	//A detailed description of the operation. Use markdown for rich text representation
-	Desc         string        `json:"description,omitempty"`
-
-	ExternalDocs *ExternalDocs `json:"externalDocs,omitempty"`
-	*/
+	// compiled is reset whenever the route changes so Compile can skip unchanged routes,
+	// hash is the checksum of the json of the route when it was compiled, see pending
+	compiled bool
+	hash     uint64
+	// generated routes are derived from a GET route by GenerateHeadOptions
+	generated       bool
+	skipHeadOptions bool
+	// noGlobals are the global params skipped by the route, all when noGlobals[""] is set
+	noGlobals map[string]bool
+	// doc is the document of the route, its settings build the schemas added to the route
+	doc *OpenAPI
+	// seq is the position of the route in the order it was added to its Router starting at 1, see PathsInsertion
+	seq int
+
+	Tag          []string              `json:"tags,omitempty"`
+	OperationID  string                `json:"operationId,omitempty"` // unique id of the operation used by client generators, see AutoOperationIDs
+	Summary      string                `json:"summary,omitempty"`
+	Desc         string                `json:"description,omitempty"`  // A detailed description of the operation. CommonMark syntax MAY be used for rich text representation.
+	Responses    map[Code]Response     `json:"responses,omitempty"`    // [status_code]Response
+	Params       Params                `json:"parameters,omitempty"`   // key reference for params. key is name of Param
+	Requests     *RequestBody          `json:"requestBody,omitempty"`  // key reference for requests
+	Security     []SecurityRequirement `json:"security,omitempty"`     // security mechanisms that can be used for this operation, overrides the document security
+	Callbacks    map[string]Router     `json:"callbacks,omitempty"`    // requests the API sends to the consumer by name, key of the Router= expression|method
+	Deprecated   bool                  `json:"deprecated,omitempty"`   // the operation is retired and should not be used, see Deprecate
+	ExternalDocs *ExternalDocs         `json:"externalDocs,omitempty"` // additional documentation of the operation such as a runbook
+
+	Extensions   Extensions `json:"-"` // Specification Extensions, fields starting with x-
+	Translations Localized  `json:"-"` // summaries by language, see Localize
 }
 
 func (r *Route) key() string {
@@ -40,6 +54,9 @@ func (r *Route) key() string {
 }
 
 func (r Router) MarshalJSON() ([]byte, error) {
+	if r.insertion() {
+		return r.marshalInsertion()
+	}
 	data := make(map[string]map[string]*Route)
 	for k, v := range r {
 		s := strings.Split(k, "|")
@@ -54,21 +71,30 @@ func (r Router) MarshalJSON() ([]byte, error) {
 	return json.Marshal(data)
 }
 
-func (r Router) UnmarshalJSON(b []byte) error {
+func (r *Router) UnmarshalJSON(b []byte) error {
 	data := make(map[string]map[string]*Route)
 	if err := json.Unmarshal(b, &data); err != nil {
 		return err
 	}
+	if *r == nil {
+		*r = make(Router)
+	}
 	for k1, v := range data {
 		for k2, rt := range v {
 
 			key := k1 + "|" + k2
 			rt.path = k1
 			rt.method = k2
-			r[key] = rt
+			(*r)[key] = rt
 		}
 	}
-	return nil
+	keys, err := routerKeys(b)
+	for i, k := range keys {
+		if rt := (*r)[k]; rt != nil {
+			rt.seq = i + 1
+		}
+	}
+	return err
 }
 
 // parsePath goes through a url path and pulls out all params
@@ -85,10 +111,33 @@ func parsePath(path string) []string {
 }
 
 func (r *Route) Tags(tag ...string) *Route {
+	r.compiled = false
 	r.Tag = tag
 	return r
 }
 
+// Deprecate marks the operation as deprecated, UIs such as Swagger UI strike it through.
+func (r *Route) Deprecate() *Route {
+	r.compiled = false
+	r.Deprecated = true
+	return r
+}
+
+// WithDesc sets the description of the operation, CommonMark syntax may be used for rich text
+// while the Summary stays a short one line text.
+func (r *Route) WithDesc(desc string) *Route {
+	r.compiled = false
+	r.Desc = desc
+	return r
+}
+
+// WithExternalDocs links the operation to additional documentation such as a runbook or design doc.
+func (r *Route) WithExternalDocs(url, desc string) *Route {
+	r.compiled = false
+	r.ExternalDocs = &ExternalDocs{URL: url, Desc: desc}
+	return r
+}
+
 // CleanPath will convert of go path like :var into
 // an approved openID path {var}
 func CleanPath(path string) string {
@@ -121,6 +170,8 @@ func (o *OpenAPI) GetRoute(path, method string) *Route {
 			path:   path,
 			method: method,
 			Params: make(Params),
+			doc:    o,
+			seq:    len(o.Paths) + 1,
 		}
 
 		// Add any path params
@@ -132,18 +183,45 @@ func (o *OpenAPI) GetRoute(path, method string) *Route {
 			}
 		}
 		o.Paths[key] = r
+		o.routeOrder = append(o.routeOrder, key)
 	}
 	return r
 }
 
+// setDoc sets the document of the routes and their callbacks, such as the routes of a loaded or merged document
+func (o *OpenAPI) setDoc(router Router) {
+	for _, r := range router {
+		r.doc = o
+		for _, cbs := range r.Callbacks {
+			o.setDoc(cbs)
+		}
+	}
+}
+
 func (r *Route) AddResponse(resp Response) *Route {
 	if r.Responses == nil {
 		r.Responses = make(map[Code]Response)
 	}
+	r.compiled = false
+	resp.Content = r.buildContent(resp.Content)
+	resp.Headers = r.buildHeaders(resp.Headers)
 	r.Responses[resp.Status] = resp
 	return r
 }
 
+// DefaultResponse adds the response for any status code not documented on the route,
+// a description is set when resp has none.
+//
+//	r.DefaultResponse(Response{Desc: "unexpected error"})
+//	r.DefaultResponse(Response{}.WithExample(Error{}))
+func (r *Route) DefaultResponse(resp Response) *Route {
+	resp.Status = DefaultStatus
+	if resp.Desc == "" && resp.Ref == "" {
+		resp.Desc = "default response"
+	}
+	return r.AddResponse(resp)
+}
+
 // Responses for the expected responses of an operation, maps a HTTP response code to the expected response.
 type Responses map[Code]Response
 
@@ -152,8 +230,27 @@ type Response struct {
 	Status Code `json:"-"`
 	//MimeType MIMEType `json:"-"`
 
-	Desc    string  `json:"description"`       // Required A short description of the response. CommonMark syntax MAY be used for rich text representation.
-	Content Content `json:"content,omitempty"` // A map containing descriptions of potential response payloads. The key is a media type or media type range and the value describes it.
+	Desc    string            `json:"description"`       // Required A short description of the response. CommonMark syntax MAY be used for rich text representation.
+	Headers map[string]Header `json:"headers,omitempty"` // Maps a header name to its definition. RFC7230 states header names are case insensitive.
+	Content Content           `json:"content,omitempty"` // A map containing descriptions of potential response payloads. The key is a media type or media type range and the value describes it.
+	Links   map[string]Link   `json:"links,omitempty"`   // operations that can follow the response, the key is a short name of the link
+
+	Ref string `json:"$ref,omitempty"` // link to a response in the components, #/components/responses/{name}
+
+	Extensions Extensions `json:"-"` // Specification Extensions, fields starting with x-
+}
+
+// MarshalJSON writes only the $ref of a referenced Response, otherwise the response with its extensions
+func (r Response) MarshalJSON() ([]byte, error) {
+	if r.Ref != "" {
+		return json.Marshal(reference{Ref: r.Ref})
+	}
+	type response Response
+	b, err := json.Marshal(response(r))
+	if err != nil {
+		return nil, err
+	}
+	return marshalExtensions(b, r.Extensions)
 }
 
 // WithJSONString takes a json string object and adds a json Content to the Response
@@ -184,38 +281,155 @@ func (r Response) WithExample(i any) Response {
 }
 
 func (r Response) WithNamedExample(name string, i any) Response {
-	if r.Content == nil {
-		r.Content = make(Content)
-	}
-	m := r.Content[Json]
-	m.AddExample(name, i)
-	r.Content[Json] = m
+	r.Content = r.Content.addExample(Json, name, i)
 	return r
 }
 
+// WithSchemaRef sets the schema of the json Content of the Response to the registered schema.
+func (r Response) WithSchemaRef(ref SchemaRef) Response {
+	r.Content = r.Content.withSchemaRef(Json, ref)
+	return r
+}
+
+// WithSchemaFor sets the schema of the json Content of the Response to the schema of i without
+// adding i as an example, for payloads that can't be published such as personal data.
+//
+//	Response{Status: 200}.WithSchemaFor(Patient{})
+func (r Response) WithSchemaFor(i any) Response {
+	r.Content = r.Content.withSchemaFor(Json, i)
+	return r
+}
+
+// WithNamedExamples adds all examples to the json Content of the Response at once.
+// The key of the map is used as the name of the example.
+func (r Response) WithNamedExamples(examples map[string]any) Response {
+	r.Content = r.Content.addExamples(Json, examples)
+	return r
+}
+
+// withSchemaRef sets the schema of the mime type to reference a component
+func (c Content) withSchemaRef(mime MIMEType, ref SchemaRef) Content {
+	return c.withSchema(mime, Schema{Ref: string(ref)})
+}
+
+// withSchemaFor sets the schema of the mime type to the schema of i
+func (c Content) withSchemaFor(mime MIMEType, i any) Content {
+	c = c.withSchema(mime, buildSchema(i))
+	m := c[mime]
+	m.source = i
+	c[mime] = m
+	return c
+}
+
+// withSchema sets the schema of the mime type
+func (c Content) withSchema(mime MIMEType, s Schema) Content {
+	if c == nil {
+		c = make(Content)
+	}
+	m := c[mime]
+	m.Schema, m.source = s, nil
+	c[mime] = m
+	return c
+}
+
+// addExample adds the example to the Media of the mime type, the examples of the
+// Media are a map so the example is added in place without copying the others.
+func (c Content) addExample(mime MIMEType, name string, v any) Content {
+	if c == nil {
+		c = make(Content)
+	}
+	m := c[mime]
+	m.AddExample(name, v)
+	c[mime] = m
+	return c
+}
+
+// addExamples adds the examples to the Media of the mime type in the order of their names
+// with a single lookup and update of the Content
+func (c Content) addExamples(mime MIMEType, examples map[string]any) Content {
+	if c == nil {
+		c = make(Content)
+	}
+	m := c[mime]
+	for _, name := range sortedKeys(examples) {
+		m.AddExample(name, examples[name])
+	}
+	c[mime] = m
+	return c
+}
+
+// exampleDesc returns the doc of the type of the value without building its schema,
+// it is the description of the schema built for the value.
+func exampleDesc(i any) string {
+	t := reflect.TypeOf(i)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return ""
+	}
+	return typeDoc(t)
+}
+
 // AddExample will add an example object by
 // creating a schema based on the object i passed in.
 // The Example name will be the title of the Schema if not provided
 // and any description from added to the example as well.
+// The schema is only built when it is needed for the Media or the name of the example.
+// Each value of a OneOf or AnyOf Composition is added as an example, the values of AllOf are merged.
 func (m *Media) AddExample(exName string, i any) {
-	if m.Examples == nil {
-		m.Examples = make(map[string]Example)
+	var schema Schema
+	isSet := m.Schema.Title != "" || m.Schema.Ref != "" || m.Schema.composed()
+	if c, ok := i.(Composition); ok {
+		if !isSet {
+			m.Schema, m.source = buildSchema(c), c
+		}
+		if c.kind != "allOf" {
+			for _, v := range c.Values {
+				m.AddExample(exName, v)
+			}
+			return
+		}
+		merged, titles := c.merged()
+		if exName == "" {
+			exName = strings.Join(titles, "_")
+		}
+		m.addExample(exName, Example{Value: merged})
+		return
 	}
-	schema := buildSchema(i)
-	if m.Schema.Title == "" {
-		m.Schema = schema
+	if !isSet || exName == "" {
+		schema = buildSchema(i)
+	} else {
+		// the schema is not needed, the description of the example comes from the type
+		schema.Desc = exampleDesc(i)
+	}
+	if !isSet {
+		m.Schema, m.source = schema, i
 	}
 	if exName == "" {
 		exName = schema.Title
 	}
-	ex := Example{
+	m.addExample(exName, Example{
 		Desc:  schema.Desc,
 		Value: i,
-	}
+	})
+}
 
+// addExample adds the example with a unique name based on exName
+func (m *Media) addExample(exName string, ex Example) {
+	if m.Examples == nil {
+		m.Examples = make(map[string]Example)
+	}
 	// create unique name if key already exists
 	if _, found := m.Examples[exName]; found {
-		exName = exName + strconv.Itoa(len(m.Examples))
+		name := exName + strconv.Itoa(len(m.Examples))
+		for n := len(m.Examples) + 1; ; n++ {
+			if _, found := m.Examples[name]; !found {
+				break
+			}
+			name = exName + strconv.Itoa(n)
+		}
+		exName = name
 	}
 
 	m.Examples[exName] = ex
@@ -226,6 +440,17 @@ type RequestBody struct {
 	Desc     string  `json:"description,omitempty"` // A brief description of the request body. This could contain examples of use. CommonMark syntax MAY be used for rich text representation.
 	Content  Content `json:"content,omitempty"`     // REQUIRED. The content of the request body. The key is a media type or media type range and the value describes it. For requests that match multiple keys, only the most specific key is applicable. e.g. text/plain overrides text/*
 	Required bool    `json:"required,omitempty"`    // Determines if the request body is required in the request. Defaults to false.
+
+	Ref string `json:"$ref,omitempty"` // link to a request body in the components, #/components/requestBodies/{name}
+}
+
+// MarshalJSON writes only the $ref of a referenced RequestBody
+func (r RequestBody) MarshalJSON() ([]byte, error) {
+	if r.Ref != "" {
+		return json.Marshal(reference{Ref: r.Ref})
+	}
+	type requestBody RequestBody
+	return json.Marshal(requestBody(r))
 }
 
 func (r RequestBody) WithJSONString(s string) RequestBody {
@@ -251,20 +476,100 @@ func (r RequestBody) WithExample(i any) RequestBody {
 }
 
 func (r RequestBody) WithNamedExample(name string, i any) RequestBody {
-	if r.Content == nil {
-		r.Content = make(Content)
-	}
-	m := r.Content[Json]
-	m.AddExample(name, i)
-	r.Content[Json] = m
+	r.Content = r.Content.addExample(Json, name, i)
 	return r
 }
 
+// WithSchemaRef sets the schema of the json Content of the RequestBody to the registered schema.
+func (r RequestBody) WithSchemaRef(ref SchemaRef) RequestBody {
+	r.Content = r.Content.withSchemaRef(Json, ref)
+	return r
+}
+
+// WithSchemaFor sets the schema of the json Content of the RequestBody to the schema of i
+// without adding i as an example, see Response.WithSchemaFor.
+func (r RequestBody) WithSchemaFor(i any) RequestBody {
+	r.Content = r.Content.withSchemaFor(Json, i)
+	return r
+}
+
+// WithNamedExamples adds all examples to the json Content of the RequestBody at once.
+// The key of the map is used as the name of the example.
+func (r RequestBody) WithNamedExamples(examples map[string]any) RequestBody {
+	r.Content = r.Content.addExamples(Json, examples)
+	return r
+}
+
+// AddRequest adds the request body to the route. The content of a route that already has a request body
+// is merged, so each call such as a gherkin scenario adds its examples. The schema of a media type
+// is kept and examples with the same name and value are added once. A request body with a $ref replaces it,
+// a promoted request body with its content is merged inline again.
 func (r *Route) AddRequest(req RequestBody) *Route {
-	r.Requests = &req
+	r.compiled = false
+	req.Content = r.buildContent(req.Content)
+	// a reference without content can't be merged
+	if r.Requests == nil || (r.Requests.Ref != "" && r.Requests.Content == nil) || req.Ref != "" {
+		r.Requests = &req
+		return r
+	}
+	merged := *r.Requests
+	// the request no longer matches the referenced component
+	merged.Ref = ""
+	if req.Desc != "" {
+		merged.Desc = req.Desc
+	}
+	merged.Required = merged.Required || req.Required
+	merged.Content = merged.Content.merge(req.Content)
+	r.Requests = &merged
 	return r
 }
 
+// merge returns a copy of the content with the media types and examples of other added
+func (c Content) merge(other Content) Content {
+	merged := make(Content, len(c)+len(other))
+	for k, m := range c {
+		merged[k] = m
+	}
+	for _, k := range sortedKeys(other) {
+		o := other[k]
+		m, found := merged[k]
+		if !found {
+			merged[k] = o
+			continue
+		}
+		if m.Schema.Type == "" && m.Schema.Ref == "" && !m.Schema.composed() {
+			m.Schema, m.source = o.Schema, o.source
+		}
+		examples := make(map[string]Example, len(m.Examples)+len(o.Examples))
+		for name, ex := range m.Examples {
+			examples[name] = ex
+		}
+		m.Examples = examples
+		for _, name := range sortedKeys(o.Examples) {
+			ex := o.Examples[name]
+			if existing, found := m.Examples[name]; found && sameJSON(existing, ex) {
+				continue
+			}
+			m.addExample(name, ex)
+		}
+		if len(m.Examples) == 0 {
+			m.Examples = nil
+		}
+		for name, enc := range o.Encoding {
+			if _, found := m.Encoding[name]; !found {
+				encoding := make(map[string]Encoding, len(m.Encoding)+1)
+				for k, v := range m.Encoding {
+					encoding[k] = v
+				}
+				encoding[name] = enc
+				m.Encoding = encoding
+			}
+		}
+		merged[k] = m
+	}
+	return merged
+}
+
 type ParamSetter func() Param
 
 type Params map[string]Param
@@ -280,14 +585,34 @@ type Param struct {
 
 	In string `json:"in"` // REQUIRED. Param Type: "query", "header", "path" or "cookie".
 
-	Schema   *Schema            `json:"schema,omitempty"` // The schema defining the param
-	Examples map[string]Example `json:"examples"`         // Examples of the parameter’s potential value.
+	Style   Style `json:"style,omitempty"`   // Describes how the parameter value will be serialized depending on the type of the parameter value. Default values (based on value of in): for query - form; for path - simple; for header - simple; for cookie - form.
+	Explode *bool `json:"explode,omitempty"` // When this is true, parameter values of type array or object generate separate parameters for each value of the array or key-value pair of the map.
+
+	Schema   *Schema            `json:"schema,omitempty"`  // The schema defining the param
+	Content  Content            `json:"content,omitempty"` // The media type of a serialized param instead of the schema, see JSONParam
+	Examples map[string]Example `json:"examples"`          // Examples of the parameter’s potential value.
+
+	Ref string `json:"$ref,omitempty"` // link to a parameter in the components, #/components/parameters/{name}
+
+	Extensions Extensions `json:"-"` // Specification Extensions, fields starting with x-
 
 	// NOT CURRENTLY SUPPORTED
-	//Style    string             `json:"style,omitempty"`       // Describes how the parameter value will be serialized depending on the type of the parameter value. Default values (based on value of in): for query - form; for path - simple; for header - simple; for cookie - form.
 	//Required bool               `json:"required"`              // Determines whether this parameter is mandatory. If the parameter location is "path", this property is REQUIRED and its value MUST be true. Otherwise, the property MAY be included and its default value is false
 }
 
+// MarshalJSON writes only the $ref of a referenced Param, otherwise the param with its extensions
+func (p Param) MarshalJSON() ([]byte, error) {
+	if p.Ref != "" {
+		return json.Marshal(reference{Ref: p.Ref})
+	}
+	type param Param
+	b, err := json.Marshal(param(p))
+	if err != nil {
+		return nil, err
+	}
+	return marshalExtensions(b, p.Extensions)
+}
+
 // PathParams add multiple path params to the provided route.
 // the value may be a map[string]any with any primitive type or a slice of a single type.
 // or a struct where the fields represent the values of the param.
@@ -319,12 +644,13 @@ func (r *Route) HeaderParams(value any) *Route {
 // addParams add a given paramType (path, query, header, cookie) to the provided route.
 // the value may be a map[string]any with any primitive type or a slice of a single type.
 // or a struct where the fields represent the values of the param.
+// A struct or map field of a query is a deepObject param with the nested schema of the field.
 func (r *Route) addParams(pType string, value any) *Route {
 	val := reflect.ValueOf(value)
 	switch val.Kind() {
 	case reflect.Struct:
 		typ := val.Type()
-		// iterate through each field and add an example for each field. single depth only
+		// iterate through each field and add an example for each field, see AddParam for nested fields
 		for i := 0; i < val.NumField(); i++ {
 			field := typ.Field(i)
 			fVal := val.Field(i)
@@ -381,6 +707,7 @@ func (r *Route) HeaderParam(name string, value any, desc string) *Route {
 // every element in value if it's a slice is added as an example.
 func (r *Route) AddParam(pType, name string, value any, desc string) *Route {
 	key := pType + "|" + name
+	r.compiled = false
 	var p Param
 	if r.Params == nil {
 		r.Params = make(Params)
@@ -394,6 +721,15 @@ func (r *Route) AddParam(pType, name string, value any, desc string) *Route {
 		}
 	}
 	p, found := r.Params[key]
+	if found && p.Ref != "" {
+		// the param no longer matches the referenced component
+		p.Ref = ""
+		examples := make(map[string]Example, len(p.Examples))
+		for k, v := range p.Examples {
+			examples[k] = v
+		}
+		p.Examples = examples
+	}
 	if !found {
 		p = Param{
 			In: pType, Name: name,
@@ -431,10 +767,15 @@ typeswitch:
 		}
 
 		if p.Schema == nil {
-			s := buildSchema(elemVal)
+			s := r.buildSchema(elemVal)
 			p.Schema = &s
 		}
 	case reflect.Struct:
+		if c, ok := value.(ContentParam); ok {
+			p.addContent(c)
+			p.Content = r.buildContent(p.Content)
+			break typeswitch
+		}
 		if ex, ok := value.(Example); ok {
 			exName := ex.Summary
 			ex.Summary = ""
@@ -446,10 +787,18 @@ typeswitch:
 		}
 		fallthrough
 	case reflect.Map:
-		p.Desc = "err: invalid type map|struct"
+		if pType != "query" {
+			p.Desc = "err: invalid type map|struct"
+			break
+		}
+		if p.Schema == nil {
+			s := r.buildSchema(value)
+			p.Schema = &s
+		}
+		p.deepObject(value)
 	case reflect.Pointer:
 		rVal := reflect.ValueOf(value).Elem()
-		if rVal.Kind() == reflect.Map || rVal.Kind() == reflect.Struct {
+		if (rVal.Kind() == reflect.Map || rVal.Kind() == reflect.Struct) && pType != "query" {
 			p.Desc = "err: invalid type map|struct"
 			break
 		}
@@ -458,7 +807,7 @@ typeswitch:
 	default:
 		exName := fmt.Sprintf("%v", value)
 		if p.Schema == nil {
-			s := buildSchema(value)
+			s := r.buildSchema(value)
 			p.Schema = &s
 		}
 		if !reflect.ValueOf(value).IsZero() {
@@ -471,6 +820,23 @@ typeswitch:
 	return r
 }
 
+// deepObject sets the object value as an example of a query param serialized
+// with the deepObject style, filter[status]=active&filter[limit]=10.
+// The example is named by its query string.
+func (p *Param) deepObject(value any) {
+	explode := true
+	p.Style, p.Explode = StyleDeepObject, &explode
+	values := make(url.Values)
+	if generic, _ := genericJSON(value); generic != nil {
+		formValues(values, p.Name, generic)
+	}
+	name, err := url.QueryUnescape(values.Encode())
+	if err != nil || name == "" {
+		name = p.Name
+	}
+	p.Examples[name] = Example{Value: value}
+}
+
 func isPrimitive(v any) bool {
 	kind := reflect.ValueOf(v).Kind()
 	if kind == reflect.Pointer {
@@ -496,10 +862,13 @@ func (p Params) List() []Param {
 		i++
 	}
 	sort.Slice(l, func(i, j int) bool {
-		if l[i].In == l[j].In {
+		if l[i].In != l[j].In {
+			return l[i].In < l[j].In
+		}
+		if l[i].Name != l[j].Name {
 			return l[i].Name < l[j].Name
 		}
-		return l[i].In < l[j].In
+		return l[i].Ref < l[j].Ref
 	})
 	return l
 }
@@ -515,5 +884,13 @@ func (p *Params) UnmarshalJSON(b []byte) error {
 	if err != nil {
 		return err
 	}
+	*p = make(Params, len(l))
+	for _, v := range l {
+		key := v.In + "|" + v.Name
+		if v.Ref != "" && v.Name == "" {
+			key = v.Ref
+		}
+		(*p)[key] = v
+	}
 	return nil
 }
@@ -1,11 +1,22 @@
 package openapi
 
 import (
-	"github.com/hydronica/trial"
+	"encoding/json"
+	"strings"
 	"testing"
+
+	"github.com/hydronica/trial"
 )
 
 func TestAddParams(t *testing.T) {
+	type priceRange struct {
+		Min int `json:"min"`
+		Max int `json:"max"`
+	}
+	type rangeFilter struct {
+		Status string     `json:"status"`
+		Price  priceRange `json:"price"`
+	}
 	type input struct {
 		value any
 		path  string
@@ -124,6 +135,41 @@ func TestAddParams(t *testing.T) {
 				},
 			},
 		},
+		"nested struct": {
+			Input: input{
+				pType: "query",
+				value: struct {
+					Filter rangeFilter `json:"filter" desc:"the price range"`
+					Page   int         `json:"page"`
+				}{Filter: rangeFilter{Status: "active", Price: priceRange{Min: 1, Max: 5}}, Page: 2},
+			},
+			Expected: []Param{
+				{
+					In: "query", Name: "filter", Desc: "the price range",
+					Style: StyleDeepObject, Explode: trial.BoolP(true),
+					Schema: &Schema{
+						Title: "openapi.rangeFilter",
+						Type:  Object,
+						Properties: map[string]Schema{
+							"status": {Type: String},
+							"price": {
+								Title:      "openapi.priceRange",
+								Type:       Object,
+								Properties: map[string]Schema{"min": {Type: Integer}, "max": {Type: Integer}},
+								Order:      []string{"min", "max"},
+							},
+						},
+						Order: []string{"status", "price"},
+					},
+					Examples: map[string]Example{
+						"filter[price][max]=5&filter[price][min]=1&filter[status]=active": {
+							Value: rangeFilter{Status: "active", Price: priceRange{Min: 1, Max: 5}},
+						},
+					},
+				},
+				{In: "query", Name: "page", Schema: &Schema{Type: Integer}, Examples: map[string]Example{"2": {Value: 2}}},
+			},
+		},
 		"struct_w_desc": {
 			Input: input{
 				pType: "query",
@@ -148,6 +194,12 @@ func TestAddParam(t *testing.T) {
 		desc  string
 		value any
 	}
+	type filter struct {
+		Status string `json:"status"`
+		Limit  int    `json:"limit"`
+	}
+	filterSchema := buildSchema(filter{})
+	sortSchema := buildSchema(map[string]string{"name": "asc"})
 	fn := func(in input) ([]Param, error) {
 		r := &Route{}
 		r.AddParam(in.pType, in.name, in.value, in.desc)
@@ -196,6 +248,46 @@ func TestAddParam(t *testing.T) {
 					}},
 			},
 		},
+		"deepObject struct": {
+			Input: input{pType: "query", name: "filter", value: &filter{Status: "active", Limit: 10}},
+			Expected: []Param{
+				{
+					Name: "filter", In: "query", Style: "deepObject", Explode: trial.BoolP(true),
+					Schema:   &filterSchema,
+					Examples: map[string]Example{"filter[limit]=10&filter[status]=active": {Value: filter{Status: "active", Limit: 10}}},
+				},
+			},
+		},
+		"deepObject map": {
+			Input: input{pType: "query", name: "sort", value: map[string]string{"name": "asc"}},
+			Expected: []Param{
+				{
+					Name: "sort", In: "query", Style: "deepObject", Explode: trial.BoolP(true),
+					Schema:   &sortSchema,
+					Examples: map[string]Example{"sort[name]=asc": {Value: map[string]string{"name": "asc"}}},
+				},
+			},
+		},
+		"json content": {
+			Input: input{pType: "query", name: "filter", value: JSONParam(filter{Status: "active", Limit: 10})},
+			Expected: []Param{
+				{
+					Name: "filter", In: "query",
+					Content: Content{Json: {
+						Schema:   filterSchema,
+						Examples: map[string]Example{"openapi.filter": {Value: filter{Status: "active", Limit: 10}}},
+						source:   filter{Status: "active", Limit: 10},
+					}},
+					Examples: map[string]Example{},
+				},
+			},
+		},
+		"header struct": {
+			Input: input{pType: "header", name: "filter", value: filter{}},
+			Expected: []Param{
+				{Name: "filter", In: "header", Desc: "err: invalid type map|struct"},
+			},
+		},
 	}
 	trial.New(fn, cases).SubTest(t)
 }
@@ -253,7 +345,8 @@ func TestAddResponse(t *testing.T) {
 	}.WithJSONString(`{"status":"ok"}`))
 	route.AddResponse(Response{Status: 400}.WithExample(struct{ Error string }{Error: "invalid request"}))
 
-	eq, diff := trial.Equal(route, &Route{
+	// the document of the route is not compared
+	eq, diff := trial.EqualOpt(trial.AllowAllUnexported, trial.EquateEmpty, trial.IgnoreFields("doc", "seq"))(route, &Route{
 		path:    "/test",
 		method:  "GET",
 		Tag:     nil,
@@ -273,6 +366,7 @@ func TestAddResponse(t *testing.T) {
 							Value: map[string]any{"status": "ok"},
 						},
 					},
+					source: map[string]any{"status": "ok"},
 				}},
 			},
 			400: {
@@ -288,6 +382,7 @@ func TestAddResponse(t *testing.T) {
 							Value: struct{ Error string }{Error: "invalid request"},
 						},
 					},
+					source: struct{ Error string }{Error: "invalid request"},
 				}},
 			},
 		},
@@ -299,6 +394,48 @@ func TestAddResponse(t *testing.T) {
 
 }
 
+func TestDefaultResponse(t *testing.T) {
+	type output struct {
+		Resp    Response
+		Warning bool
+	}
+	fn := func(resps []Response) (output, error) {
+		var warnings []string
+		doc := New("t", "v", "desc")
+		route := doc.GetRoute("/test", "GET")
+		for _, resp := range resps {
+			route.DefaultResponse(resp)
+		}
+		route.AddResponse(Response{Status: 404, Desc: "not found"})
+		report := ReportWarnings(func(w string) { warnings = append(warnings, w) })
+		if err := doc.Compile(report); err != nil {
+			return output{}, err
+		}
+		// the warnings are returned without the option as well
+		if ok, diff := trial.Equal(doc.Warnings(), warnings); !ok {
+			t.Errorf("warnings: %v", diff)
+		}
+		return output{
+			Resp:    route.Responses[DefaultStatus],
+			Warning: strings.Contains(strings.Join(doc.Warnings(), "\n"), "GET /test has no success or default response"),
+		}, nil
+	}
+	cases := trial.Cases[[]Response, output]{
+		"description only": {
+			Input:    []Response{{Desc: "unexpected error"}},
+			Expected: output{Resp: Response{Status: DefaultStatus, Desc: "unexpected error"}},
+		},
+		"no description": {
+			Input:    []Response{{Status: 500}},
+			Expected: output{Resp: Response{Status: DefaultStatus, Desc: "default response"}},
+		},
+		"missing": {
+			Expected: output{Warning: true},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
 func TestAddRequest(t *testing.T) {
 	type form struct {
 		Name  string
@@ -311,8 +448,32 @@ func TestAddRequest(t *testing.T) {
 		Desc: "custom Request",
 	}.WithJSONString(`{"Name":"hello world"}`))
 	route.AddRequest(RequestBody{}.WithExample(form{Name: "bob", Value: 12.34, Count: -10}))
-	if len(route.Requests.Content) == 2 {
-		t.Fatalf("expected two Requests to be added but got %v", len(route.Requests.Content))
+	route.AddRequest(RequestBody{}.WithExample(form{Name: "bob", Value: 12.34, Count: -10}))
+	route.AddRequest(RequestBody{}.WithExample(form{Name: "alice"}))
+	route.AddRequest(RequestBody{Required: true}.WithContent(Text, "hello"))
+
+	first := buildSchema(map[string]any{"Name": "hello world"}).Title
+	fn := func(mime MIMEType) ([]string, error) {
+		return sortedKeys(route.Requests.Content[mime].Examples), nil
+	}
+	cases := trial.Cases[MIMEType, []string]{
+		"json examples": {
+			Input:    Json,
+			Expected: []string{first, "openapi.form", "openapi.form2"},
+		},
+		"text examples": {
+			Input:    Text,
+			Expected: []string{""},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	if route.Requests.Desc != "custom Request" || !route.Requests.Required {
+		t.Errorf("expected the description and required to be merged: %+v", route.Requests)
+	}
+	// the schema of the first request is kept
+	if route.Requests.Content[Json].Schema.Title != first {
+		t.Errorf("unexpected schema %v", route.Requests.Content[Json].Schema.Title)
 	}
 }
 
@@ -331,7 +492,92 @@ func TestMarshalRoute(t *testing.T) {
 			},
 			Expected: `{"my/path":{"delete":{},"get":{},"put":{}}}`,
 		},
+		"deprecated": {
+			Input: Router{
+				"my/path|get": (&Route{}).Deprecate(),
+			},
+			Expected: `{"my/path":{"get":{"deprecated":true}}}`,
+		},
+		"description": {
+			Input: Router{
+				"my/path|get": (&Route{Summary: "list"}).WithDesc("# Users\n\nreturns **all** users"),
+			},
+			Expected: `{"my/path":{"get":{"summary":"list","description":"# Users\n\nreturns **all** users"}}}`,
+		},
+		"external docs": {
+			Input: Router{
+				"my/path|get": (&Route{}).WithExternalDocs("https://wiki.example.com/runbook", "runbook"),
+			},
+			Expected: `{"my/path":{"get":{"externalDocs":{"description":"runbook","url":"https://wiki.example.com/runbook"}}}}`,
+		},
 	}
 	trial.New(fn, cases).SubTest(t)
 
 }
+
+func TestWithNamedExamples(t *testing.T) {
+	type item struct {
+		ID int `json:"id"`
+	}
+	resp := Response{Status: 200}.
+		WithNamedExamples(map[string]any{"one": item{ID: 1}, "two": item{ID: 2}}).
+		WithExample(item{ID: 3}).
+		WithExample(item{ID: 4})
+
+	eq, diff := trial.Equal(resp.Content[Json], Media{
+		Schema: Schema{
+			Title:      "openapi.item",
+			Type:       Object,
+			Properties: map[string]Schema{"id": {Type: Integer}},
+		},
+		Examples: map[string]Example{
+			"one":           {Value: item{ID: 1}},
+			"two":           {Value: item{ID: 2}},
+			"openapi.item":  {Value: item{ID: 3}},
+			"openapi.item3": {Value: item{ID: 4}},
+		},
+		source: item{ID: 1},
+	})
+	if !eq {
+		t.Error(diff)
+	}
+
+	req := RequestBody{}.WithNamedExamples(map[string]any{"a": item{}, "b": item{}})
+	if l := len(req.Content[Json].Examples); l != 2 {
+		t.Errorf("expected 2 examples got %d", l)
+	}
+}
+
+func TestWithSchemaFor(t *testing.T) {
+	type patient struct {
+		Name string `json:"name"`
+		SSN  string `json:"ssn"`
+	}
+	doc := New("", "", "")
+	doc.GetRoute("/patients", "post").
+		AddRequest(RequestBody{}.WithSchemaFor(patient{Name: "bob", SSN: "123-45-6789"})).
+		AddResponse(Response{Status: 200}.WithSchemaFor([]patient{}))
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	fn := func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+	r := doc.Paths["/patients|post"]
+	cases := trial.Cases[any, string]{
+		"request": {
+			Input:    r.Requests,
+			Expected: `{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/openapi.patient"}}}}`,
+		},
+		"response": {
+			Input:    r.Responses[200],
+			Expected: `{"description":"","content":{"application/json":{"schema":{"type":"array","items":{"title":"openapi.patient","type":"object","properties":{"name":{"type":"string"},"ssn":{"type":"string"}}}}}}}`,
+		},
+		"component": {
+			Input:    doc.Components.Schemas,
+			Expected: `{"openapi.patient":{"title":"openapi.patient","type":"object","properties":{"name":{"type":"string"},"ssn":{"type":"string"}}}}`,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
@@ -0,0 +1,21 @@
+package openapi
+
+// DocumentProcessor transforms or checks the whole document during Compile,
+// such as tagging operations, adding security requirements, linting or pruning.
+// A CompileOption is a DocumentProcessor.
+type DocumentProcessor interface {
+	Process(o *OpenAPI) error
+}
+
+// Process runs the option on the document
+func (opt CompileOption) Process(o *OpenAPI) error {
+	return opt(o)
+}
+
+// Use registers processors that run in order on every Compile after the routes are compiled,
+// routes they add or change are compiled afterwards. Errors of the processors are returned by Compile.
+//
+//	doc.Use(openapi.CompileOption(openapi.PromoteComponents))
+func (o *OpenAPI) Use(processors ...DocumentProcessor) {
+	o.processors = append(o.processors, processors...)
+}
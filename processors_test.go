@@ -0,0 +1,85 @@
+package openapi
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+// securityProcessor requires the scheme on every operation
+type securityProcessor string
+
+func (s securityProcessor) Process(o *OpenAPI) error {
+	for _, r := range o.Paths {
+		r.Security = []SecurityRequirement{{string(s): {}}}
+	}
+	return nil
+}
+
+func TestUse(t *testing.T) {
+	type output struct {
+		Calls    []string
+		Security []SecurityRequirement
+		Schemas  []string
+	}
+	fn := func(processors []string) (output, error) {
+		var out output
+		doc := New("", "", "")
+		doc.GetRoute("/users", "get").AddResponse(Response{Status: 200, Desc: "ok"})
+		for _, name := range processors {
+			name := name
+			switch name {
+			case "security":
+				doc.Use(securityProcessor("apiKey"))
+			case "add route":
+				doc.Use(CompileOption(func(o *OpenAPI) error {
+					out.Calls = append(out.Calls, name)
+					type order struct {
+						ID string `json:"id"`
+					}
+					o.GetRoute("/orders", "get").AddResponse(Response{Status: 200}.WithExample(order{ID: "1"}))
+					return nil
+				}))
+			case "fail":
+				doc.Use(CompileOption(func(o *OpenAPI) error {
+					out.Calls = append(out.Calls, name)
+					return errors.New("lint failed")
+				}))
+			default:
+				doc.Use(CompileOption(func(o *OpenAPI) error {
+					out.Calls = append(out.Calls, name)
+					return nil
+				}))
+			}
+		}
+		if err := doc.Compile(); err != nil {
+			return out, err
+		}
+		out.Security = doc.GetRoute("/users", "get").Security
+		out.Schemas = sortedKeys(doc.Components.Schemas)
+		return out, nil
+	}
+	cases := trial.Cases[[]string, output]{
+		"in order": {
+			Input:    []string{"first", "second", "third"},
+			Expected: output{Calls: []string{"first", "second", "third"}, Schemas: []string{}},
+		},
+		"security": {
+			Input: []string{"security"},
+			Expected: output{
+				Security: []SecurityRequirement{{"apiKey": {}}},
+				Schemas:  []string{},
+			},
+		},
+		"added route is compiled": {
+			Input:    []string{"add route"},
+			Expected: output{Calls: []string{"add route"}, Schemas: []string{"openapi.order"}},
+		},
+		"error": {
+			Input:     []string{"fail", "after"},
+			ShouldErr: true,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
@@ -0,0 +1,181 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PromoteComponents is a CompileOption that moves parameters, request bodies and responses
+// used by more than one operation into the components, the operations reference them with a $ref.
+// Inline objects that match an existing component are referenced as well.
+// The promoted objects keep their inline values so a later AddRequest or AddParam still merges into them.
+func PromoteComponents(o *OpenAPI) error {
+	keys := sortedKeys(o.Paths)
+	promoted := make(map[*Route]bool)
+
+	// params
+	if o.Components.Parameters == nil {
+		o.Components.Parameters = make(map[string]Param)
+	}
+	params := newPromoter(o.Components.Parameters, "#/components/parameters/")
+	for _, k := range keys {
+		for _, p := range o.Paths[k].Params {
+			params.count(p, p.Ref)
+		}
+	}
+	for _, k := range keys {
+		r := o.Paths[k]
+		for _, p := range r.Params.List() {
+			if ref := params.ref(p, p.Ref, p.In+"."+p.Name); ref != "" {
+				p.Ref = ref
+				r.Params[p.In+"|"+p.Name] = p
+				promoted[r] = true
+			}
+		}
+	}
+
+	// request bodies
+	if o.Components.RequestBodies == nil {
+		o.Components.RequestBodies = make(map[string]RequestBody)
+	}
+	requests := newPromoter(o.Components.RequestBodies, "#/components/requestBodies/")
+	for _, k := range keys {
+		if req := o.Paths[k].Requests; req != nil {
+			requests.count(*req, req.Ref)
+		}
+	}
+	for _, k := range keys {
+		r := o.Paths[k]
+		if r.Requests == nil {
+			continue
+		}
+		name := "RequestBody"
+		if s := r.Requests.Content[Json].Schema; s.Ref != "" {
+			name = strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		}
+		if ref := requests.ref(*r.Requests, r.Requests.Ref, name); ref != "" {
+			r.Requests.Ref = ref
+			promoted[r] = true
+		}
+	}
+
+	// responses
+	if o.Components.Responses == nil {
+		o.Components.Responses = make(map[string]Response)
+	}
+	responses := newPromoter(o.Components.Responses, "#/components/responses/")
+	for _, k := range keys {
+		for _, resp := range o.Paths[k].Responses {
+			responses.count(resp, resp.Ref)
+		}
+	}
+	for _, k := range keys {
+		r := o.Paths[k]
+		for _, code := range sortedCodes(r.Responses) {
+			resp := r.Responses[code]
+			name := strings.ReplaceAll(http.StatusText(int(code)), " ", "")
+			if code == DefaultStatus {
+				name = "Default"
+			} else if name == "" {
+				name = fmt.Sprintf("Response%d", code)
+			}
+			if ref := responses.ref(resp, resp.Ref, name); ref != "" {
+				resp.Ref = ref
+				r.Responses[code] = resp
+				promoted[r] = true
+			}
+		}
+	}
+
+	// the refs are not a change of the compiled routes
+	for r := range promoted {
+		if r.compiled {
+			o.setCompiled(r, nil)
+		}
+	}
+	return nil
+}
+
+// promoter counts the inline objects by their json value and
+// adds the repeated objects to the components.
+type promoter[V any] struct {
+	components map[string]V
+	prefix     string
+	counts     map[string]int
+	names      map[string]string // [json]component name
+}
+
+func newPromoter[V any](components map[string]V, prefix string) *promoter[V] {
+	p := &promoter[V]{
+		components: components,
+		prefix:     prefix,
+		counts:     make(map[string]int),
+		names:      make(map[string]string),
+	}
+	for _, k := range sortedKeys(components) {
+		if b, err := json.Marshal(components[k]); err == nil {
+			p.names[string(b)] = k
+		}
+	}
+	return p
+}
+
+// count the inline object v, references are skipped
+func (p *promoter[V]) count(v V, ref string) {
+	if ref != "" {
+		return
+	}
+	if b, err := json.Marshal(v); err == nil {
+		p.counts[string(b)]++
+	}
+}
+
+// ref returns the $ref for the inline object v if it is repeated or already a component.
+// A new component is created with a unique name based on name.
+func (p *promoter[V]) ref(v V, ref, name string) string {
+	if ref != "" {
+		return ""
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	key := string(b)
+	if n, found := p.names[key]; found {
+		return p.prefix + n
+	}
+	if p.counts[key] < 2 {
+		return ""
+	}
+	n := componentName(name, p.components)
+	p.components[n] = v
+	p.names[key] = n
+	return p.prefix + n
+}
+
+var regexComponentName = regexp.MustCompile(`[^a-zA-Z0-9.\-_]+`)
+
+// componentName creates a valid component key from name that is not used in m.
+func componentName[V any](name string, m map[string]V) string {
+	name = regexComponentName.ReplaceAllString(name, "_")
+	n := name
+	for i := 2; ; i++ {
+		if _, found := m[n]; !found {
+			return n
+		}
+		n = fmt.Sprintf("%s_%d", name, i)
+	}
+}
+
+func sortedCodes(r map[Code]Response) []Code {
+	codes := make([]Code, 0, len(r))
+	for c := range r {
+		codes = append(codes, c)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	return codes
+}
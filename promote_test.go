@@ -0,0 +1,94 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestPromoteComponents(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	doc := New("", "", "")
+	for _, path := range []string{"/users", "/admins"} {
+		doc.GetRoute(path, "post").
+			HeaderParam("X-Tenant-Id", "abc", "tenant").
+			QueryParam(path[1:], true, "").
+			AddRequest(RequestBody{}.WithExample(user{Name: "bob"})).
+			AddResponse(Response{Status: 200}.WithNamedExample("user", user{Name: path})).
+			AddResponse(Response{Status: 400, Desc: "invalid request"}.WithJSONString(`{"error":"invalid"}`))
+	}
+	if err := doc.Compile(PromoteComponents); err != nil {
+		t.Fatal(err)
+	}
+
+	users := doc.GetRoute("/users", "post")
+	if eq, diff := trial.Equal(users.Params["header|X-Tenant-Id"].Ref, "#/components/parameters/header.X-Tenant-Id"); !eq {
+		t.Error(diff)
+	}
+	if ref := users.Params["query|users"].Ref; ref != "" {
+		t.Errorf("unexpected ref for unique param %q", ref)
+	}
+	if eq, diff := trial.Equal(users.Requests.Ref, "#/components/requestBodies/openapi.user"); !eq {
+		t.Error(diff)
+	}
+	if eq, diff := trial.Equal(users.Responses[400].Ref, "#/components/responses/BadRequest"); !eq {
+		t.Error(diff)
+	}
+	if ref := users.Responses[200].Ref; ref != "" {
+		t.Errorf("unexpected ref for unique response %q", ref)
+	}
+	if l := len(doc.Components.Parameters) + len(doc.Components.RequestBodies) + len(doc.Components.Responses); l != 3 {
+		t.Errorf("expected 3 components got %d", l)
+	}
+
+	b, err := users.Params["header|X-Tenant-Id"].MarshalJSON()
+	if eq, diff := trial.Equal(string(b), `{"$ref":"#/components/parameters/header.X-Tenant-Id"}`); err != nil || !eq {
+		t.Error(err, diff)
+	}
+
+	// a new route matching a component is referenced
+	doc.GetRoute("/groups", "get").HeaderParam("X-Tenant-Id", "abc", "tenant")
+	if err := doc.Compile(PromoteComponents); err != nil {
+		t.Fatal(err)
+	}
+	if ref := doc.GetRoute("/groups", "get").Params["header|X-Tenant-Id"].Ref; ref == "" {
+		t.Error("expected param to reference the existing component")
+	}
+
+	// changing a referenced param makes it inline again
+	users.HeaderParam("X-Tenant-Id", "xyz", "")
+	if p := users.Params["header|X-Tenant-Id"]; p.Ref != "" || len(p.Examples) != 2 {
+		t.Errorf("expected inline param with 2 examples got %v", p)
+	}
+	if l := len(doc.Components.Parameters["header.X-Tenant-Id"].Examples); l != 1 {
+		t.Errorf("component param should not change, got %d examples", l)
+	}
+
+	// a request added to a promoted request body is merged
+	users.AddRequest(RequestBody{}.WithNamedExample("alice", user{Name: "alice"}))
+	if req := users.Requests; req.Ref != "" || len(req.Content[Json].Examples) != 2 {
+		t.Errorf("expected merged inline request with 2 examples got %v", req)
+	}
+	if l := len(doc.Components.RequestBodies["openapi.user"].Content[Json].Examples); l != 1 {
+		t.Errorf("component request should not change, got %d examples", l)
+	}
+}
+
+func TestPromoteComponentsFieldChanges(t *testing.T) {
+	doc := New("", "", "")
+	doc.DetectFieldChanges(true)
+	for _, path := range []string{"/users", "/admins"} {
+		doc.GetRoute(path, "get").HeaderParam("X-Tenant-Id", "abc", "tenant")
+	}
+	if err := doc.Compile(PromoteComponents); err != nil {
+		t.Fatal(err)
+	}
+	doc.detectFieldChanges()
+	for _, path := range []string{"/users", "/admins"} {
+		if r := doc.GetRoute(path, "get"); r.pending() {
+			t.Errorf("promoted route %v should not be pending", path)
+		}
+	}
+}
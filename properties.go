@@ -0,0 +1,75 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// propertyOrder returns the keys of the properties of s in its Order, the keys not in the Order
+// such as the keys of a map are sorted after them. nil is returned when the keys are sorted.
+func propertyOrder(s Schema) []string {
+	if len(s.Order) == 0 || len(s.Properties) < 2 {
+		return nil
+	}
+	keys := make([]string, 0, len(s.Properties))
+	added := make(map[string]bool, len(s.Properties))
+	for _, k := range s.Order {
+		if _, found := s.Properties[k]; found && !added[k] {
+			keys = append(keys, k)
+			added[k] = true
+		}
+	}
+	for _, k := range sortedKeys(s.Properties) {
+		if !added[k] {
+			keys = append(keys, k)
+		}
+	}
+	if sort.StringsAreSorted(keys) {
+		return nil
+	}
+	return keys
+}
+
+// orderProperties writes the properties of the json schema b in the order of the keys
+func orderProperties(b []byte, keys []string) ([]byte, error) {
+	fields, err := objectFields(b)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(f.key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		if f.key != "properties" {
+			buf.Write(f.value)
+			continue
+		}
+		props, err := objectFields(f.value)
+		if err != nil {
+			return nil, err
+		}
+		values := make(map[string]json.RawMessage, len(props))
+		for _, p := range props {
+			values[p.key] = p.value
+		}
+		buf.WriteByte('{')
+		for j, key := range keys {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			k, _ := json.Marshal(key)
+			buf.Write(k)
+			buf.WriteByte(':')
+			buf.Write(values[key])
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,46 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestPropertyOrder(t *testing.T) {
+	type address struct {
+		Street string `json:"street"`
+		City   string `json:"city"`
+		Zip    string `json:"zip"`
+	}
+	fn := func(s Schema) (string, error) {
+		b, err := json.Marshal(s)
+		return string(b), err
+	}
+	cases := trial.Cases[Schema, string]{
+		"struct fields": {
+			Input:    buildSchema(address{}),
+			Expected: `{"title":"openapi.address","type":"object","properties":{"street":{"type":"string"},"city":{"type":"string"},"zip":{"type":"string"}}}`,
+		},
+		"map keys": {
+			Input:    buildSchema(map[string]int{"b": 2, "c": 3, "a": 1}),
+			Expected: `{"title":"3776c42000000000","type":"object","properties":{"a":{"type":"integer"},"b":{"type":"integer"},"c":{"type":"integer"}}}`,
+		},
+		"added keys": {
+			Input: Schema{
+				Type:       Object,
+				Properties: map[string]Schema{"z": {Type: String}, "b": {Type: String}, "a": {Type: String}},
+				Order:      []string{"z", "missing"},
+			},
+			Expected: `{"type":"object","properties":{"z":{"type":"string"},"a":{"type":"string"},"b":{"type":"string"}}}`,
+		},
+		"loaded": {
+			Input: func() (s Schema) {
+				json.Unmarshal([]byte(`{"type":"object","properties":{"zip":{"type":"string"},"city":{"type":"string"}}}`), &s)
+				return s
+			}(),
+			Expected: `{"type":"object","properties":{"zip":{"type":"string"},"city":{"type":"string"}}}`,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
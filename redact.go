@@ -0,0 +1,128 @@
+package openapi
+
+import "strings"
+
+// FieldRedactor reports if a field is removed from the schemas and examples of the document.
+// field is the location of the property, the json keys joined by a dot such as user.password.
+type FieldRedactor func(field string) bool
+
+// RedactFields removes the properties with one of the names from the schemas and the examples
+// of the document when it is compiled, so secrets in payloads copied from tests are not published.
+// Names are matched with the last key of the field ignoring case.
+//
+//	doc.RedactFields("password", "ssn")
+func (o *OpenAPI) RedactFields(names ...string) {
+	o.SetFieldRedactor(func(field string) bool {
+		key := field[strings.LastIndex(field, ".")+1:]
+		for _, name := range names {
+			if strings.EqualFold(key, name) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// SetFieldRedactor sets the function that selects the properties removed from the schemas
+// and the examples of the document, see RedactFields.
+func (o *OpenAPI) SetFieldRedactor(fn FieldRedactor) {
+	o.fieldRedactor = fn
+	// the schemas already compiled
+	for name, s := range o.Components.Schemas {
+		o.redactSchema(&s, "")
+		o.Components.Schemas[name] = s
+	}
+	o.invalidate()
+}
+
+// redactSchema removes the redacted properties of the schema and the schemas within it
+func (o *OpenAPI) redactSchema(s *Schema, field string) {
+	if o.fieldRedactor == nil {
+		return
+	}
+	if s.Items != nil {
+		items := s.Items.clone()
+		o.redactSchema(&items, field)
+		s.Items = &items
+	}
+	if s.Properties != nil {
+		props := make(map[string]Schema, len(s.Properties))
+		required := s.Required[:0:0]
+		for k, p := range s.Properties {
+			f := joinField(field, k)
+			if o.fieldRedactor(f) {
+				continue
+			}
+			o.redactSchema(&p, f)
+			props[k] = p
+		}
+		for _, k := range s.Required {
+			if _, found := props[k]; found {
+				required = append(required, k)
+			}
+		}
+		s.Properties, s.Required = props, required
+		if len(s.Required) == 0 {
+			s.Required = nil
+		}
+	}
+	for _, l := range []*[]Schema{&s.OneOf, &s.AnyOf, &s.AllOf} {
+		if *l == nil {
+			continue
+		}
+		schemas := make([]Schema, len(*l))
+		for i, v := range *l {
+			o.redactSchema(&v, field)
+			schemas[i] = v
+		}
+		*l = schemas
+	}
+}
+
+// redactExamples removes the redacted fields from the values of the examples
+func (o *OpenAPI) redactExamples(examples map[string]Example) {
+	if o.fieldRedactor == nil {
+		return
+	}
+	for k, ex := range examples {
+		if ex.Ref != "" {
+			continue
+		}
+		v, err := ex.Decode()
+		if err != nil {
+			continue
+		}
+		// the generic copy keeps the values of the caller unchanged
+		if generic, ok := genericJSON(v); ok {
+			v = generic
+		}
+		ex.Value = o.redactValue(v, "")
+		examples[k] = ex
+	}
+}
+
+func (o *OpenAPI) redactValue(v any, field string) any {
+	switch t := v.(type) {
+	case []any:
+		for i, val := range t {
+			t[i] = o.redactValue(val, field)
+		}
+	case map[string]any:
+		for k, val := range t {
+			f := joinField(field, k)
+			if o.fieldRedactor(f) {
+				delete(t, k)
+				continue
+			}
+			t[k] = o.redactValue(val, f)
+		}
+	}
+	return v
+}
+
+func joinField(field, key string) string {
+	if field == "" {
+		return key
+	}
+	return field + "." + key
+}
@@ -0,0 +1,57 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestRedactFields(t *testing.T) {
+	type account struct {
+		Email    string `json:"email"`
+		Password string `json:"password" openapi:"required"`
+	}
+	type signup struct {
+		Name     string    `json:"name" openapi:"required"`
+		SSN      string    `json:"ssn"`
+		Accounts []account `json:"accounts"`
+	}
+	example := signup{Name: "bob", SSN: "123-45-6789", Accounts: []account{{Email: "bob@example.com", Password: "secret"}}}
+	fn := func(redact func(doc *OpenAPI)) (string, error) {
+		doc := New("", "", "")
+		doc.GetRoute("/signup", "post").
+			AddRequest(RequestBody{}.WithExample(example)).
+			AddResponse(Response{Status: 204})
+		redact(doc)
+		if err := doc.Compile(); err != nil {
+			return "", err
+		}
+		b, err := json.Marshal(map[string]any{
+			"schema":  doc.Components.Schemas["openapi.signup"],
+			"example": doc.Paths["/signup|post"].Requests.Content[Json].Examples["openapi.signup"].Value,
+		})
+		return string(b), err
+	}
+	cases := trial.Cases[func(doc *OpenAPI), string]{
+		"names": {
+			Input: func(doc *OpenAPI) { doc.RedactFields("SSN", "password") },
+			Expected: `{"example":{"accounts":[{"email":"bob@example.com"}],"name":"bob"},` +
+				`"schema":{"title":"openapi.signup","type":"object","properties":{"name":{"type":"string"},"accounts":{"type":"array","items":{"title":"openapi.account","type":"object","properties":{"email":{"type":"string"}}}}},"required":["name"]}}`,
+		},
+		"callback": {
+			Input: func(doc *OpenAPI) {
+				doc.SetFieldRedactor(func(field string) bool { return strings.HasPrefix(field, "accounts") })
+			},
+			Expected: `{"example":{"name":"bob","ssn":"123-45-6789"},` +
+				`"schema":{"title":"openapi.signup","type":"object","properties":{"name":{"type":"string"},"ssn":{"type":"string"}},"required":["name"]}}`,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	// the example of the caller is unchanged
+	if example.SSN == "" || example.Accounts[0].Password == "" {
+		t.Error("example was changed")
+	}
+}
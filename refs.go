@@ -0,0 +1,225 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Resolve replaces the $refs of the operations to the components of the document with their value,
+// the $refs of the schemas are inlined recursively except where a schema references itself,
+// so the params, request bodies, responses, headers, examples and schemas of a loaded document
+// can be changed on each route. Compile references the components again: schemas are moved back
+// by their title and the other objects get their $ref back when they still match the component.
+//
+//	doc, _ := openapi.NewFromJson(spec)
+//	doc.Resolve()
+//	doc.GetRoute("/users", "get").Responses[200].Content[openapi.Json].Schema.Properties // the properties of the component
+func (o *OpenAPI) Resolve() error {
+	if o.resolvedSchemas == nil {
+		o.resolvedSchemas = make(map[string]bool)
+	}
+	var errs error
+	for _, k := range sortedKeys(o.Paths) {
+		r := o.Paths[k]
+		at := r.method + " " + r.path
+		for key, p := range r.Params {
+			c, err := component(o.Components.Parameters, p.Ref, "#/components/parameters/")
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("%v: %w", at, err))
+			}
+			if c == nil {
+				continue
+			}
+			delete(r.Params, key)
+			r.Params[c.In+"|"+c.Name] = *c
+		}
+		if r.Requests != nil {
+			c, err := component(o.Components.RequestBodies, r.Requests.Ref, "#/components/requestBodies/")
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("%v: %w", at, err))
+			}
+			if c != nil {
+				r.Requests = c
+			}
+			r.Requests.Content, err = o.resolveContent(r.Requests.Content)
+			errs = errors.Join(errs, err)
+		}
+		for code, resp := range r.Responses {
+			c, err := component(o.Components.Responses, resp.Ref, "#/components/responses/")
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("%v: %w", at, err))
+			}
+			if c != nil {
+				resp = *c
+				resp.Status = code
+			}
+			if resp.Content, err = o.resolveContent(resp.Content); err != nil {
+				errs = errors.Join(errs, fmt.Errorf("%v: %w", at, err))
+			}
+			headers := make(map[string]Header, len(resp.Headers))
+			for name, h := range resp.Headers {
+				c, err := component(o.Components.Headers, h.Ref, "#/components/headers/")
+				if err != nil {
+					errs = errors.Join(errs, fmt.Errorf("%v: %w", at, err))
+				}
+				if c != nil {
+					h = *c
+				}
+				headers[name] = h
+			}
+			if resp.Headers != nil {
+				resp.Headers = headers
+			}
+			r.Responses[code] = resp
+		}
+		r.compiled = false
+	}
+	o.resolved = true
+	return errs
+}
+
+// component returns a copy of the component of the ref, nil when ref does not point to prefix
+func component[V any](components map[string]V, ref, prefix string) (*V, error) {
+	name, found := strings.CutPrefix(ref, prefix)
+	if !found {
+		return nil, nil
+	}
+	v, found := components[name]
+	if !found {
+		return nil, fmt.Errorf("%v not found", ref)
+	}
+	return &v, nil
+}
+
+// resolveContent returns a copy of the content with the component schemas and examples inlined
+func (o *OpenAPI) resolveContent(content Content) (Content, error) {
+	if content == nil {
+		return nil, nil
+	}
+	var errs error
+	resolved := make(Content, len(content))
+	for mime, m := range content {
+		s, err := o.resolveSchema(m.Schema.clone(), make(map[string]bool))
+		errs = errors.Join(errs, err)
+		m.Schema = s
+		examples := make(map[string]Example, len(m.Examples))
+		for k, ex := range m.Examples {
+			c, err := component(o.Components.Examples, ex.Ref, "#/components/examples/")
+			errs = errors.Join(errs, err)
+			if c != nil {
+				ex = *c
+			}
+			examples[k] = ex
+		}
+		if m.Examples != nil {
+			m.Examples = examples
+		}
+		resolved[mime] = m
+	}
+	return resolved, errs
+}
+
+// resolveSchema returns the schema with the $refs to the component schemas inlined, in its
+// properties, items and compositions as well as in the components they reference.
+// A component that references itself, directly or through other components, keeps its $ref
+// where it would be repeated. parents are the components being inlined.
+func (o *OpenAPI) resolveSchema(s Schema, parents map[string]bool) (Schema, error) {
+	if name, found := strings.CutPrefix(s.Ref, "#/components/schemas/"); found {
+		if parents[name] {
+			return s, nil
+		}
+		c, found := o.Components.Schemas[name]
+		if !found {
+			return s, fmt.Errorf("%v not found", s.Ref)
+		}
+		s = c.clone()
+		s.Title = name
+		o.resolvedSchemas[name] = true
+		parents[name] = true
+		defer delete(parents, name)
+		if s.Ref != "" {
+			// a component that is a $ref to another component is the schema of that component,
+			// Compile references the last component of the chain
+			return o.resolveSchema(Schema{Ref: s.Ref}, parents)
+		}
+	}
+	var errs error
+	eachSchema(&s, func(child *Schema) {
+		var err error
+		*child, err = o.resolveSchema(*child, parents)
+		errs = errors.Join(errs, err)
+	})
+	return s, errs
+}
+
+// resolvedRefs references the components again for the schemas nested in s that were inlined
+// by Resolve, a component is replaced when its inlined schema was changed.
+func (o *OpenAPI) resolvedRefs(s Schema, at string) (Schema, error) {
+	var errs error
+	eachSchema(&s, func(child *Schema) {
+		c, err := o.resolvedRefs(*child, at)
+		errs = errors.Join(errs, err)
+		if o.resolvedSchemas[c.Title] {
+			c, err = o.componentSchema(c, at)
+			errs = errors.Join(errs, err)
+		}
+		*child = c
+	})
+	return s, errs
+}
+
+// compactRefs references the components again for the objects that were inlined by Resolve
+// and still match the component.
+func (o *OpenAPI) compactRefs() {
+	keys := sortedKeys(o.Paths)
+	examples := newPromoter(o.Components.Examples, "#/components/examples/")
+	compactContent := func(c Content) {
+		for mime, m := range c {
+			for name, ex := range m.Examples {
+				if ref := examples.ref(ex, ex.Ref, name); ref != "" {
+					m.Examples[name] = Example{Ref: ref}
+				}
+			}
+			c[mime] = m
+		}
+	}
+	headers := newPromoter(o.Components.Headers, "#/components/headers/")
+	params := newPromoter(o.Components.Parameters, "#/components/parameters/")
+	requests := newPromoter(o.Components.RequestBodies, "#/components/requestBodies/")
+	responses := newPromoter(o.Components.Responses, "#/components/responses/")
+	for _, k := range keys {
+		r := o.Paths[k]
+		for key, p := range r.Params {
+			if ref := params.ref(p, p.Ref, key); ref != "" {
+				p.Ref = ref
+				r.Params[key] = p
+			}
+		}
+		if r.Requests != nil {
+			compactContent(r.Requests.Content)
+			if ref := requests.ref(*r.Requests, r.Requests.Ref, ""); ref != "" {
+				r.Requests.Ref = ref
+			}
+		}
+		for code, resp := range r.Responses {
+			compactContent(resp.Content)
+			for name, h := range resp.Headers {
+				if ref := headers.ref(h, h.Ref, name); ref != "" {
+					resp.Headers[name] = Header{Ref: ref}
+				}
+			}
+			if ref := responses.ref(resp, resp.Ref, ""); ref != "" {
+				resp.Ref = ref
+			}
+			r.Responses[code] = resp
+		}
+	}
+}
+
+// sameSchema reports if the schemas are the same ignoring their title,
+// the title of a schema inlined by Resolve is the name of the component.
+func sameSchema(a, b Schema) bool {
+	b.Title = a.Title
+	return sameJSON(a, b)
+}
@@ -0,0 +1,184 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+const refSpec = `{
+	"openapi": "3.0.3",
+	"info": {"title": "refs", "version": "v1"},
+	"paths": {
+		"/users/{id}": {
+			"get": {
+				"parameters": [{"$ref": "#/components/parameters/id"}],
+				"responses": {
+					"200": {
+						"description": "the user",
+						"headers": {"X-Rate-Limit": {"$ref": "#/components/headers/RateLimit"}},
+						"content": {"application/json": {
+							"schema": {"$ref": "#/components/schemas/User"},
+							"examples": {"bob": {"$ref": "#/components/examples/bob"}}
+						}}
+					},
+					"404": {"$ref": "#/components/responses/NotFound"}
+				}
+			}
+		}
+	},
+	"components": {
+		"schemas": {"User": {"type": "object", "properties": {"name": {"type": "string"}}}},
+		"parameters": {"id": {"name": "id", "in": "path", "schema": {"type": "string"}, "examples": {}}},
+		"headers": {"RateLimit": {"schema": {"type": "integer"}}},
+		"examples": {"bob": {"value": {"name": "bob"}}},
+		"responses": {"NotFound": {"description": "not found"}}
+	}
+}`
+
+func TestResolve(t *testing.T) {
+	doc, err := NewFromJson(refSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+	r := doc.GetRoute("/users/{id}", "get")
+	resp := r.Responses[200]
+	media := resp.Content[Json]
+
+	fn := func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+	cases := trial.Cases[any, string]{
+		"param": {
+			Input:    r.Params["path|id"],
+			Expected: `{"name":"id","in":"path","schema":{"type":"string"},"examples":{}}`,
+		},
+		"schema": {
+			Input:    media.Schema,
+			Expected: `{"title":"User","type":"object","properties":{"name":{"type":"string"}}}`,
+		},
+		"example": {
+			Input:    media.Examples,
+			Expected: `{"bob":{"value":{"name":"bob"}}}`,
+		},
+		"header": {
+			Input:    resp.Headers,
+			Expected: `{"X-Rate-Limit":{"schema":{"type":"integer"}}}`,
+		},
+		"response": {
+			Input:    r.Responses[404],
+			Expected: `{"description":"not found"}`,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	// the changed schema replaces the component, the unchanged objects are referenced again
+	media.Schema.Properties["email"] = Schema{Type: String}
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	cases = trial.Cases[any, string]{
+		"component": {
+			Input:    doc.Components.Schemas["User"],
+			Expected: `{"title":"User","type":"object","properties":{"email":{"type":"string"},"name":{"type":"string"}}}`,
+		},
+		"operation": {
+			Input:    doc.GetRoute("/users/{id}", "get"),
+			Expected: `{"responses":{"200":{"description":"the user","headers":{"X-Rate-Limit":{"$ref":"#/components/headers/RateLimit"}},"content":{"application/json":{"schema":{"$ref":"#/components/schemas/User"},"examples":{"bob":{"$ref":"#/components/examples/bob"}}}}},"404":{"$ref":"#/components/responses/NotFound"}},"parameters":[{"$ref":"#/components/parameters/id"}]}`,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
+const nestedRefSpec = `{
+	"openapi": "3.0.3",
+	"info": {"title": "refs", "version": "v1"},
+	"paths": {
+		"/users": {
+			"get": {
+				"responses": {
+					"200": {"description": "the user", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}},
+					"201": {"description": "the admin", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Admin"}}}}
+				}
+			}
+		}
+	},
+	"components": {
+		"schemas": {
+			"Admin": {"$ref": "#/components/schemas/User"},
+			"Address": {"type": "object", "properties": {"city": {"type": "string"}}},
+			"Cat": {"type": "object", "properties": {"indoor": {"type": "boolean"}}},
+			"Dog": {"type": "object", "properties": {"breed": {"type": "string"}}},
+			"Node": {"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#/components/schemas/Node"}}}},
+			"User": {"type": "object", "properties": {
+				"address": {"$ref": "#/components/schemas/Address"},
+				"pets": {"type": "array", "items": {"oneOf": [{"$ref": "#/components/schemas/Cat"}, {"$ref": "#/components/schemas/Dog"}]}},
+				"tree": {"$ref": "#/components/schemas/Node"}
+			}}
+		}
+	}
+}`
+
+func TestResolveNested(t *testing.T) {
+	doc, err := NewFromJson(nestedRefSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	components, _ := json.Marshal(doc.Components.Schemas)
+	if err := doc.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+	r := doc.GetRoute("/users", "get")
+
+	fn := func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+	user := `{"title":"User","type":"object","properties":{` +
+		`"address":{"title":"Address","type":"object","properties":{"city":{"type":"string"}}},` +
+		`"pets":{"type":"array","items":{"oneOf":[{"title":"Cat","type":"object","properties":{"indoor":{"type":"boolean"}}},{"title":"Dog","type":"object","properties":{"breed":{"type":"string"}}}]}},` +
+		`"tree":{"title":"Node","type":"object","properties":{"children":{"type":"array","items":{"$ref":"#/components/schemas/Node"}}}}}}`
+	cases := trial.Cases[any, string]{
+		"nested": {
+			Input:    r.Responses[200].Content[Json].Schema,
+			Expected: user,
+		},
+		"chain": {
+			Input:    r.Responses[201].Content[Json].Schema,
+			Expected: user,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	// the unchanged schemas are referenced again
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := json.Marshal(doc.Components.Schemas)
+	if eq, diff := trial.Equal(string(got), string(components)); !eq {
+		t.Error(diff)
+	}
+	for _, code := range []Code{200, 201} {
+		if ref := r.Responses[code].Content[Json].Schema.Ref; ref != "#/components/schemas/User" {
+			t.Errorf("%v: unexpected ref %q", code, ref)
+		}
+	}
+}
+
+func TestResolveMissing(t *testing.T) {
+	doc, err := NewFromJson(`{"openapi": "3.0.3", "info": {"title": "refs", "version": "v1"},
+		"paths": {"/users": {"get": {"responses": {"200": {"description": "the user",
+			"content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}}}}}},
+		"components": {"schemas": {"User": {"type": "object", "properties": {"address": {"$ref": "#/components/schemas/Address"}}}}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Resolve(); err == nil || err.Error() != "get /users: #/components/schemas/Address not found" {
+		t.Errorf("expected the missing component to be reported got %v", err)
+	}
+}
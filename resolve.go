@@ -0,0 +1,361 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Resolver inlines $refs that point at other files or URLs,
+// so a document split across multiple files becomes one self-contained document.
+// Files and URLs with a .yaml or .yml extension are read as yaml, all others as json.
+// Loaded documents are cached by their location.
+type Resolver struct {
+	// Allow is a list of URLs and directories that may be loaded, a URL allows the locations
+	// with its scheme and host below its path and a directory the files within it.
+	// When empty all local files are allowed and no URLs.
+	Allow  []string
+	Client *http.Client // client used to fetch URLs, http.DefaultClient if nil
+
+	mu    sync.Mutex
+	cache map[string]any
+}
+
+// NewResolver creates a Resolver that can load locations starting with one of the allowed prefixes.
+func NewResolver(allow ...string) *Resolver {
+	return &Resolver{Allow: allow}
+}
+
+// Load reads the document at the location (file or URL), inlines all external $refs
+// and returns the OpenAPI object. Swagger 2.0 documents are converted with NewFromSwagger2.
+// The components of the root document that are a $ref to another file are inlined
+// and the refs to the same file are refs to the component, so a document written with
+// WriteSplit is read back as the document that was split.
+func (r *Resolver) Load(location string) (*OpenAPI, error) {
+	loc, err := r.location(location, "")
+	if err != nil {
+		return nil, err
+	}
+	doc, err := r.load(loc)
+	if err != nil {
+		return nil, err
+	}
+	v, err := r.resolveRoot(copyJSON(doc), loc)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if IsSwagger2(b) {
+		return NewFromSwagger2(string(b))
+	}
+	return NewFromJson(string(b))
+}
+
+// Resolve inlines all external $refs of the json spec, relative refs
+// are resolved from base which is the file or URL of the spec.
+func (r *Resolver) Resolve(base string, spec []byte) ([]byte, error) {
+	var doc any
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, err
+	}
+	loc, err := r.location(base, "")
+	if err != nil {
+		return nil, err
+	}
+	v, err := r.resolveRoot(doc, loc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// resolveRoot inlines the external $refs of the root document at loc.
+// The components that are a $ref to another location are inlined first,
+// the other refs to their location are replaced by a ref to the component.
+func (r *Resolver) resolveRoot(doc any, loc string) (any, error) {
+	local := make(map[string]string)
+	type component struct {
+		entries map[string]any
+		name    string
+		key     string
+	}
+	var refs []component
+	components, _ := pointer(doc, "/components")
+	kinds, _ := components.(map[string]any)
+	for _, kind := range sortedKeys(kinds) {
+		entries, _ := kinds[kind].(map[string]any)
+		for _, name := range sortedKeys(entries) {
+			m, _ := entries[name].(map[string]any)
+			ref, isRef := m["$ref"].(string)
+			path, fragment, _ := strings.Cut(ref, "#")
+			if !isRef || path == "" {
+				continue
+			}
+			target, err := r.location(path, loc)
+			if err != nil {
+				return nil, err
+			}
+			key := target + "#" + fragment
+			local[key] = "#/components/" + kind + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+			refs = append(refs, component{entries: entries, name: name, key: key})
+		}
+	}
+	for _, c := range refs {
+		target, fragment, _ := strings.Cut(c.key, "#")
+		targetDoc, err := r.load(target)
+		if err != nil {
+			return nil, err
+		}
+		val, err := pointer(targetDoc, fragment)
+		if err != nil {
+			return nil, fmt.Errorf("$ref %q: %w", c.key, err)
+		}
+		if c.entries[c.name], err = r.resolve(copyJSON(val), loc, target, targetDoc, local, []string{c.key}); err != nil {
+			return nil, err
+		}
+	}
+	return r.resolve(doc, loc, loc, doc, local, nil)
+}
+
+// resolve walks through v which is part of the document at loc and replaces
+// every $ref object that does not point into the root document with its value.
+// A ref to a location in local is replaced by the local ref of the root document.
+// seen holds the refs being resolved to detect cycles.
+func (r *Resolver) resolve(v any, root, loc string, doc any, local map[string]string, seen []string) (any, error) {
+	switch t := v.(type) {
+	case []any:
+		for i := range t {
+			val, err := r.resolve(t[i], root, loc, doc, local, seen)
+			if err != nil {
+				return nil, err
+			}
+			t[i] = val
+		}
+		return t, nil
+	case map[string]any:
+		ref, isRef := t["$ref"].(string)
+		if !isRef {
+			for k := range t {
+				val, err := r.resolve(t[k], root, loc, doc, local, seen)
+				if err != nil {
+					return nil, err
+				}
+				t[k] = val
+			}
+			return t, nil
+		}
+
+		path, fragment, _ := strings.Cut(ref, "#")
+		if path == "" && loc == root {
+			return t, nil // local refs of the root document are kept
+		}
+		target, targetDoc := loc, doc
+		if path != "" {
+			var err error
+			if target, err = r.location(path, loc); err != nil {
+				return nil, err
+			}
+			if targetDoc, err = r.load(target); err != nil {
+				return nil, err
+			}
+		}
+		key := target + "#" + fragment
+		if ref, found := local[key]; found {
+			return map[string]any{"$ref": ref}, nil
+		}
+		if target == root {
+			return map[string]any{"$ref": "#" + fragment}, nil // refs into the root document from another file
+		}
+		for _, s := range seen {
+			if s == key {
+				return nil, fmt.Errorf("circular $ref %q", key)
+			}
+		}
+		val, err := pointer(targetDoc, fragment)
+		if err != nil {
+			return nil, fmt.Errorf("$ref %q: %w", ref, err)
+		}
+		return r.resolve(copyJSON(val), root, target, targetDoc, local, append(seen, key))
+	}
+	return v, nil
+}
+
+// location creates the absolute location of path relative to the base location
+// and checks that it is allowed.
+func (r *Resolver) location(path, base string) (string, error) {
+	var loc string
+	if u, err := url.Parse(path); err == nil && u.Scheme != "" && len(u.Scheme) > 1 {
+		loc = u.String()
+	} else if b, err := url.Parse(base); err == nil && (b.Scheme == "http" || b.Scheme == "https") {
+		loc = b.ResolveReference(&url.URL{Path: path}).String()
+	} else {
+		if !filepath.IsAbs(path) && base != "" {
+			path = filepath.Join(filepath.Dir(base), path)
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return "", err
+		}
+		loc = abs
+	}
+
+	isURL := strings.HasPrefix(loc, "http://") || strings.HasPrefix(loc, "https://")
+	if len(r.Allow) == 0 && !isURL {
+		return loc, nil
+	}
+	for _, prefix := range r.Allow {
+		if allowed(loc, prefix) {
+			return loc, nil
+		}
+	}
+	return "", fmt.Errorf("location %q is not allowed", loc)
+}
+
+// allowed reports if the location is within the allowed prefix. A URL must have the scheme
+// and host of the prefix and a path within the path of the prefix, a file must be in the
+// directory of the prefix. Paths are compared by their segments so /specs does not allow /specs-secret.
+func allowed(loc, prefix string) bool {
+	u, err := url.Parse(loc)
+	if err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		p, err := url.Parse(prefix)
+		if err != nil || !strings.EqualFold(p.Scheme, u.Scheme) || !strings.EqualFold(p.Host, u.Host) {
+			return false
+		}
+		dir := strings.TrimSuffix(path.Clean("/"+p.Path), "/")
+		file := path.Clean("/" + u.Path)
+		return dir == "" || file == dir || strings.HasPrefix(file, dir+"/")
+	}
+	if p, err := url.Parse(prefix); err == nil && p.Scheme != "" && len(p.Scheme) > 1 {
+		return false // a URL prefix does not allow files
+	}
+	dir, err := filepath.Abs(prefix)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(dir, loc)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// load the json document at the location from the cache, a file or a URL
+func (r *Resolver) load(loc string) (any, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if doc, found := r.cache[loc]; found {
+		return doc, nil
+	}
+
+	var b []byte
+	var err error
+	if strings.HasPrefix(loc, "http://") || strings.HasPrefix(loc, "https://") {
+		b, err = r.fetch(loc)
+	} else {
+		b, err = os.ReadFile(loc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	if isYAML(loc) {
+		err = yaml.Unmarshal(b, &doc)
+		doc = stringKeys(doc)
+	} else {
+		err = json.Unmarshal(b, &doc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", loc, err)
+	}
+	if r.cache == nil {
+		r.cache = make(map[string]any)
+	}
+	r.cache[loc] = doc
+	return doc, nil
+}
+
+// isYAML reports if the file or URL at the location has a yaml extension
+func isYAML(loc string) bool {
+	if u, err := url.Parse(loc); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		loc = u.Path
+	}
+	ext := strings.ToLower(filepath.Ext(loc))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+func (r *Resolver) fetch(u string) ([]byte, error) {
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%v: unexpected status %v", u, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// pointer returns the value of the json pointer (RFC 6901) in doc
+func pointer(doc any, ptr string) (any, error) {
+	if ptr == "" || ptr == "/" {
+		return doc, nil
+	}
+	v := doc
+	for _, token := range strings.Split(strings.TrimPrefix(ptr, "/"), "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		if s, err := url.PathUnescape(token); err == nil {
+			token = s
+		}
+		switch t := v.(type) {
+		case map[string]any:
+			val, found := t[token]
+			if !found {
+				return nil, fmt.Errorf("%q not found", ptr)
+			}
+			v = val
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(t) {
+				return nil, fmt.Errorf("%q invalid index %q", ptr, token)
+			}
+			v = t[i]
+		default:
+			return nil, errors.New("invalid json pointer " + ptr)
+		}
+	}
+	return v, nil
+}
+
+// copyJSON creates a deep copy of a generic json value so cached documents are not modified
+func copyJSON(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(t))
+		for k, val := range t {
+			m[k] = copyJSON(val)
+		}
+		return m
+	case []any:
+		s := make([]any, len(t))
+		for i, val := range t {
+			s[i] = copyJSON(val)
+		}
+		return s
+	}
+	return v
+}
@@ -0,0 +1,179 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestResolver(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/common.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"Error": {"type": "object", "properties": {"msg": {"type": "string"}}}}`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"models.json": `{
+			"User": {"type": "object", "properties": {"id": {"type": "integer"}, "group": {"$ref": "#/Group"}}},
+			"Group": {"type": "string"}
+		}`,
+		"cycle.json":  `{"A": {"$ref": "#/B"}, "B": {"$ref": "#/A"}}`,
+		"models.yaml": "Item:\n  type: object\n  properties:\n    id:\n      $ref: '#/ID'\nID:\n  type: integer\n",
+	}
+	for name, s := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fn := func(in string) (string, error) {
+		r := NewResolver(dir, srv.URL)
+		b, err := r.Resolve(filepath.Join(dir, "openapi.json"), []byte(in))
+		return string(b), err
+	}
+	cases := trial.Cases[string, string]{
+		"local refs": {
+			Input:    `{"schema": {"$ref": "#/components/schemas/User"}}`,
+			Expected: `{"schema":{"$ref":"#/components/schemas/User"}}`,
+		},
+		"file": {
+			Input:    `{"schema": {"$ref": "models.json#/User"}}`,
+			Expected: `{"schema":{"properties":{"group":{"type":"string"},"id":{"type":"integer"}},"type":"object"}}`,
+		},
+		"yaml file": {
+			Input:    `{"schema": {"$ref": "models.yaml#/Item"}}`,
+			Expected: `{"schema":{"properties":{"id":{"type":"integer"}},"type":"object"}}`,
+		},
+		"url": {
+			Input:    `{"schema": {"$ref": "` + srv.URL + `/common.json#/Error/properties"}}`,
+			Expected: `{"schema":{"msg":{"type":"string"}}}`,
+		},
+		"not found": {
+			Input:     `{"schema": {"$ref": "models.json#/Missing"}}`,
+			ShouldErr: true,
+		},
+		"not allowed": {
+			Input:     `{"schema": {"$ref": "/etc/passwd"}}`,
+			ShouldErr: true,
+		},
+		"url not allowed": {
+			Input:     `{"schema": {"$ref": "https://example.com/common.json"}}`,
+			ShouldErr: true,
+		},
+		"circular": {
+			Input:     `{"schema": {"$ref": "cycle.json#/A"}}`,
+			ShouldErr: true,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	// documents are cached by location
+	requests = 0
+	r := NewResolver(srv.URL)
+	for i := 0; i < 2; i++ {
+		if _, err := r.Resolve(srv.URL+"/openapi.json", []byte(`{"$ref": "common.json#/Error"}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request got %d", requests)
+	}
+}
+
+func TestResolverAllow(t *testing.T) {
+	dir := t.TempDir()
+	type input struct {
+		allow    string
+		location string
+	}
+	fn := func(in input) (bool, error) {
+		_, err := NewResolver(in.allow).location(in.location, "")
+		return err == nil, nil
+	}
+	cases := trial.Cases[input, bool]{
+		"url": {
+			Input:    input{allow: "https://example.com/specs", location: "https://example.com/specs/common.json"},
+			Expected: true,
+		},
+		"url host": {
+			Input:    input{allow: "https://example.com", location: "https://example.com/common.json"},
+			Expected: true,
+		},
+		"url host suffix": {
+			Input:    input{allow: "https://example.com", location: "https://example.com.evil.com/x"},
+			Expected: false,
+		},
+		"url user info": {
+			Input:    input{allow: "https://example.com", location: "https://example.com@evil.com/x"},
+			Expected: false,
+		},
+		"url path segment": {
+			Input:    input{allow: "https://example.com/specs", location: "https://example.com/specs-secret/x"},
+			Expected: false,
+		},
+		"url parent path": {
+			Input:    input{allow: "https://example.com/specs", location: "https://example.com/specs/../secret/x"},
+			Expected: false,
+		},
+		"url scheme": {
+			Input:    input{allow: "https://example.com", location: "http://example.com/x"},
+			Expected: false,
+		},
+		"file": {
+			Input:    input{allow: filepath.Join(dir, "specs"), location: filepath.Join(dir, "specs", "common.json")},
+			Expected: true,
+		},
+		"file path segment": {
+			Input:    input{allow: filepath.Join(dir, "specs"), location: filepath.Join(dir, "specs-secret", "x")},
+			Expected: false,
+		},
+		"file parent": {
+			Input:    input{allow: filepath.Join(dir, "specs"), location: filepath.Join(dir, "specs") + "/../secret.json"},
+			Expected: false,
+		},
+		"file with url prefix": {
+			Input:    input{allow: "https://example.com", location: filepath.Join(dir, "x.json")},
+			Expected: false,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
+func TestResolverLoad(t *testing.T) {
+	dir := t.TempDir()
+	root := `{
+		"openapi": "3.0.3",
+		"info": {"title": "split", "version": "1.0.0"},
+		"paths": {"/users": {"$ref": "paths/users.json"}}
+	}`
+	users := `{"get": {"responses": {"200": {"$ref": "../responses.json#/User"}}}}`
+	responses := `{"User": {"description": "a user", "content": {"application/json": {"schema": {"type": "object"}}}}}`
+	os.Mkdir(filepath.Join(dir, "paths"), 0755)
+	os.WriteFile(filepath.Join(dir, "openapi.json"), []byte(root), 0644)
+	os.WriteFile(filepath.Join(dir, "paths", "users.json"), []byte(users), 0644)
+	os.WriteFile(filepath.Join(dir, "responses.json"), []byte(responses), 0644)
+
+	doc, err := NewResolver().Load(filepath.Join(dir, "openapi.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := doc.GetRoute("/users", "get").Responses[200]
+	eq, diff := trial.Equal(resp.Desc, "a user")
+	if !eq {
+		t.Error(diff)
+	}
+	if resp.Content[Json].Schema.Type != Object {
+		t.Errorf("expected object schema got %q", resp.Content[Json].Schema.Type)
+	}
+}
@@ -0,0 +1,60 @@
+package openapi
+
+// SetSchemaName names the component schema of the type of the example v in this document only,
+// other documents built in the same process keep their names. Compile uses the name in place
+// of the title the schema was built with, the schemas of routes already compiled are renamed
+// on the next Compile. It is safe to call from multiple goroutines, but not during Compile.
+//
+//	doc.SetSchemaName(User{}, "TenantUser")
+func (o *OpenAPI) SetSchemaName(v any, name string) {
+	b := o.schemaBuilder()
+	title := b.build(v).Title
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.components == nil {
+		b.components = make(map[string]string)
+	}
+	b.components[title] = name
+	o.invalidate()
+}
+
+// SchemaName returns the name set with SetSchemaName for the type of the example v
+func (o *OpenAPI) SchemaName(v any) (string, bool) {
+	return o.schemaName(o.buildSchema(v).Title)
+}
+
+// schemaName returns the name of the component schema built with the title
+func (o *OpenAPI) schemaName(title string) (string, bool) {
+	b := o.schemaBuilder()
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	name, found := b.components[title]
+	return name, found
+}
+
+// applySchemaNames moves the component schemas added before their name was set with SetSchemaName
+// to the name and updates the refs to them. A component that already has the name is kept.
+func (o *OpenAPI) applySchemaNames() {
+	renames := make(map[string]string)
+	for _, title := range sortedKeys(o.Components.Schemas) {
+		name, found := o.schemaName(title)
+		if !found || name == title {
+			continue
+		}
+		if _, found := o.Components.Schemas[name]; found {
+			continue
+		}
+		s := o.Components.Schemas[title]
+		s.Title = name
+		o.Components.Schemas[name] = s
+		delete(o.Components.Schemas, title)
+		if origin, found := o.schemaOrigins[title]; found {
+			o.schemaOrigins[name] = origin
+			delete(o.schemaOrigins, title)
+		}
+		renames[title] = name
+	}
+	if len(renames) > 0 {
+		o.renameSchemaRefs(renames)
+	}
+}
@@ -0,0 +1,108 @@
+package openapi
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestSetSchemaName(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	tenants := []string{"AcmeUser", "GlobexUser", ""}
+	docs := make([]*OpenAPI, len(tenants))
+	var wg sync.WaitGroup
+	for i, name := range tenants {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			doc := New("", "", "")
+			if name != "" {
+				doc.SetSchemaName(user{}, name)
+			}
+			doc.GetRoute("/users", "get").AddResponse(Response{Status: 200}.WithExample(user{Name: "bob"}))
+			if err := doc.Compile(); err != nil {
+				t.Error(err)
+			}
+			docs[i] = doc
+		}(i, name)
+	}
+	wg.Wait()
+
+	fn := func(i int) ([]string, error) {
+		doc := docs[i]
+		return []string{
+			sortedKeys(doc.Components.Schemas)[0],
+			doc.Paths["/users|get"].Responses[200].Content[Json].Schema.Ref,
+		}, nil
+	}
+	cases := trial.Cases[int, []string]{
+		"acme": {
+			Input:    0,
+			Expected: []string{"AcmeUser", "#/components/schemas/AcmeUser"},
+		},
+		"globex": {
+			Input:    1,
+			Expected: []string{"GlobexUser", "#/components/schemas/GlobexUser"},
+		},
+		"default": {
+			Input:    2,
+			Expected: []string{"openapi.user", "#/components/schemas/openapi.user"},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	if name, found := docs[0].SchemaName(user{}); !found || name != "AcmeUser" {
+		t.Errorf("unexpected name %q", name)
+	}
+}
+
+func TestSetSchemaNameCompiled(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	doc := New("", "", "")
+	doc.GetRoute("/users", "get").AddResponse(Response{Status: 200}.WithExample(user{Name: "bob"}))
+	doc.GetRoute("/trees", "get").AddResponse(Response{Status: 200}.WithExample(tree{}))
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	doc.SetSchemaName(user{}, "User")
+	doc.SetSchemaName(node{}, "Node")
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	got := []string{
+		doc.Paths["/users|get"].Responses[200].Content[Json].Schema.Ref,
+		doc.Components.Schemas["openapi.tree"].Properties["root"].Properties["parent"].Ref,
+		doc.Components.Schemas["Node"].Properties["children"].Items.Ref,
+	}
+	got = append(got, sortedKeys(doc.Components.Schemas)...)
+	eq, diff := trial.Equal(got, []string{
+		"#/components/schemas/User",
+		"#/components/schemas/Node",
+		"#/components/schemas/Node",
+		"Node", "User", "openapi.tree",
+	})
+	if !eq {
+		t.Error(diff)
+	}
+}
+
+func TestSetSchemaNameCycle(t *testing.T) {
+	doc := New("", "", "")
+	doc.SetSchemaName(node{}, "Node")
+	doc.GetRoute("/trees", "get").AddResponse(Response{Status: 200}.WithExample(node{}))
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	n := doc.Components.Schemas["Node"]
+	got := []string{n.Title, n.Properties["parent"].Ref, n.Properties["children"].Items.Ref}
+	got = append(got, sortedKeys(doc.Components.Schemas)...)
+	eq, diff := trial.Equal(got, []string{"Node", "#/components/schemas/Node", "#/components/schemas/Node", "Node"})
+	if !eq {
+		t.Error(diff)
+	}
+}
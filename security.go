@@ -0,0 +1,240 @@
+package openapi
+
+import (
+	"fmt"
+	"path"
+)
+
+// SecurityScheme defines a security scheme that can be used by the operations.
+// Supported schemes are HTTP authentication, an API key (either as a header, a cookie parameter or as a query parameter),
+// OAuth2's common flows (implicit, password, client credentials and authorization code) and OpenID Connect Discovery.
+type SecurityScheme struct {
+	Type             string      `json:"type"`                       // REQUIRED. The type of the security scheme. Valid values are "apiKey", "http", "oauth2", "openIdConnect".
+	Desc             string      `json:"description,omitempty"`      // A short description for security scheme. CommonMark syntax MAY be used for rich text representation.
+	Name             string      `json:"name,omitempty"`             // apiKey REQUIRED. The name of the header, query or cookie parameter to be used.
+	In               string      `json:"in,omitempty"`               // apiKey REQUIRED. The location of the API key. Valid values are "query", "header" or "cookie".
+	Scheme           string      `json:"scheme,omitempty"`           // http REQUIRED. The name of the HTTP Authorization scheme to be used in the Authorization header as defined in RFC7235.
+	BearerFormat     string      `json:"bearerFormat,omitempty"`     // http ("bearer") A hint to the client to identify how the bearer token is formatted.
+	Flows            *OAuthFlows `json:"flows,omitempty"`            // oauth2 REQUIRED. An object containing configuration information for the flow types supported.
+	OpenIDConnectURL string      `json:"openIdConnectUrl,omitempty"` // openIdConnect REQUIRED. OpenId Connect URL to discover OAuth2 configuration values.
+
+	Extensions Extensions `json:"-"` // Specification Extensions, fields starting with x-
+}
+
+// OAuthFlows allows configuration of the supported OAuth Flows.
+type OAuthFlows struct {
+	Implicit          *OAuthFlow `json:"implicit,omitempty"`          // Configuration for the OAuth Implicit flow
+	Password          *OAuthFlow `json:"password,omitempty"`          // Configuration for the OAuth Resource Owner Password flow
+	ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"` // Configuration for the OAuth Client Credentials flow.
+	AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"` // Configuration for the OAuth Authorization Code flow.
+}
+
+// list the configured flows
+func (f *OAuthFlows) list() []*OAuthFlow {
+	if f == nil {
+		return nil
+	}
+	l := make([]*OAuthFlow, 0, 4)
+	for _, flow := range []*OAuthFlow{f.Implicit, f.Password, f.ClientCredentials, f.AuthorizationCode} {
+		if flow != nil {
+			l = append(l, flow)
+		}
+	}
+	return l
+}
+
+// OAuthFlow configuration details for a supported OAuth Flow
+type OAuthFlow struct {
+	AuthorizationURL string            `json:"authorizationUrl,omitempty"` // implicit, authorizationCode REQUIRED. The authorization URL to be used for this flow.
+	TokenURL         string            `json:"tokenUrl,omitempty"`         // password, clientCredentials, authorizationCode REQUIRED. The token URL to be used for this flow.
+	RefreshURL       string            `json:"refreshUrl,omitempty"`       // The URL to be used for obtaining refresh tokens.
+	Scopes           map[string]string `json:"scopes"`                     // REQUIRED. The available scopes for the OAuth2 security scheme. A map between the scope name and a short description for it.
+}
+
+// SecurityRequirement lists the required security schemes to execute an operation.
+// The key is the name of a security scheme and the value is a list of scope names required for the execution.
+// For other security scheme types than oauth2 and openIdConnect the list MUST be empty.
+type SecurityRequirement map[string][]string
+
+// AddSecurityScheme adds a security scheme with the name to the components.
+func (o *OpenAPI) AddSecurityScheme(name string, s SecurityScheme) {
+	if o.Components.SecuritySchemes == nil {
+		o.Components.SecuritySchemes = make(map[string]SecurityScheme)
+	}
+	o.Components.SecuritySchemes[name] = s
+}
+
+// AddCookieAuth adds an apiKey security scheme for a session cookie named cookieName.
+// When global is true the scheme is also required by all operations of the document.
+func (o *OpenAPI) AddCookieAuth(name, cookieName, desc string, global bool) {
+	o.AddSecurityScheme(name, SecurityScheme{
+		Type: "apiKey",
+		In:   "cookie",
+		Name: cookieName,
+		Desc: desc,
+	})
+	if global {
+		o.AddSecurity(name)
+	}
+}
+
+// RemoveSecurityScheme removes the security scheme from the components and from every
+// security requirement of the document, its operations, webhooks and callbacks.
+// An operation whose requirements all used the scheme falls back to the security of the document.
+func (o *OpenAPI) RemoveSecurityScheme(name string) {
+	delete(o.Components.SecuritySchemes, name)
+	o.Security = removeScheme(o.Security, name)
+	var routes func(router Router)
+	routes = func(router Router) {
+		for _, r := range router {
+			r.Security = removeScheme(r.Security, name)
+			for _, cb := range r.Callbacks {
+				routes(cb)
+			}
+		}
+	}
+	routes(o.Paths)
+	routes(o.Webhooks)
+}
+
+// removeScheme removes the scheme from the requirements. A requirement that only contained
+// the scheme is dropped instead of becoming empty, an empty requirement allows anonymous access.
+// The empty requirement of OptionalSecurity is kept. When no requirement is left the list is nil,
+// so an operation uses the security of the document.
+func removeScheme(reqs []SecurityRequirement, name string) []SecurityRequirement {
+	var l []SecurityRequirement
+	for _, req := range reqs {
+		if _, found := req[name]; !found {
+			l = append(l, req)
+			continue
+		}
+		if len(req) == 1 {
+			continue
+		}
+		r := make(SecurityRequirement, len(req)-1)
+		for k, v := range req {
+			if k != name {
+				r[k] = v
+			}
+		}
+		l = append(l, r)
+	}
+	return l
+}
+
+// SetSecurity replaces the security requirements used by all operations of the document.
+func (o *OpenAPI) SetSecurity(reqs ...SecurityRequirement) {
+	o.Security = reqs
+}
+
+// ClearSecurity removes all security requirements of the document.
+func (o *OpenAPI) ClearSecurity() {
+	o.Security = nil
+}
+
+// AddSecurity adds a security requirement used by all operations of the document.
+func (o *OpenAPI) AddSecurity(scheme string, scopes ...string) {
+	o.Security = append(o.Security, newRequirement(scheme, scopes))
+}
+
+// AddSecurity adds a security requirement to the operation, it overrides the security of the document.
+func (r *Route) AddSecurity(scheme string, scopes ...string) *Route {
+	r.Security = append(r.Security, newRequirement(scheme, scopes))
+	return r
+}
+
+// OptionalSecurity allows anonymous access to the operation as well as access with the scheme.
+// It adds an empty security requirement before the requirement of the scheme.
+func (r *Route) OptionalSecurity(scheme string, scopes ...string) *Route {
+	optional := false
+	for _, req := range r.Security {
+		optional = optional || len(req) == 0
+	}
+	if !optional {
+		r.Security = append([]SecurityRequirement{{}}, r.Security...)
+	}
+	return r.AddSecurity(scheme, scopes...)
+}
+
+func newRequirement(scheme string, scopes []string) SecurityRequirement {
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return SecurityRequirement{scheme: scopes}
+}
+
+// RegisterScope adds the scope and its description to every flow of the oauth2 security scheme.
+// The registered scopes are used by Validate to check the scopes of the security requirements.
+func (o *OpenAPI) RegisterScope(scheme, scope, desc string) error {
+	s, found := o.Components.SecuritySchemes[scheme]
+	if !found {
+		return fmt.Errorf("security scheme %q not found", scheme)
+	}
+	flows := s.Flows.list()
+	if len(flows) == 0 {
+		return fmt.Errorf("security scheme %q has no oauth2 flows", scheme)
+	}
+	for _, f := range flows {
+		if f.Scopes == nil {
+			f.Scopes = make(map[string]string)
+		}
+		f.Scopes[scope] = desc
+	}
+	return nil
+}
+
+// validateSecurity checks that the schemes of the requirement are declared
+// and that oauth2 scopes are registered in a flow of the scheme.
+func (o *OpenAPI) validateSecurity(req SecurityRequirement, at string) (errs []error) {
+	for _, name := range sortedKeys(req) {
+		s, found := o.Components.SecuritySchemes[name]
+		if !found {
+			errs = append(errs, fmt.Errorf("%v: security scheme %q is not declared", at, name))
+			continue
+		}
+		switch s.Type {
+		case "oauth2":
+			for _, scope := range req[name] {
+				if !s.hasScope(scope) {
+					errs = append(errs, fmt.Errorf("%v: scope %q is not declared in security scheme %q", at, scope, name))
+				}
+			}
+		case "openIdConnect":
+		default:
+			if len(req[name]) > 0 {
+				errs = append(errs, fmt.Errorf("%v: security scheme %q of type %v must not have scopes", at, name, s.Type))
+			}
+		}
+	}
+	return errs
+}
+
+func (s SecurityScheme) hasScope(scope string) bool {
+	for _, f := range s.Flows.list() {
+		if _, found := f.Scopes[scope]; found {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireSecurity enables a Validate rule that flags operations without any security requirement,
+// neither on the operation nor on the document. Paths matching one of the allow patterns
+// are excluded, patterns use the path.Match syntax e.g. "/health" or "/metrics/*".
+func (o *OpenAPI) RequireSecurity(allow ...string) {
+	o.requireSecurity = true
+	o.securityAllow = allow
+}
+
+// validateCoverage checks that the route has a security requirement when required by the document.
+func (o *OpenAPI) validateCoverage(r *Route) error {
+	if !o.requireSecurity || len(r.Security) > 0 || len(o.Security) > 0 {
+		return nil
+	}
+	for _, pattern := range o.securityAllow {
+		if ok, _ := path.Match(pattern, r.path); ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("%v %v: no security requirement", r.method, r.path)
+}
@@ -0,0 +1,262 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestRegisterScope(t *testing.T) {
+	doc := New("", "", "")
+	doc.AddSecurityScheme("oauth", SecurityScheme{
+		Type: "oauth2",
+		Flows: &OAuthFlows{
+			ClientCredentials: &OAuthFlow{TokenURL: "https://auth.example.com/token"},
+			AuthorizationCode: &OAuthFlow{
+				AuthorizationURL: "https://auth.example.com/authorize",
+				TokenURL:         "https://auth.example.com/token",
+				Scopes:           map[string]string{"read": "read access"},
+			},
+		},
+	})
+	doc.AddSecurityScheme("key", SecurityScheme{Type: "apiKey", Name: "X-API-Key", In: "header"})
+
+	if err := doc.RegisterScope("oauth", "write", "write access"); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.RegisterScope("key", "write", ""); err == nil {
+		t.Error("expected error for scheme without flows")
+	}
+	if err := doc.RegisterScope("missing", "write", ""); err == nil {
+		t.Error("expected error for missing scheme")
+	}
+
+	flows := doc.Components.SecuritySchemes["oauth"].Flows
+	if eq, diff := trial.Equal(flows.ClientCredentials.Scopes, map[string]string{"write": "write access"}); !eq {
+		t.Error(diff)
+	}
+	if eq, diff := trial.Equal(flows.AuthorizationCode.Scopes, map[string]string{"read": "read access", "write": "write access"}); !eq {
+		t.Error(diff)
+	}
+}
+
+func TestValidateSecurity(t *testing.T) {
+	fn := func(r *Route) (bool, error) {
+		doc := New("", "", "")
+		doc.AddSecurityScheme("oauth", SecurityScheme{
+			Type:  "oauth2",
+			Flows: &OAuthFlows{Implicit: &OAuthFlow{AuthorizationURL: "https://auth.example.com"}},
+		})
+		doc.AddSecurityScheme("key", SecurityScheme{Type: "apiKey", Name: "key", In: "query"})
+		if err := doc.RegisterScope("oauth", "read", ""); err != nil {
+			return false, err
+		}
+		doc.AddSecurity("key")
+		doc.Paths[r.key()] = r
+		return true, doc.Validate()
+	}
+	cases := trial.Cases[*Route, bool]{
+		"valid": {
+			Input:    (&Route{path: "/a", method: "get"}).AddSecurity("oauth", "read"),
+			Expected: true,
+		},
+		"undeclared_scope": {
+			Input:       (&Route{path: "/a", method: "get"}).AddSecurity("oauth", "read", "admin"),
+			ExpectedErr: errors.New(`get /a: scope "admin" is not declared in security scheme "oauth"`),
+		},
+		"undeclared_scheme": {
+			Input:       (&Route{path: "/a", method: "get"}).AddSecurity("basic"),
+			ExpectedErr: errors.New(`get /a: security scheme "basic" is not declared`),
+		},
+		"apikey_scopes": {
+			Input:       (&Route{path: "/a", method: "get"}).AddSecurity("key", "read"),
+			ExpectedErr: errors.New(`get /a: security scheme "key" of type apiKey must not have scopes`),
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
+func TestOptionalSecurity(t *testing.T) {
+	r := (&Route{}).
+		OptionalSecurity("oauth", "read").
+		OptionalSecurity("key")
+	b, err := json.Marshal(r.Security)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(string(b), `[{},{"oauth":["read"]},{"key":[]}]`); !eq {
+		t.Error(diff)
+	}
+}
+
+func TestRemoveSecurityScheme(t *testing.T) {
+	doc := New("", "", "")
+	doc.AddSecurityScheme("basic", SecurityScheme{Type: "http", Scheme: "basic"})
+	doc.AddSecurityScheme("key", SecurityScheme{Type: "apiKey", Name: "key", In: "header"})
+	doc.SetSecurity(SecurityRequirement{"basic": {}}, SecurityRequirement{"basic": {}, "key": {}})
+	route := doc.GetRoute("/a", "get").AddSecurity("basic").OptionalSecurity("key")
+
+	doc.RemoveSecurityScheme("basic")
+	if _, found := doc.Components.SecuritySchemes["basic"]; found {
+		t.Error("expected scheme to be removed")
+	}
+	if eq, diff := trial.Equal(doc.Security, []SecurityRequirement{{"key": {}}}); !eq {
+		t.Error(diff)
+	}
+	if eq, diff := trial.Equal(route.Security, []SecurityRequirement{{}, {"key": {}}}); !eq {
+		t.Error(diff)
+	}
+	if err := doc.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	doc.ClearSecurity()
+	if doc.Security != nil {
+		t.Errorf("expected no security got %v", doc.Security)
+	}
+}
+
+func TestRemoveSecuritySchemeRoutes(t *testing.T) {
+	doc := New("", "", "")
+	doc.AddSecurityScheme("basic", SecurityScheme{Type: "http", Scheme: "basic"})
+	doc.AddSecurityScheme("key", SecurityScheme{Type: "apiKey", Name: "key", In: "header"})
+	doc.GetRoute("/only", "get").AddSecurity("basic")
+	doc.GetRoute("/both", "get").AddSecurity("basic").AddSecurity("key")
+	doc.GetRoute("/optional", "get").OptionalSecurity("basic")
+	doc.GetWebhook("created", "post").AddSecurity("basic").AddSecurity("key")
+	doc.GetRoute("/subscribe", "post").Callback("event", "{$request.body#/url}", "post").AddSecurity("basic")
+	doc.RemoveSecurityScheme("basic")
+
+	fn := func(r *Route) ([]SecurityRequirement, error) {
+		return r.Security, nil
+	}
+	cases := trial.Cases[*Route, []SecurityRequirement]{
+		"only the scheme": {
+			Input:    doc.GetRoute("/only", "get"),
+			Expected: nil,
+		},
+		"other requirement": {
+			Input:    doc.GetRoute("/both", "get"),
+			Expected: []SecurityRequirement{{"key": {}}},
+		},
+		"optional": {
+			Input:    doc.GetRoute("/optional", "get"),
+			Expected: []SecurityRequirement{{}},
+		},
+		"webhook": {
+			Input:    doc.GetWebhook("created", "post"),
+			Expected: []SecurityRequirement{{"key": {}}},
+		},
+		"callback": {
+			Input:    doc.GetRoute("/subscribe", "post").Callbacks["event"]["{$request.body#/url}|post"],
+			Expected: nil,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
+func TestAddCookieAuth(t *testing.T) {
+	doc := New("", "", "")
+	doc.AddCookieAuth("session", "SESSIONID", "login session", true)
+	doc.AddCookieAuth("csrf", "XSRF-TOKEN", "", false)
+
+	eq, diff := trial.Equal(doc.Components.SecuritySchemes, map[string]SecurityScheme{
+		"session": {Type: "apiKey", In: "cookie", Name: "SESSIONID", Desc: "login session"},
+		"csrf":    {Type: "apiKey", In: "cookie", Name: "XSRF-TOKEN"},
+	})
+	if !eq {
+		t.Error(diff)
+	}
+	if eq, diff := trial.Equal(doc.Security, []SecurityRequirement{{"session": {}}}); !eq {
+		t.Error(diff)
+	}
+}
+
+func TestAddOpenIDConnectAuthFromDiscovery(t *testing.T) {
+	issuer := "https://idp.example.com"
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/.well-known/openid-configuration" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{
+			"issuer": "` + issuer + `",
+			"authorization_endpoint": "https://idp.example.com/authorize",
+			"token_endpoint": "https://idp.example.com/token",
+			"scopes_supported": ["openid", "email"],
+			"grant_types_supported": ["authorization_code", "client_credentials", "refresh_token"]
+		}`))
+	}))
+	defer srv.Close()
+	issuer = srv.URL
+
+	doc := New("", "", "")
+	c, err := doc.AddOpenIDConnectAuthFromDiscovery(context.Background(), srv.Client(), "oidc", srv.URL+"/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request with the client got %d", requests)
+	}
+	eq, diff := trial.Equal(doc.Components.SecuritySchemes["oidc"], SecurityScheme{
+		Type:             "openIdConnect",
+		OpenIDConnectURL: srv.URL + "/.well-known/openid-configuration",
+	})
+	if !eq {
+		t.Error(diff)
+	}
+
+	scopes := map[string]string{"openid": "", "email": ""}
+	eq, diff = trial.Equal(c.Flows(), &OAuthFlows{
+		AuthorizationCode: &OAuthFlow{
+			AuthorizationURL: "https://idp.example.com/authorize",
+			TokenURL:         "https://idp.example.com/token",
+			Scopes:           scopes,
+		},
+		ClientCredentials: &OAuthFlow{TokenURL: "https://idp.example.com/token", Scopes: scopes},
+	})
+	if !eq {
+		t.Error(diff)
+	}
+
+	if _, err := doc.AddOpenIDConnectAuthFromDiscovery(context.Background(), nil, "bad", srv.URL+"/missing"); err == nil {
+		t.Error("expected error for missing discovery document")
+	}
+
+	// the issuer of the configuration must be the requested issuer
+	issuer = "https://evil.example.com"
+	if _, err := doc.AddOpenIDConnectAuthFromDiscovery(context.Background(), nil, "other", srv.URL); err == nil {
+		t.Error("expected error for a mismatched issuer")
+	}
+	if _, found := doc.Components.SecuritySchemes["other"]; found {
+		t.Error("expected no security scheme for a mismatched issuer")
+	}
+}
+
+func TestRequireSecurity(t *testing.T) {
+	doc := New("", "", "")
+	doc.AddSecurityScheme("key", SecurityScheme{Type: "apiKey", Name: "key", In: "header"})
+	doc.RequireSecurity("/health", "/metrics/*")
+	doc.GetRoute("/health", "get")
+	doc.GetRoute("/metrics/cpu", "get")
+	doc.GetRoute("/users", "get").AddSecurity("key")
+	doc.GetRoute("/users", "post")
+
+	err := doc.Validate()
+	if eq, diff := trial.Equal(err.Error(), "post /users: no security requirement"); !eq {
+		t.Error(diff)
+	}
+
+	// document security covers all operations
+	doc.AddSecurity("key")
+	if err := doc.Validate(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,61 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+)
+
+// AddEnvironments adds a server with an env variable that selects the environment.
+// envs maps the name of an environment to its base URL. When the URLs only differ by
+// the name of the environment, such as https://dev.example.com and https://prod.example.com,
+// the name is the value of the variable: https://{env}.example.com.
+// Otherwise the variable is the whole URL and its description lists the environments.
+// The first environment by name is the default.
+//
+//	doc.AddEnvironments(map[string]string{"dev": "https://dev.example.com", "prod": "https://prod.example.com"})
+func (o *OpenAPI) AddEnvironments(envs map[string]string) {
+	if len(envs) == 0 {
+		return
+	}
+	names := sortedKeys(envs)
+	if tmpl, ok := envTemplate(envs, names); ok {
+		o.Servers = append(o.Servers, Server{
+			URL:  tmpl,
+			Desc: "environment",
+			Vars: map[string]ServerVar{"env": {Enum: names, Default: names[0], Desc: "the environment"}},
+		})
+		return
+	}
+
+	urls := make([]string, len(names))
+	desc := make([]string, len(names))
+	for i, name := range names {
+		urls[i] = envs[name]
+		desc[i] = name + ": " + envs[name]
+	}
+	def := urls[0]
+	sort.Strings(urls)
+	o.Servers = append(o.Servers, Server{
+		URL:  "{env}",
+		Desc: "environment",
+		Vars: map[string]ServerVar{"env": {Enum: urls, Default: def, Desc: strings.Join(desc, ", ")}},
+	})
+}
+
+// envTemplate returns the URL with the name of the environment replaced by {env}
+// if it is the same for all environments.
+func envTemplate(envs map[string]string, names []string) (string, bool) {
+	var tmpl string
+	for _, name := range names {
+		url := envs[name]
+		if strings.Count(url, name) != 1 {
+			return "", false
+		}
+		t := strings.Replace(url, name, "{env}", 1)
+		if tmpl != "" && t != tmpl {
+			return "", false
+		}
+		tmpl = t
+	}
+	return tmpl, true
+}
@@ -0,0 +1,54 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestAddEnvironments(t *testing.T) {
+	fn := func(envs map[string]string) ([]Server, error) {
+		doc := New("", "", "")
+		doc.AddEnvironments(envs)
+		return doc.Servers, nil
+	}
+	cases := trial.Cases[map[string]string, []Server]{
+		"template": {
+			Input: map[string]string{"prod": "https://prod.example.com/v1", "dev": "https://dev.example.com/v1", "staging": "https://staging.example.com/v1"},
+			Expected: []Server{{
+				URL:  "https://{env}.example.com/v1",
+				Desc: "environment",
+				Vars: map[string]ServerVar{"env": {Enum: []string{"dev", "prod", "staging"}, Default: "dev", Desc: "the environment"}},
+			}},
+		},
+		"different urls": {
+			Input: map[string]string{"prod": "https://api.example.com", "dev": "http://localhost:8080"},
+			Expected: []Server{{
+				URL:  "{env}",
+				Desc: "environment",
+				Vars: map[string]ServerVar{"env": {
+					Enum:    []string{"http://localhost:8080", "https://api.example.com"},
+					Default: "http://localhost:8080",
+					Desc:    "dev: http://localhost:8080, prod: https://api.example.com",
+				}},
+			}},
+		},
+		"name repeated in url": {
+			Input: map[string]string{"api": "https://api.example.com/api", "eu": "https://eu.example.com/api"},
+			Expected: []Server{{
+				URL:  "{env}",
+				Desc: "environment",
+				Vars: map[string]ServerVar{"env": {
+					Enum:    []string{"https://api.example.com/api", "https://eu.example.com/api"},
+					Default: "https://api.example.com/api",
+					Desc:    "api: https://api.example.com/api, eu: https://eu.example.com/api",
+				}},
+			}},
+		},
+		"empty": {
+			Input:    map[string]string{},
+			Expected: nil,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
@@ -0,0 +1,180 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Dialect31 is the default JSON Schema dialect of OpenAPI 3.1 documents
+const Dialect31 = "https://spec.openapis.org/oas/3.1/dialect/base"
+
+// SetSpecVersion sets the OpenAPI version of the document, 3.0.x or 3.1.x.
+// Documents of version 3.1 are written with the 3.1 semantics:
+// the jsonSchemaDialect is set, nullable schemas have a type array
+// such as ["string", "null"] and the example of a schema is written as examples.
+func (o *OpenAPI) SetSpecVersion(version string) error {
+	switch {
+	case strings.HasPrefix(version, "3.0."):
+		o.JSONSchemaDialect = ""
+	case strings.HasPrefix(version, "3.1."):
+		if o.JSONSchemaDialect == "" {
+			o.JSONSchemaDialect = Dialect31
+		}
+	default:
+		return fmt.Errorf("unsupported OpenAPI version %q", version)
+	}
+	o.Version = version
+	return nil
+}
+
+// is31 reports if the document is written with the 3.1 semantics
+func (o *OpenAPI) is31() bool {
+	return strings.HasPrefix(o.Version, "3.1.")
+}
+
+// jsonField is a field of a json object
+type jsonField struct {
+	key   string
+	value json.RawMessage
+}
+
+// objectFields returns the fields of the json object b in order
+func objectFields(b []byte) ([]jsonField, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	var fields []jsonField
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		fields = append(fields, jsonField{key: t.(string), value: raw})
+	}
+	return fields, nil
+}
+
+// to31 converts the schemas found in the compact json b to 3.1, isSchema is set when b is a schema.
+// The order of the fields is kept and values of examples, enums, defaults and extensions are written as is.
+func to31(b []byte, isSchema bool) ([]byte, error) {
+	if len(b) == 0 || (b[0] != '{' && b[0] != '[') {
+		return b, nil
+	}
+	if b[0] == '[' {
+		var items []json.RawMessage
+		if err := json.Unmarshal(b, &items); err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for i, raw := range items {
+			v, err := to31(raw, isSchema)
+			if err != nil {
+				return nil, err
+			}
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(v)
+		}
+		buf.WriteByte(']')
+		return buf.Bytes(), nil
+	}
+
+	fields, err := objectFields(b)
+	if err != nil {
+		return nil, err
+	}
+	// nullable is replaced by the null type, a nullable $ref by the anyOf of the $ref and the null type.
+	// It is kept on the other schemas without a type such as an allOf.
+	nullable, typed, ref := false, false, false
+	if isSchema {
+		for _, f := range fields {
+			nullable = nullable || (f.key == "nullable" && string(f.value) == "true")
+			typed = typed || f.key == "type"
+			ref = ref || f.key == "$ref"
+		}
+	}
+	nullRef := nullable && ref && !typed
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	n := 0
+	for _, f := range fields {
+		key, v := f.key, []byte(f.value)
+		switch {
+		case strings.HasPrefix(key, "x-"):
+		case isSchema && key == "nullable" && (typed || nullRef):
+			continue
+		case nullRef && key == "$ref":
+			key, v = "anyOf", append(append([]byte(`[{"$ref":`), v...), []byte(`},{"type":"null"}]`)...)
+		case isSchema && key == "type" && nullable:
+			v = append(append([]byte{'['}, v...), []byte(`,"null"]`)...)
+		case isSchema && key == "example":
+			key, v = "examples", append(append([]byte{'['}, v...), ']')
+		case isSchema && (key == "items" || key == "not" || key == "additionalProperties" ||
+			key == "allOf" || key == "anyOf" || key == "oneOf"):
+			if v, err = to31(v, true); err != nil {
+				return nil, err
+			}
+		case isSchema && key == "properties", !isSchema && key == "schemas":
+			if v, err = schemaMap(v); err != nil {
+				return nil, err
+			}
+		case !isSchema && key == "schema":
+			if v, err = to31(v, true); err != nil {
+				return nil, err
+			}
+		case key == "value", key == "example", key == "enum", key == "default", key == "const":
+		default:
+			if v, err = to31(v, false); err != nil {
+				return nil, err
+			}
+		}
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		n++
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// schemaMap converts the schemas of the json object b such as properties to 3.1
+func schemaMap(b []byte) ([]byte, error) {
+	if len(b) == 0 || b[0] != '{' {
+		return b, nil
+	}
+	fields, err := objectFields(b)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range fields {
+		v, err := to31(f.value, true)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(f.key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,96 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestSetSpecVersion(t *testing.T) {
+	fn := func(version string) (string, error) {
+		doc := New("", "", "")
+		if err := doc.SetSpecVersion(version); err != nil {
+			return "", err
+		}
+		b, err := doc.JSONWith(MarshalOptions{})
+		return string(b), err
+	}
+	cases := trial.Cases[string, string]{
+		"3.0": {
+			Input:    "3.0.3",
+			Expected: `{"openapi":"3.0.3","info":{"title":"","version":"","description":""},"paths":{},"components":{}}`,
+		},
+		"3.1": {
+			Input:    "3.1.0",
+			Expected: `{"openapi":"3.1.0","info":{"title":"","version":"","description":""},"jsonSchemaDialect":"https://spec.openapis.org/oas/3.1/dialect/base","paths":{},"components":{}}`,
+		},
+		"unsupported": {
+			Input:     "2.0",
+			ShouldErr: true,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
+func TestTo31(t *testing.T) {
+	fn := func(in string) (string, error) {
+		b, err := to31([]byte(in), false)
+		return string(b), err
+	}
+	cases := trial.Cases[string, string]{
+		"nullable": {
+			Input:    `{"schema":{"type":"string","nullable":true}}`,
+			Expected: `{"schema":{"type":["string","null"]}}`,
+		},
+		"nullable ref": {
+			Input:    `{"schema":{"$ref":"#/components/schemas/user","nullable":true,"description":"the owner"}}`,
+			Expected: `{"schema":{"anyOf":[{"$ref":"#/components/schemas/user"},{"type":"null"}],"description":"the owner"}}`,
+		},
+		"nullable property ref": {
+			Input:    `{"schema":{"type":"object","properties":{"owner":{"nullable":true,"$ref":"#/components/schemas/user"}}}}`,
+			Expected: `{"schema":{"type":"object","properties":{"owner":{"anyOf":[{"$ref":"#/components/schemas/user"},{"type":"null"}]}}}}`,
+		},
+		"example": {
+			Input:    `{"schema":{"type":"integer","example":5}}`,
+			Expected: `{"schema":{"type":"integer","examples":[5]}}`,
+		},
+		"nested": {
+			Input:    `{"components":{"schemas":{"user":{"type":"object","properties":{"tags":{"type":"array","items":{"type":"string","nullable":true,"example":"a"}}}}}}}`,
+			Expected: `{"components":{"schemas":{"user":{"type":"object","properties":{"tags":{"type":"array","items":{"type":["string","null"],"examples":["a"]}}}}}}}`,
+		},
+		"example values": {
+			Input:    `{"examples":{"user":{"value":{"schema":{"nullable":true,"type":"string"}}}},"x-schema":{"schema":{"example":1}}}`,
+			Expected: `{"examples":{"user":{"value":{"schema":{"nullable":true,"type":"string"}}}},"x-schema":{"schema":{"example":1}}}`,
+		},
+		"params": {
+			Input:    `{"parameters":[{"name":"id","in":"path","example":"1","schema":{"type":"string","example":"1"}}]}`,
+			Expected: `{"parameters":[{"name":"id","in":"path","example":"1","schema":{"type":"string","examples":["1"]}}]}`,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
+func TestSpec31RoundTrip(t *testing.T) {
+	doc := New("", "", "")
+	if err := doc.SetSpecVersion("3.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	doc.Components.Schemas = map[string]Schema{
+		"name": {Type: String, Nullable: true, Example: "bob"},
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc2, err := NewFromJson(string(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(doc2.Components.Schemas, doc.Components.Schemas); !eq {
+		t.Error(diff)
+	}
+	if doc2.JSONSchemaDialect != Dialect31 {
+		t.Errorf("dialect %q", doc2.JSONSchemaDialect)
+	}
+}
@@ -0,0 +1,179 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SplitOptions control the files written by WriteSplitWith
+type SplitOptions struct {
+	MarshalOptions      // format of the json of each file
+	YAML           bool // write the files as yaml with the .yaml extension instead of json
+}
+
+// WriteSplit writes the OpenAPI object as multiple json files into dir.
+// Each path is written to paths/{path}.json and each component schema to
+// components/schemas/{name}.json, the root openapi.json references them with relative $refs.
+// Compile should be called first so schemas are consolidated into the components.
+// The files are encoded like WriteJSON, see WriteSplitWith for other formats.
+func (o *OpenAPI) WriteSplit(dir string) error {
+	return o.WriteSplitWith(dir, SplitOptions{MarshalOptions: defaultMarshal})
+}
+
+// WriteSplitWith is WriteSplit with the files encoded with the options,
+// a split document is read back as a single document with Resolver.Load.
+//
+//	doc.WriteSplitWith("api", openapi.SplitOptions{YAML: true}) // api/openapi.yaml
+func (o *OpenAPI) WriteSplitWith(dir string, opts SplitOptions) error {
+	ext := ".json"
+	if opts.YAML {
+		ext = ".yaml"
+	}
+	schemaDir := filepath.Join(dir, "components", "schemas")
+	pathDir := filepath.Join(dir, "paths")
+	for _, d := range []string{schemaDir, pathDir} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			return err
+		}
+	}
+	write := func(file string, b []byte) error {
+		if opts.YAML {
+			var err error
+			if b, err = yamlBytes(b); err != nil {
+				return fmt.Errorf("%v: %w", file, err)
+			}
+		}
+		return os.WriteFile(file, b, 0o644)
+	}
+	// each file is encoded like the same part of WriteJSON
+	e := &jsonWriter{opts: opts.MarshalOptions, spec31: o.is31()}
+
+	// components/schemas/{name}.json
+	schemaFiles := make(map[string]string)
+	used := make(map[string]bool)
+	names := sortedKeys(o.Components.Schemas)
+	for _, name := range names {
+		schemaFiles[name] = uniqueFile(name, ext, used)
+	}
+	refs := make(map[string]Schema, len(schemaFiles))
+	for _, name := range names {
+		b := e.encode(o.Components.Schemas[name], 0)
+		if e.err != nil {
+			return fmt.Errorf("schema %q: %w", name, e.err)
+		}
+		b = splitRefs(b, "", "../../openapi"+ext, schemaFiles)
+		if err := write(filepath.Join(schemaDir, schemaFiles[name]), b); err != nil {
+			return err
+		}
+		refs[name] = Schema{Ref: "components/schemas/" + schemaFiles[name]}
+	}
+
+	// paths/{path}.json
+	paths := make(map[string]map[string]*Route)
+	for k, r := range o.Paths {
+		path, method, _ := strings.Cut(k, "|")
+		if paths[path] == nil {
+			paths[path] = make(map[string]*Route)
+		}
+		paths[path][method] = r
+	}
+	pathRefs := make(map[string]string, len(paths))
+	used = make(map[string]bool)
+	for _, path := range sortedKeys(paths) {
+		name := strings.Trim(path, "/")
+		if name == "" {
+			name = "root"
+		}
+		file := uniqueFile(strings.NewReplacer("/", "_", "{", "", "}", "").Replace(name), ext, used)
+		b := e.encode(paths[path], 0)
+		if e.err != nil {
+			return fmt.Errorf("path %q: %w", path, e.err)
+		}
+		b = splitRefs(b, "../components/schemas/", "../openapi"+ext, schemaFiles)
+		if err := write(filepath.Join(pathDir, file), b); err != nil {
+			return err
+		}
+		pathRefs[path] = "paths/" + file
+	}
+
+	// openapi.json
+	root := *o
+	root.Components.Schemas = refs
+	var buf bytes.Buffer
+	if err := root.writeJSON(&buf, pathRefs, opts.MarshalOptions); err != nil {
+		return err
+	}
+	return write(filepath.Join(dir, "openapi"+ext), buf.Bytes())
+}
+
+var regexComponentRef = regexp.MustCompile(`("\$ref":\s*)"#/components/([^/"]+)/([^"]+)"`)
+
+// splitRefs rewrites the local component $refs in b so they can be resolved from a split file.
+// schema refs point to their own file in schemaDir, all other components remain in the root document.
+func splitRefs(b []byte, schemaDir, root string, schemaFiles map[string]string) []byte {
+	return regexComponentRef.ReplaceAllFunc(b, func(ref []byte) []byte {
+		m := regexComponentRef.FindSubmatch(ref)
+		key, kind, name := string(m[1]), string(m[2]), string(m[3])
+		if file, found := schemaFiles[name]; found && kind == "schemas" {
+			return []byte(key + `"` + schemaDir + file + `"`)
+		}
+		return []byte(key + `"` + root + `#/components/` + kind + "/" + name + `"`)
+	})
+}
+
+// yamlBytes converts the json b to yaml with the fields in the same order
+func yamlBytes(b []byte) ([]byte, error) {
+	var n yaml.Node
+	if err := yaml.Unmarshal(b, &n); err != nil {
+		return nil, err
+	}
+	plainStyle(&n)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&n); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// plainStyle removes the json flow style and quotes of the yaml nodes,
+// strings that would be read as another type are still quoted by the encoder.
+func plainStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		plainStyle(c)
+	}
+}
+
+var regexFileName = regexp.MustCompile(`[^a-zA-Z0-9_.\-]+`)
+
+// uniqueFile creates a file name with the extension from name that is not already in used.
+func uniqueFile(name, ext string, used map[string]bool) string {
+	name = strings.Trim(regexFileName.ReplaceAllString(name, "_"), "_")
+	file := name + ext
+	for i := 1; used[file]; i++ {
+		file = fmt.Sprintf("%s_%d%s", name, i, ext)
+	}
+	used[file] = true
+	return file
+}
+
+func sortedKeys[K ~string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
@@ -0,0 +1,51 @@
+package openapi
+
+// Stats describes the size of the document
+type Stats struct {
+	Routes   int // number of operations (path and method)
+	Schemas  int // number of component schemas
+	Examples int // number of request, response and param examples
+	Bytes    int // size of the json document
+}
+
+// Stats counts the routes, schemas and examples of the document
+// and the size of its json output, which is encoded without being stored.
+func (o *OpenAPI) Stats() Stats {
+	s := Stats{
+		Routes:  len(o.Paths),
+		Schemas: len(o.Components.Schemas),
+	}
+	for _, r := range o.Paths {
+		if r.Requests != nil {
+			for _, m := range r.Requests.Content {
+				s.Examples += len(m.Examples)
+			}
+		}
+		for _, resp := range r.Responses {
+			for _, m := range resp.Content {
+				s.Examples += len(m.Examples)
+			}
+		}
+		for _, p := range r.Params {
+			s.Examples += len(p.Examples)
+			for _, m := range p.Content {
+				s.Examples += len(m.Examples)
+			}
+		}
+	}
+	w := &countWriter{}
+	if err := o.WriteJSON(w); err == nil {
+		s.Bytes = w.n
+	}
+	return s
+}
+
+// countWriter counts the bytes written to it
+type countWriter struct {
+	n int
+}
+
+func (w *countWriter) Write(b []byte) (int, error) {
+	w.n += len(b)
+	return len(b), nil
+}
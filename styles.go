@@ -0,0 +1,87 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Style describes how a parameter value is serialized
+type Style string
+
+const (
+	StyleForm           Style = "form"           // ids=1&ids=2, or ids=1,2 without explode
+	StyleSpaceDelimited Style = "spaceDelimited" // ids=1%202
+	StylePipeDelimited  Style = "pipeDelimited"  // ids=1|2
+	StyleDeepObject     Style = "deepObject"     // filter[status]=active
+)
+
+// QueryArrayParam adds an array query param serialized with the style.
+// examples is a slice of primitive values used as one example, or a slice of
+// such slices for multiple examples. Each example is named by its query string.
+// The space and pipe delimited styles are only defined without explode.
+//
+//	r.QueryArrayParam("ids", []int{1, 2, 3}, openapi.StylePipeDelimited, false) // ids=1|2|3
+func (r *Route) QueryArrayParam(name string, examples any, style Style, explode bool) *Route {
+	if r.Params == nil {
+		r.Params = make(Params)
+	}
+	r.compiled = false
+	p := Param{In: "query", Name: name, Style: style, Explode: &explode, Examples: make(map[string]Example)}
+	defer func() { r.Params["query|"+name] = p }()
+
+	switch style {
+	case StyleForm:
+	case StyleSpaceDelimited, StylePipeDelimited:
+		if explode {
+			p.Desc = fmt.Sprintf("err: %v is not defined with explode", style)
+			return r
+		}
+	default:
+		p.Desc = fmt.Sprintf("err: invalid array style %v", style)
+		return r
+	}
+
+	val := reflect.ValueOf(examples)
+	if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+		p.Desc = "err: invalid param, examples must be a slice"
+		return r
+	}
+	list, elemType := []reflect.Value{val}, val.Type().Elem()
+	if k := elemType.Kind(); k == reflect.Slice || k == reflect.Array {
+		list, elemType = make([]reflect.Value, val.Len()), elemType.Elem()
+		for i := range list {
+			list[i] = val.Index(i)
+		}
+	}
+	elem := reflect.New(elemType).Elem().Interface()
+	if !isPrimitive(elem) {
+		p.Desc = "err: invalid param, slice elem must be primitive"
+		return r
+	}
+	items := r.buildSchema(elem)
+	p.Schema = &Schema{Type: Array, Items: &items}
+	for _, ex := range list {
+		values := make([]string, ex.Len())
+		for i := range values {
+			values[i] = fmt.Sprint(ex.Index(i).Interface())
+		}
+		p.Examples[arrayQuery(name, values, style, explode)] = Example{Value: ex.Interface()}
+	}
+	return r
+}
+
+// arrayQuery returns the query string of the values serialized with the style
+func arrayQuery(name string, values []string, style Style, explode bool) string {
+	if explode {
+		return name + "=" + strings.Join(values, "&"+name+"=")
+	}
+	sep := ","
+	switch style {
+	case StyleSpaceDelimited:
+		sep = "%20"
+	case StylePipeDelimited:
+		sep = "|"
+	}
+	return name + "=" + strings.Join(values, sep)
+}
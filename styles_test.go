@@ -0,0 +1,65 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestQueryArrayParam(t *testing.T) {
+	type input struct {
+		examples any
+		style    Style
+		explode  bool
+	}
+	fn := func(in input) (Param, error) {
+		r := &Route{}
+		r.QueryArrayParam("ids", in.examples, in.style, in.explode)
+		return r.Params["query|ids"], nil
+	}
+	intArray := &Schema{Type: Array, Items: &Schema{Type: Integer}}
+	cases := trial.Cases[input, Param]{
+		"form explode": {
+			Input: input{examples: []int{1, 2}, style: StyleForm, explode: true},
+			Expected: Param{In: "query", Name: "ids", Style: StyleForm, Explode: trial.BoolP(true), Schema: intArray,
+				Examples: map[string]Example{"ids=1&ids=2": {Value: []int{1, 2}}}},
+		},
+		"form": {
+			Input: input{examples: []int{1, 2}, style: StyleForm},
+			Expected: Param{In: "query", Name: "ids", Style: StyleForm, Explode: trial.BoolP(false), Schema: intArray,
+				Examples: map[string]Example{"ids=1,2": {Value: []int{1, 2}}}},
+		},
+		"space": {
+			Input: input{examples: []int{1, 2}, style: StyleSpaceDelimited},
+			Expected: Param{In: "query", Name: "ids", Style: StyleSpaceDelimited, Explode: trial.BoolP(false), Schema: intArray,
+				Examples: map[string]Example{"ids=1%202": {Value: []int{1, 2}}}},
+		},
+		"pipe with multiple examples": {
+			Input: input{examples: [][]string{{"a", "b"}, {"c"}}, style: StylePipeDelimited},
+			Expected: Param{In: "query", Name: "ids", Style: StylePipeDelimited, Explode: trial.BoolP(false),
+				Schema:   &Schema{Type: Array, Items: &Schema{Type: String}},
+				Examples: map[string]Example{"ids=a|b": {Value: []string{"a", "b"}}, "ids=c": {Value: []string{"c"}}}},
+		},
+		"pipe explode": {
+			Input: input{examples: []int{1}, style: StylePipeDelimited, explode: true},
+			Expected: Param{In: "query", Name: "ids", Style: StylePipeDelimited, Explode: trial.BoolP(true),
+				Desc: "err: pipeDelimited is not defined with explode", Examples: map[string]Example{}},
+		},
+		"deepObject": {
+			Input: input{examples: []int{1}, style: StyleDeepObject},
+			Expected: Param{In: "query", Name: "ids", Style: StyleDeepObject, Explode: trial.BoolP(false),
+				Desc: "err: invalid array style deepObject", Examples: map[string]Example{}},
+		},
+		"not a slice": {
+			Input: input{examples: 1, style: StyleForm},
+			Expected: Param{In: "query", Name: "ids", Style: StyleForm, Explode: trial.BoolP(false),
+				Desc: "err: invalid param, examples must be a slice", Examples: map[string]Example{}},
+		},
+		"objects": {
+			Input: input{examples: []map[string]int{}, style: StyleForm},
+			Expected: Param{In: "query", Name: "ids", Style: StyleForm, Explode: trial.BoolP(false),
+				Desc: "err: invalid param, slice elem must be primitive", Examples: map[string]Example{}},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
@@ -0,0 +1,1075 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// NewFromSwagger2 converts a Swagger 2.0 json spec into an OpenAPI 3.0 document.
+// The definitions, parameters, responses and security definitions become components,
+// body and form parameters become request bodies and the host, basePath and schemes become servers.
+//
+//	b, _ := os.ReadFile("swagger.json")
+//	doc, err := openapi.NewFromSwagger2(string(b))
+func NewFromSwagger2(spec string) (*OpenAPI, error) {
+	var src map[string]any
+	if err := json.Unmarshal([]byte(spec), &src); err != nil {
+		return nil, fmt.Errorf("error with unmarshal %w", err)
+	}
+	if v, _ := src["swagger"].(string); !strings.HasPrefix(v, "2.") {
+		return nil, fmt.Errorf("unsupported swagger version %q", v)
+	}
+	c := swagger2{
+		src:      src,
+		consumes: stringList(src["consumes"]),
+		produces: stringList(src["produces"]),
+		bodies:   make(map[string]bool),
+	}
+	b, err := json.Marshal(c.convert())
+	if err != nil {
+		return nil, err
+	}
+	return NewFromJson(string(b))
+}
+
+// IsSwagger2 reports if the json spec is a Swagger 2.0 document
+func IsSwagger2(spec []byte) bool {
+	var v struct {
+		Swagger string `json:"swagger"`
+	}
+	return json.Unmarshal(spec, &v) == nil && strings.HasPrefix(v.Swagger, "2.")
+}
+
+// swagger2 converts the generic json of a Swagger 2.0 document
+type swagger2 struct {
+	src      map[string]any
+	consumes []string        // default mime types of the requests
+	produces []string        // default mime types of the responses
+	bodies   map[string]bool // global parameters that are request bodies
+}
+
+func (c swagger2) convert() map[string]any {
+	doc := map[string]any{
+		"openapi": "3.0.3",
+		"info":    c.src["info"],
+		"paths":   map[string]any{},
+	}
+	for _, k := range []string{"tags", "security", "externalDocs"} {
+		if v, found := c.src[k]; found {
+			doc[k] = v
+		}
+	}
+	for k, v := range c.src {
+		if strings.HasPrefix(k, "x-") {
+			doc[k] = v
+		}
+	}
+	if servers := c.servers(); len(servers) > 0 {
+		doc["servers"] = servers
+	}
+
+	components := map[string]any{}
+	if defs, ok := c.src["definitions"].(map[string]any); ok {
+		schemas := map[string]any{}
+		for name, s := range defs {
+			schemas[name] = convertSchema(s)
+		}
+		components["schemas"] = schemas
+	}
+	if params, ok := c.src["parameters"].(map[string]any); ok {
+		parameters, bodies := map[string]any{}, map[string]any{}
+		for name, v := range params {
+			p, _ := v.(map[string]any)
+			switch p["in"] {
+			case "body":
+				c.bodies[name] = true
+				bodies[name] = c.requestBody([]map[string]any{p}, c.consumes)
+			case "formData":
+				c.bodies[name] = true
+				bodies[name] = c.requestBody([]map[string]any{p}, c.consumes)
+			default:
+				parameters[name] = convertParam(p)
+			}
+		}
+		if len(parameters) > 0 {
+			components["parameters"] = parameters
+		}
+		if len(bodies) > 0 {
+			components["requestBodies"] = bodies
+		}
+	}
+	if resps, ok := c.src["responses"].(map[string]any); ok {
+		responses := map[string]any{}
+		for name, v := range resps {
+			responses[name] = c.response(v, c.produces)
+		}
+		components["responses"] = responses
+	}
+	if defs, ok := c.src["securityDefinitions"].(map[string]any); ok {
+		schemes := map[string]any{}
+		for name, v := range defs {
+			schemes[name] = convertSecurity(v)
+		}
+		components["securitySchemes"] = schemes
+	}
+	doc["components"] = components
+
+	paths, _ := c.src["paths"].(map[string]any)
+	for path, v := range paths {
+		item, _ := v.(map[string]any)
+		shared := paramList(item["parameters"])
+		ops := map[string]any{}
+		for method, v := range item {
+			op, ok := v.(map[string]any)
+			if !ok || method == "parameters" || strings.HasPrefix(method, "x-") {
+				continue
+			}
+			ops[method] = c.operation(op, shared)
+		}
+		doc["paths"].(map[string]any)[path] = ops
+	}
+	return replaceRefs(doc, c.bodies).(map[string]any)
+}
+
+// servers are built from the host, basePath and schemes
+func (c swagger2) servers() []any {
+	host, _ := c.src["host"].(string)
+	base, _ := c.src["basePath"].(string)
+	if host == "" {
+		if base == "" {
+			return nil
+		}
+		return []any{map[string]any{"url": base}}
+	}
+	schemes := stringList(c.src["schemes"])
+	if len(schemes) == 0 {
+		schemes = []string{"https"}
+	}
+	var servers []any
+	for _, s := range schemes {
+		servers = append(servers, map[string]any{"url": s + "://" + host + base})
+	}
+	return servers
+}
+
+// operation converts the operation, the shared params of the path are added when the operation does not override them
+func (c swagger2) operation(op map[string]any, shared []map[string]any) map[string]any {
+	out := map[string]any{}
+	for k, v := range op {
+		switch k {
+		case "tags", "summary", "description", "externalDocs", "operationId", "deprecated", "security":
+			out[k] = v
+		default:
+			if strings.HasPrefix(k, "x-") {
+				out[k] = v
+			}
+		}
+	}
+	consumes, produces := c.consumes, c.produces
+	if v, found := op["consumes"]; found {
+		consumes = stringList(v)
+	}
+	if v, found := op["produces"]; found {
+		produces = stringList(v)
+	}
+
+	params := paramList(op["parameters"])
+	defined := make(map[string]bool)
+	for _, p := range params {
+		defined[fmt.Sprint(p["in"], "|", p["name"], "|", p["$ref"])] = true
+	}
+	for _, p := range shared {
+		if !defined[fmt.Sprint(p["in"], "|", p["name"], "|", p["$ref"])] {
+			params = append(params, p)
+		}
+	}
+
+	var parameters []any
+	var body []map[string]any
+	for _, p := range params {
+		if ref, _ := p["$ref"].(string); ref != "" {
+			if c.bodies[strings.TrimPrefix(ref, "#/parameters/")] {
+				out["requestBody"] = map[string]any{"$ref": ref}
+				continue
+			}
+			parameters = append(parameters, p)
+			continue
+		}
+		switch p["in"] {
+		case "body", "formData":
+			body = append(body, p)
+		default:
+			parameters = append(parameters, convertParam(p))
+		}
+	}
+	if len(parameters) > 0 {
+		out["parameters"] = parameters
+	}
+	if len(body) > 0 {
+		out["requestBody"] = c.requestBody(body, consumes)
+	}
+
+	responses := map[string]any{}
+	resps, _ := op["responses"].(map[string]any)
+	for code, v := range resps {
+		if strings.HasPrefix(code, "x-") {
+			continue
+		}
+		responses[code] = c.response(v, produces)
+	}
+	out["responses"] = responses
+	return out
+}
+
+// requestBody converts a body param or the form params into a request body
+func (c swagger2) requestBody(params []map[string]any, consumes []string) map[string]any {
+	out := map[string]any{}
+	var schema map[string]any
+	if params[0]["in"] == "body" {
+		p := params[0]
+		schema, _ = convertSchema(p["schema"]).(map[string]any)
+		if d, found := p["description"]; found {
+			out["description"] = d
+		}
+		if len(consumes) == 0 {
+			consumes = []string{string(Json)}
+		}
+	} else {
+		// form params are the properties of an object
+		props := map[string]any{}
+		var required []any
+		file := false
+		for _, p := range params {
+			name, _ := p["name"].(string)
+			props[name] = paramSchema(p)
+			if req, _ := p["required"].(bool); req {
+				required = append(required, name)
+			}
+			file = file || p["type"] == "file"
+		}
+		schema = map[string]any{"type": "object", "properties": props}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		var forms []string
+		for _, m := range consumes {
+			if m == string(XForm) || m == string(Form) {
+				forms = append(forms, m)
+			}
+		}
+		consumes = forms
+		if len(consumes) == 0 {
+			consumes = []string{string(XForm)}
+			if file {
+				consumes = []string{string(Form)}
+			}
+		}
+	}
+	for _, p := range params {
+		if req, _ := p["required"].(bool); req && p["in"] == "body" {
+			out["required"] = true
+		}
+	}
+	content := map[string]any{}
+	for _, m := range consumes {
+		content[m] = map[string]any{"schema": schema}
+	}
+	out["content"] = content
+	return out
+}
+
+// response converts the schema, headers and examples of the response into content
+func (c swagger2) response(v any, produces []string) any {
+	resp, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	if _, found := resp["$ref"]; found {
+		return resp
+	}
+	out := map[string]any{"description": resp["description"]}
+	if out["description"] == nil {
+		out["description"] = ""
+	}
+	if headers, ok := resp["headers"].(map[string]any); ok {
+		h := map[string]any{}
+		for name, v := range headers {
+			header, _ := v.(map[string]any)
+			hv := map[string]any{"schema": paramSchema(header)}
+			if d, found := header["description"]; found {
+				hv["description"] = d
+			}
+			h[name] = hv
+		}
+		out["headers"] = h
+	}
+	examples, _ := resp["examples"].(map[string]any)
+	if schema, found := resp["schema"]; found || len(examples) > 0 {
+		if len(produces) == 0 {
+			produces = []string{string(Json)}
+		}
+		content := map[string]any{}
+		for _, m := range produces {
+			media := map[string]any{}
+			if found {
+				media["schema"] = convertSchema(schema)
+			}
+			if ex, found := examples[m]; found {
+				media["examples"] = map[string]any{"example": map[string]any{"value": ex}}
+			}
+			content[m] = media
+		}
+		out["content"] = content
+	}
+	for k, v := range resp {
+		if strings.HasPrefix(k, "x-") {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// convertParam moves the type of a query, header or path param into its schema
+// and the collectionFormat into the style.
+func convertParam(p map[string]any) map[string]any {
+	out := map[string]any{"schema": paramSchema(p)}
+	for k, v := range p {
+		switch k {
+		case "name", "in", "description", "required", "deprecated", "allowEmptyValue":
+			out[k] = v
+		default:
+			if strings.HasPrefix(k, "x-") {
+				out[k] = v
+			}
+		}
+	}
+	switch p["collectionFormat"] {
+	case "csv":
+		if p["in"] == "query" {
+			out["style"], out["explode"] = "form", false
+		}
+	case "ssv":
+		out["style"] = "spaceDelimited"
+	case "pipes":
+		out["style"] = "pipeDelimited"
+	case "multi":
+		out["style"], out["explode"] = "form", true
+	}
+	if ex, found := p["x-example"]; found {
+		out["examples"] = map[string]any{"example": map[string]any{"value": ex}}
+		delete(out, "x-example")
+	}
+	return out
+}
+
+// paramSchema returns the schema of the type fields of a non body param, header or items object
+func paramSchema(p map[string]any) any {
+	s := map[string]any{}
+	for _, k := range []string{"type", "format", "enum", "default", "minimum", "maximum", "pattern",
+		"minLength", "maxLength", "minItems", "maxItems", "uniqueItems", "multipleOf", "exclusiveMinimum", "exclusiveMaximum"} {
+		if v, found := p[k]; found {
+			s[k] = v
+		}
+	}
+	if items, ok := p["items"].(map[string]any); ok {
+		s["items"] = paramSchema(items)
+	}
+	return convertSchema(s)
+}
+
+// convertSchema replaces the file type, x-nullable and the discriminator of a Swagger 2.0 schema
+func convertSchema(v any) any {
+	s, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	out := make(map[string]any, len(s))
+	for k, v := range s {
+		switch k {
+		case "properties", "definitions":
+			props := map[string]any{}
+			if m, ok := v.(map[string]any); ok {
+				for name, p := range m {
+					props[name] = convertSchema(p)
+				}
+			}
+			out[k] = props
+		case "items", "additionalProperties", "not":
+			out[k] = convertSchema(v)
+		case "allOf", "anyOf", "oneOf":
+			var l []any
+			items, _ := v.([]any)
+			for _, item := range items {
+				l = append(l, convertSchema(item))
+			}
+			out[k] = l
+		case "x-nullable":
+			out["nullable"] = v
+		case "discriminator":
+			if name, ok := v.(string); ok {
+				out[k] = map[string]any{"propertyName": name}
+			} else {
+				out[k] = v
+			}
+		default:
+			out[k] = v
+		}
+	}
+	if out["type"] == "file" {
+		out["type"], out["format"] = "string", "binary"
+	}
+	return out
+}
+
+// convertSecurity converts the basic type and the oauth2 flow of a security definition
+func convertSecurity(v any) any {
+	d, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	out := map[string]any{}
+	for k, v := range d {
+		switch k {
+		case "type", "description", "name", "in":
+			out[k] = v
+		default:
+			if strings.HasPrefix(k, "x-") {
+				out[k] = v
+			}
+		}
+	}
+	switch d["type"] {
+	case "basic":
+		out["type"], out["scheme"] = "http", "basic"
+	case "oauth2":
+		flow := map[string]any{"scopes": map[string]any{}}
+		for _, k := range []string{"authorizationUrl", "tokenUrl", "scopes"} {
+			if v, found := d[k]; found {
+				flow[k] = v
+			}
+		}
+		name := map[any]string{
+			"implicit":    "implicit",
+			"password":    "password",
+			"application": "clientCredentials",
+			"accessCode":  "authorizationCode",
+		}[d["flow"]]
+		out["flows"] = map[string]any{name: flow}
+	}
+	return out
+}
+
+// replaceRefs points the $refs of Swagger 2.0 to the components,
+// refs of global body params point to the request bodies.
+func replaceRefs(v any, bodies map[string]bool) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, v := range t {
+			ref, ok := v.(string)
+			if k != "$ref" || !ok {
+				t[k] = replaceRefs(v, bodies)
+				continue
+			}
+			switch {
+			case strings.HasPrefix(ref, "#/definitions/"):
+				t[k] = "#/components/schemas/" + strings.TrimPrefix(ref, "#/definitions/")
+			case strings.HasPrefix(ref, "#/parameters/"):
+				name := strings.TrimPrefix(ref, "#/parameters/")
+				if bodies[name] {
+					t[k] = "#/components/requestBodies/" + name
+				} else {
+					t[k] = "#/components/parameters/" + name
+				}
+			case strings.HasPrefix(ref, "#/responses/"):
+				t[k] = "#/components/responses/" + strings.TrimPrefix(ref, "#/responses/")
+			}
+		}
+	case []any:
+		for i := range t {
+			t[i] = replaceRefs(t[i], bodies)
+		}
+	}
+	return v
+}
+
+func paramList(v any) []map[string]any {
+	l, _ := v.([]any)
+	params := make([]map[string]any, 0, len(l))
+	for _, p := range l {
+		if m, ok := p.(map[string]any); ok {
+			params = append(params, m)
+		}
+	}
+	return params
+}
+
+func stringList(v any) []string {
+	l, _ := v.([]any)
+	var s []string
+	for _, item := range l {
+		if str, ok := item.(string); ok {
+			s = append(s, str)
+		}
+	}
+	return s
+}
+
+// Swagger2 converts the document into a Swagger 2.0 json spec, the reverse of NewFromSwagger2.
+// The schemas, parameters, responses and security schemes become definitions, parameters, responses
+// and security definitions, request bodies become body or formData params and the first server becomes
+// the host, basePath and schemes. Cookie params, callbacks, links, webhooks and OpenID Connect security
+// have no Swagger 2.0 equivalent and are dropped, the anyOf and oneOf of a schema are written as x-anyOf and x-oneOf.
+//
+//	b, err := doc.Swagger2()
+func (o *OpenAPI) Swagger2() ([]byte, error) {
+	b, err := o.JSONWith(MarshalOptions{OmitEmpty: true})
+	if err != nil {
+		return nil, err
+	}
+	var src map[string]any
+	if err := json.Unmarshal(b, &src); err != nil {
+		return nil, err
+	}
+	c := openapi3{src: src, bodies: make(map[string]bool)}
+	return json.Marshal(c.convert())
+}
+
+// openapi3 converts the generic json of an OpenAPI 3 document into Swagger 2.0
+type openapi3 struct {
+	src    map[string]any
+	bodies map[string]bool // request bodies of the components written as parameters
+}
+
+func (c openapi3) convert() map[string]any {
+	doc := map[string]any{
+		"swagger": "2.0",
+		"info":    c.src["info"],
+		"paths":   map[string]any{},
+	}
+	for _, k := range []string{"tags", "security", "externalDocs"} {
+		if v, found := c.src[k]; found {
+			doc[k] = v
+		}
+	}
+	for k, v := range c.src {
+		if strings.HasPrefix(k, "x-") {
+			doc[k] = v
+		}
+	}
+	servers, _ := c.src["servers"].([]any)
+	for k, v := range hostSchemes(servers) {
+		doc[k] = v
+	}
+
+	components, _ := c.src["components"].(map[string]any)
+	if schemas, ok := components["schemas"].(map[string]any); ok {
+		defs := map[string]any{}
+		for name, s := range schemas {
+			defs[name] = schema2(s)
+		}
+		doc["definitions"] = defs
+	}
+	parameters := map[string]any{}
+	if params, ok := components["parameters"].(map[string]any); ok {
+		for name, v := range params {
+			if p := param2(v); p != nil {
+				parameters[name] = p
+			}
+		}
+	}
+	if bodies, ok := components["requestBodies"].(map[string]any); ok {
+		for name, v := range bodies {
+			body, _ := v.(map[string]any)
+			params, _ := c.requestBody(body)
+			if len(params) == 1 && params[0]["in"] == "body" {
+				// only a body param can be referenced, form params are written with each operation
+				c.bodies[name] = true
+				parameters[name] = params[0]
+			}
+		}
+	}
+	if len(parameters) > 0 {
+		doc["parameters"] = parameters
+	}
+	if resps, ok := components["responses"].(map[string]any); ok {
+		responses := map[string]any{}
+		for name, v := range resps {
+			responses[name], _ = c.response(v)
+		}
+		doc["responses"] = responses
+	}
+	if schemes, ok := components["securitySchemes"].(map[string]any); ok {
+		defs := map[string]any{}
+		for name, v := range schemes {
+			if d := security2(v); d != nil {
+				defs[name] = d
+			}
+		}
+		doc["securityDefinitions"] = defs
+	}
+
+	paths, _ := c.src["paths"].(map[string]any)
+	for path, v := range paths {
+		item, _ := v.(map[string]any)
+		ops := map[string]any{}
+		for method, v := range item {
+			op, ok := v.(map[string]any)
+			switch {
+			case method == "parameters":
+				if params := params2(v); len(params) > 0 {
+					ops[method] = params
+				}
+			case strings.HasPrefix(method, "x-"):
+				ops[method] = v
+			case ok && method != "trace":
+				ops[method] = c.operation(op, components)
+			}
+		}
+		doc["paths"].(map[string]any)[path] = ops
+	}
+	return replaceRefs2(doc).(map[string]any)
+}
+
+// hostSchemes returns the host, basePath and schemes of the first server,
+// the schemes of the other servers with the same host and path are added.
+func hostSchemes(servers []any) map[string]any {
+	var host, base string
+	var schemes []any
+	for i, v := range servers {
+		s, _ := v.(map[string]any)
+		u, err := url.Parse(fmt.Sprint(s["url"]))
+		if err != nil {
+			continue
+		}
+		if i == 0 {
+			host, base = u.Host, strings.TrimSuffix(u.Path, "/")
+		} else if u.Host != host || strings.TrimSuffix(u.Path, "/") != base {
+			continue
+		}
+		if u.Scheme != "" {
+			schemes = append(schemes, u.Scheme)
+		}
+	}
+	out := map[string]any{}
+	if host != "" {
+		out["host"] = host
+	}
+	if base != "" {
+		out["basePath"] = base
+	}
+	if len(schemes) > 0 {
+		out["schemes"] = schemes
+	}
+	return out
+}
+
+// operation converts the params, request body and responses of the operation,
+// the media types of the content become the consumes and produces.
+func (c openapi3) operation(op map[string]any, components map[string]any) map[string]any {
+	out := map[string]any{}
+	for k, v := range op {
+		switch k {
+		case "tags", "summary", "description", "externalDocs", "operationId", "deprecated", "security":
+			out[k] = v
+		default:
+			if strings.HasPrefix(k, "x-") {
+				out[k] = v
+			}
+		}
+	}
+	params := params2(op["parameters"])
+	if v, found := op["requestBody"]; found {
+		body, _ := v.(map[string]any)
+		ref, _ := body["$ref"].(string)
+		name := strings.TrimPrefix(ref, "#/components/requestBodies/")
+		if ref != "" {
+			body, _ = components["requestBodies"].(map[string]any)[name].(map[string]any)
+		}
+		bodyParams, consumes := c.requestBody(body)
+		if c.bodies[name] {
+			params = append(params, map[string]any{"$ref": ref})
+		} else {
+			// a referenced form is written inline
+			for _, p := range bodyParams {
+				params = append(params, p)
+			}
+		}
+		if len(consumes) > 0 {
+			out["consumes"] = consumes
+		}
+	}
+	if len(params) > 0 {
+		out["parameters"] = params
+	}
+
+	responses := map[string]any{}
+	produced := map[string]bool{}
+	resps, _ := op["responses"].(map[string]any)
+	for code, v := range resps {
+		var produces []string
+		responses[code], produces = c.response(v)
+		for _, m := range produces {
+			produced[m] = true
+		}
+	}
+	out["responses"] = responses
+	if len(produced) > 0 {
+		out["produces"] = sortedKeys(produced)
+	}
+	return out
+}
+
+// requestBody converts the request body into a body param or the form params of an object schema
+// and returns the media types of its content.
+func (c openapi3) requestBody(body map[string]any) ([]map[string]any, []string) {
+	content, _ := body["content"].(map[string]any)
+	if len(content) == 0 {
+		return nil, nil
+	}
+	consumes := sortedKeys(content)
+	required, _ := body["required"].(bool)
+
+	var forms []string
+	for _, m := range consumes {
+		if m == string(XForm) || m == string(Form) {
+			forms = append(forms, m)
+		}
+	}
+	if len(forms) > 0 {
+		media, _ := content[forms[0]].(map[string]any)
+		s, _ := media["schema"].(map[string]any)
+		if props, ok := s["properties"].(map[string]any); ok {
+			req := map[string]bool{}
+			for _, name := range stringList(s["required"]) {
+				req[name] = true
+			}
+			var params []map[string]any
+			for _, name := range sortedKeys(props) {
+				p := map[string]any{"name": name, "in": "formData"}
+				if req[name] {
+					p["required"] = true
+				}
+				prop, _ := schema2(props[name]).(map[string]any)
+				for k, v := range flattenSchema(prop) {
+					p[k] = v
+				}
+				params = append(params, p)
+			}
+			return params, forms
+		}
+	}
+
+	m := string(Json)
+	if _, found := content[m]; !found {
+		m = consumes[0]
+	}
+	media, _ := content[m].(map[string]any)
+	p := map[string]any{"name": "body", "in": "body", "schema": schema2(media["schema"])}
+	if p["schema"] == nil {
+		p["schema"] = map[string]any{}
+	}
+	if d, found := body["description"]; found {
+		p["description"] = d
+	}
+	if required {
+		p["required"] = true
+	}
+	return []map[string]any{p}, consumes
+}
+
+// response converts the content of the response into its schema and examples
+// and returns the media types of the content.
+func (c openapi3) response(v any) (any, []string) {
+	resp, ok := v.(map[string]any)
+	if !ok {
+		return v, nil
+	}
+	if _, found := resp["$ref"]; found {
+		return resp, nil
+	}
+	out := map[string]any{"description": resp["description"]}
+	if out["description"] == nil {
+		out["description"] = ""
+	}
+	if headers, ok := resp["headers"].(map[string]any); ok {
+		h := map[string]any{}
+		for name, v := range headers {
+			header, _ := v.(map[string]any)
+			s, _ := schema2(header["schema"]).(map[string]any)
+			hv := flattenSchema(s)
+			if d, found := header["description"]; found {
+				hv["description"] = d
+			}
+			h[name] = hv
+		}
+		out["headers"] = h
+	}
+	content, _ := resp["content"].(map[string]any)
+	produces := sortedKeys(content)
+	if len(produces) > 0 {
+		m := string(Json)
+		if _, found := content[m]; !found {
+			m = produces[0]
+		}
+		media, _ := content[m].(map[string]any)
+		if s, found := media["schema"]; found {
+			out["schema"] = schema2(s)
+		}
+		examples := map[string]any{}
+		for _, m := range produces {
+			media, _ := content[m].(map[string]any)
+			if ex, found := firstExample(media); found {
+				examples[m] = ex
+			}
+		}
+		if len(examples) > 0 {
+			out["examples"] = examples
+		}
+	}
+	for k, v := range resp {
+		if strings.HasPrefix(k, "x-") {
+			out[k] = v
+		}
+	}
+	return out, produces
+}
+
+// params2 converts a list of params, cookie params are dropped
+func params2(v any) []any {
+	var params []any
+	for _, p := range paramList(v) {
+		if p := param2(p); p != nil {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// param2 moves the schema of a query, header or path param into the param
+// and the style into the collectionFormat. A cookie param returns nil.
+func param2(v any) map[string]any {
+	p, ok := v.(map[string]any)
+	if !ok || p["in"] == "cookie" {
+		return nil
+	}
+	if _, found := p["$ref"]; found {
+		return p
+	}
+	out := map[string]any{}
+	for k, v := range p {
+		switch k {
+		case "name", "in", "description", "required", "allowEmptyValue":
+			out[k] = v
+		default:
+			if strings.HasPrefix(k, "x-") {
+				out[k] = v
+			}
+		}
+	}
+	s, found := p["schema"]
+	if !found {
+		// a param with content uses the schema of its media type
+		content, _ := p["content"].(map[string]any)
+		for _, m := range sortedKeys(content) {
+			media, _ := content[m].(map[string]any)
+			s = media["schema"]
+			break
+		}
+	}
+	schema, _ := schema2(s).(map[string]any)
+	for k, v := range flattenSchema(schema) {
+		out[k] = v
+	}
+	if out["type"] == nil {
+		out["type"] = "string"
+	}
+	if p["in"] == "path" {
+		out["required"] = true
+	}
+	if out["type"] == "array" {
+		explode, exploded := p["explode"].(bool)
+		switch p["style"] {
+		case "spaceDelimited":
+			out["collectionFormat"] = "ssv"
+		case "pipeDelimited":
+			out["collectionFormat"] = "pipes"
+		case "form", nil:
+			// form is the default style of a query param and explodes by default
+			if p["in"] == "query" && (!exploded || explode) {
+				out["collectionFormat"] = "multi"
+			} else {
+				out["collectionFormat"] = "csv"
+			}
+		default:
+			out["collectionFormat"] = "csv"
+		}
+	}
+	if ex, found := firstExample(p); found {
+		out["x-example"] = ex
+	}
+	return out
+}
+
+// flattenSchema returns the type fields of a schema for a non body param, header or items object
+func flattenSchema(s map[string]any) map[string]any {
+	out := map[string]any{}
+	for _, k := range []string{"type", "format", "enum", "default", "minimum", "maximum", "pattern",
+		"minLength", "maxLength", "minItems", "maxItems", "uniqueItems", "multipleOf", "exclusiveMinimum", "exclusiveMaximum"} {
+		if v, found := s[k]; found {
+			out[k] = v
+		}
+	}
+	if items, ok := s["items"].(map[string]any); ok {
+		out["items"] = flattenSchema(items)
+	}
+	return out
+}
+
+// firstExample returns the example of a media type or param, or the value of its first example by name
+func firstExample(m map[string]any) (any, bool) {
+	if ex, found := m["example"]; found {
+		return ex, true
+	}
+	examples, _ := m["examples"].(map[string]any)
+	for _, name := range sortedKeys(examples) {
+		ex, _ := examples[name].(map[string]any)
+		if v, found := ex["value"]; found {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// schema2 replaces the binary format, nullable, the discriminator and
+// the anyOf and oneOf of an OpenAPI 3 schema.
+func schema2(v any) any {
+	s, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	out := make(map[string]any, len(s))
+	for k, v := range s {
+		switch k {
+		case "properties":
+			props := map[string]any{}
+			if m, ok := v.(map[string]any); ok {
+				for name, p := range m {
+					props[name] = schema2(p)
+				}
+			}
+			out[k] = props
+		case "items", "additionalProperties", "not":
+			out[k] = schema2(v)
+		case "allOf", "anyOf", "oneOf":
+			var l []any
+			items, _ := v.([]any)
+			for _, item := range items {
+				l = append(l, schema2(item))
+			}
+			if k != "allOf" {
+				k = "x-" + k
+			}
+			out[k] = l
+		case "nullable":
+			out["x-nullable"] = v
+		case "discriminator":
+			if d, ok := v.(map[string]any); ok {
+				out[k] = d["propertyName"]
+			} else {
+				out[k] = v
+			}
+		case "writeOnly", "deprecated":
+			out["x-"+k] = v
+		default:
+			out[k] = v
+		}
+	}
+	if out["type"] == "string" && out["format"] == "binary" {
+		out["type"] = "file"
+		delete(out, "format")
+	}
+	return out
+}
+
+// security2 converts the http basic type and the first oauth2 flow of a security scheme,
+// other http schemes are written as an Authorization header api key. OpenID Connect returns nil.
+func security2(v any) map[string]any {
+	d, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+	out := map[string]any{}
+	for k, v := range d {
+		switch k {
+		case "type", "description", "name", "in":
+			out[k] = v
+		default:
+			if strings.HasPrefix(k, "x-") {
+				out[k] = v
+			}
+		}
+	}
+	switch d["type"] {
+	case "http":
+		if d["scheme"] == "basic" {
+			out["type"] = "basic"
+		} else {
+			out["type"], out["name"], out["in"] = "apiKey", "Authorization", "header"
+		}
+	case "oauth2":
+		flows, _ := d["flows"].(map[string]any)
+		names := map[string]string{
+			"implicit":          "implicit",
+			"password":          "password",
+			"clientCredentials": "application",
+			"authorizationCode": "accessCode",
+		}
+		for _, name := range sortedKeys(flows) {
+			if names[name] == "" {
+				continue
+			}
+			flow, _ := flows[name].(map[string]any)
+			out["flow"] = names[name]
+			for _, k := range []string{"authorizationUrl", "tokenUrl", "scopes"} {
+				if v, found := flow[k]; found {
+					out[k] = v
+				}
+			}
+			break
+		}
+	case "openIdConnect":
+		return nil
+	}
+	return out
+}
+
+// replaceRefs2 points the $refs of the components to the Swagger 2.0 definitions, parameters and responses
+func replaceRefs2(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, v := range t {
+			ref, ok := v.(string)
+			if k != "$ref" || !ok {
+				t[k] = replaceRefs2(v)
+				continue
+			}
+			switch {
+			case strings.HasPrefix(ref, "#/components/schemas/"):
+				t[k] = "#/definitions/" + strings.TrimPrefix(ref, "#/components/schemas/")
+			case strings.HasPrefix(ref, "#/components/parameters/"):
+				t[k] = "#/parameters/" + strings.TrimPrefix(ref, "#/components/parameters/")
+			case strings.HasPrefix(ref, "#/components/requestBodies/"):
+				t[k] = "#/parameters/" + strings.TrimPrefix(ref, "#/components/requestBodies/")
+			case strings.HasPrefix(ref, "#/components/responses/"):
+				t[k] = "#/responses/" + strings.TrimPrefix(ref, "#/components/responses/")
+			}
+		}
+	case []any:
+		for i := range t {
+			t[i] = replaceRefs2(t[i])
+		}
+	}
+	return v
+}
@@ -0,0 +1,129 @@
+package openapi
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestNewFromSwagger2(t *testing.T) {
+	fn := func(spec string) (string, error) {
+		doc, err := NewFromSwagger2(`{"swagger":"2.0","info":{"title":"pets","version":"1"},` + spec + `}`)
+		if err != nil {
+			return "", err
+		}
+		b, err := doc.JSONWith(MarshalOptions{OmitEmpty: true})
+		return string(b), err
+	}
+	cases := trial.Cases[string, string]{
+		"definitions": {
+			Input: `"paths":{"/pets":{"get":{"operationId":"listPets","responses":{"200":{"description":"ok","schema":{"type":"array","items":{"$ref":"#/definitions/Pet"}}}}}}},` +
+				`"definitions":{"Pet":{"type":"object","properties":{"name":{"type":"string","x-nullable":true},"photo":{"type":"file"}}}}`,
+			Expected: `{"openapi":"3.0.3","info":{"title":"pets","version":"1","description":""},"paths":{"/pets":{"get":{"operationId":"listPets","responses":{"200":{"description":"ok","content":{"application/json":{"schema":{"type":"array","items":{"$ref":"#/components/schemas/Pet"}}}}}}}}},` +
+				`"components":{"schemas":{"Pet":{"type":"object","properties":{"name":{"type":"string","nullable":true},"photo":{"type":"string","format":"binary"}}}}}}`,
+		},
+		"servers": {
+			Input:    `"host":"api.io","basePath":"/v1","schemes":["http","https"],"paths":{}`,
+			Expected: `{"openapi":"3.0.3","servers":[{"url":"http://api.io/v1","description":""},{"url":"https://api.io/v1","description":""}],"info":{"title":"pets","version":"1","description":""},"paths":{}}`,
+		},
+		"path params": {
+			Input: `"paths":{"/pets/{id}":{"parameters":[{"name":"id","in":"path","required":true,"type":"integer"}],` +
+				`"get":{"parameters":[{"name":"tags","in":"query","type":"array","items":{"type":"string"},"collectionFormat":"multi"}],"responses":{"204":{"description":"ok","headers":{"X-Rate":{"type":"integer"}}}}}}}`,
+			Expected: `{"openapi":"3.0.3","info":{"title":"pets","version":"1","description":""},"paths":{"/pets/{id}":{"get":{"responses":{"204":{"description":"ok","headers":{"X-Rate":{"schema":{"type":"integer"}}}}},` +
+				`"parameters":[{"name":"id","in":"path","schema":{"type":"integer"},"examples":null},{"name":"tags","in":"query","style":"form","explode":true,"schema":{"type":"array","items":{"type":"string"}},"examples":null}]}}}}`,
+		},
+		"body param": {
+			Input: `"consumes":["application/json","application/xml"],"paths":{"/pets":{"post":{"parameters":[{"name":"body","in":"body","required":true,"schema":{"$ref":"#/definitions/Pet"}}],"responses":{"default":{"$ref":"#/responses/Error"}}}}},` +
+				`"definitions":{"Pet":{"type":"object"}},"responses":{"Error":{"description":"error"}}`,
+			Expected: `{"openapi":"3.0.3","info":{"title":"pets","version":"1","description":""},"paths":{"/pets":{"post":{"responses":{"default":{"$ref":"#/components/responses/Error"}},` +
+				`"requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/Pet"}},"application/xml":{"schema":{"$ref":"#/components/schemas/Pet"}}},"required":true}}}},` +
+				`"components":{"schemas":{"Pet":{"type":"object"}},"responses":{"Error":{"description":"error"}}}}`,
+		},
+		"form params": {
+			Input: `"paths":{"/pets":{"post":{"parameters":[{"name":"name","in":"formData","type":"string","required":true},{"name":"photo","in":"formData","type":"file"}],"responses":{"201":{"description":"created"}}}}}`,
+			Expected: `{"openapi":"3.0.3","info":{"title":"pets","version":"1","description":""},"paths":{"/pets":{"post":{"responses":{"201":{"description":"created"}},` +
+				`"requestBody":{"content":{"multipart/form-data":{"schema":{"type":"object","properties":{"name":{"type":"string"},"photo":{"type":"string","format":"binary"}},"required":["name"]}}}}}}}}`,
+		},
+		"global params": {
+			Input: `"paths":{"/pets":{"get":{"parameters":[{"$ref":"#/parameters/limit"}],"responses":{"200":{"description":"ok"}}},"post":{"parameters":[{"$ref":"#/parameters/pet"}],"responses":{"201":{"description":"created"}}}}},` +
+				`"parameters":{"limit":{"name":"limit","in":"query","type":"integer"},"pet":{"name":"pet","in":"body","schema":{"type":"object"}}}`,
+			Expected: `{"openapi":"3.0.3","info":{"title":"pets","version":"1","description":""},"paths":{"/pets":{"get":{"responses":{"200":{"description":"ok"}},"parameters":[{"$ref":"#/components/parameters/limit"}]},"post":{"responses":{"201":{"description":"created"}},"requestBody":{"$ref":"#/components/requestBodies/pet"}}}},` +
+				`"components":{"parameters":{"limit":{"name":"limit","in":"query","schema":{"type":"integer"},"examples":null}},"requestBodies":{"pet":{"content":{"application/json":{"schema":{"type":"object"}}}}}}}`,
+		},
+		"security": {
+			Input: `"paths":{},"security":[{"basic":[]}],"securityDefinitions":{"basic":{"type":"basic"},"key":{"type":"apiKey","name":"X-Key","in":"header"},` +
+				`"oauth":{"type":"oauth2","flow":"application","tokenUrl":"https://api.io/token","scopes":{"read":"read pets"}}}`,
+			Expected: `{"openapi":"3.0.3","info":{"title":"pets","version":"1","description":""},"paths":{},"components":{"securitySchemes":{"basic":{"type":"http","scheme":"basic"},"key":{"type":"apiKey","name":"X-Key","in":"header"},` +
+				`"oauth":{"type":"oauth2","flows":{"clientCredentials":{"tokenUrl":"https://api.io/token","scopes":{"read":"read pets"}}}}}},"security":[{"basic":[]}]}`,
+		},
+		"version 1.2": {
+			Input:       `"swagger":"1.2"`,
+			ExpectedErr: errors.New(`unsupported swagger version "1.2"`),
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
+func TestSwagger2(t *testing.T) {
+	fn := func(spec string) (string, error) {
+		doc, err := NewFromJson(`{"openapi":"3.0.3","info":{"title":"pets","version":"1"},` + spec + `}`)
+		if err != nil {
+			return "", err
+		}
+		b, err := doc.Swagger2()
+		return string(b), err
+	}
+	cases := trial.Cases[string, string]{
+		"schemas": {
+			Input: `"paths":{"/pets":{"get":{"operationId":"listPets","responses":{"200":{"description":"ok","content":{"application/json":{"schema":{"type":"array","items":{"$ref":"#/components/schemas/Pet"}},"examples":{"pets":{"value":[{"name":"max"}]}}}}}}}}},` +
+				`"components":{"schemas":{"Pet":{"type":"object","properties":{"name":{"type":"string","nullable":true},"photo":{"type":"string","format":"binary"},"kind":{"oneOf":[{"type":"string"},{"type":"integer"}]}}}}}`,
+			Expected: `{"definitions":{"Pet":{"properties":{"kind":{"x-oneOf":[{"type":"string"},{"type":"integer"}]},"name":{"type":"string","x-nullable":true},"photo":{"type":"file"}},"type":"object"}},"info":{"description":"","title":"pets","version":"1"},` +
+				`"paths":{"/pets":{"get":{"operationId":"listPets","produces":["application/json"],"responses":{"200":{"description":"ok","examples":{"application/json":[{"name":"max"}]},"schema":{"items":{"$ref":"#/definitions/Pet"},"type":"array"}}}}}},"swagger":"2.0"}`,
+		},
+		"servers": {
+			Input:    `"servers":[{"url":"http://api.io/v1"},{"url":"https://api.io/v1"},{"url":"https://other.io"}],"paths":{}`,
+			Expected: `{"basePath":"/v1","host":"api.io","info":{"description":"","title":"pets","version":"1"},"paths":{},"schemes":["http","https"],"swagger":"2.0"}`,
+		},
+		"params": {
+			Input: `"paths":{"/pets/{id}":{"get":{"parameters":[{"name":"id","in":"path","required":true,"schema":{"type":"integer"}},{"name":"tags","in":"query","schema":{"type":"array","items":{"type":"string"}},"examples":{"a":{"value":"a"}}},` +
+				`{"name":"ids","in":"query","explode":false,"schema":{"type":"array","items":{"type":"string"}}},{"name":"session","in":"cookie","schema":{"type":"string"}}],"responses":{"204":{"description":"ok","headers":{"X-Rate":{"schema":{"type":"integer"}}}}}}}}`,
+			Expected: `{"info":{"description":"","title":"pets","version":"1"},"paths":{"/pets/{id}":{"get":{"parameters":[{"in":"path","name":"id","required":true,"type":"integer"},{"collectionFormat":"csv","in":"query","items":{"type":"string"},"name":"ids","type":"array"},` +
+				`{"collectionFormat":"multi","in":"query","items":{"type":"string"},"name":"tags","type":"array","x-example":"a"}],"responses":{"204":{"description":"ok","headers":{"X-Rate":{"type":"integer"}}}}}}},"swagger":"2.0"}`,
+		},
+		"body param": {
+			Input: `"paths":{"/pets":{"post":{"requestBody":{"required":true,"content":{"application/json":{"schema":{"$ref":"#/components/schemas/Pet"}},"application/xml":{"schema":{"$ref":"#/components/schemas/Pet"}}}},"responses":{"default":{"$ref":"#/components/responses/Error"}}}}},` +
+				`"components":{"schemas":{"Pet":{"type":"object"}},"responses":{"Error":{"description":"error"}}}`,
+			Expected: `{"definitions":{"Pet":{"type":"object"}},"info":{"description":"","title":"pets","version":"1"},"paths":{"/pets":{"post":{"consumes":["application/json","application/xml"],` +
+				`"parameters":[{"in":"body","name":"body","required":true,"schema":{"$ref":"#/definitions/Pet"}}],"responses":{"default":{"$ref":"#/responses/Error"}}}}},"responses":{"Error":{"description":"error"}},"swagger":"2.0"}`,
+		},
+		"form params": {
+			Input: `"paths":{"/pets":{"post":{"requestBody":{"content":{"multipart/form-data":{"schema":{"type":"object","properties":{"name":{"type":"string"},"photo":{"type":"string","format":"binary"}},"required":["name"]}}}},"responses":{"201":{"description":"created"}}}}}`,
+			Expected: `{"info":{"description":"","title":"pets","version":"1"},"paths":{"/pets":{"post":{"consumes":["multipart/form-data"],` +
+				`"parameters":[{"in":"formData","name":"name","required":true,"type":"string"},{"in":"formData","name":"photo","type":"file"}],"responses":{"201":{"description":"created"}}}}},"swagger":"2.0"}`,
+		},
+		"component params": {
+			Input: `"paths":{"/pets":{"get":{"parameters":[{"$ref":"#/components/parameters/limit"}],"responses":{"200":{"description":"ok"}}},"post":{"requestBody":{"$ref":"#/components/requestBodies/pet"},"responses":{"201":{"description":"created"}}}}},` +
+				`"components":{"parameters":{"limit":{"name":"limit","in":"query","schema":{"type":"integer"}}},"requestBodies":{"pet":{"content":{"application/json":{"schema":{"type":"object"}}}}}}`,
+			Expected: `{"info":{"description":"","title":"pets","version":"1"},"parameters":{"limit":{"in":"query","name":"limit","type":"integer"},"pet":{"in":"body","name":"body","schema":{"type":"object"}}},` +
+				`"paths":{"/pets":{"get":{"parameters":[{"$ref":"#/parameters/limit"}],"responses":{"200":{"description":"ok"}}},"post":{"consumes":["application/json"],"parameters":[{"$ref":"#/parameters/pet"}],"responses":{"201":{"description":"created"}}}}},"swagger":"2.0"}`,
+		},
+		"security": {
+			Input: `"paths":{},"security":[{"basic":[]}],"components":{"securitySchemes":{"basic":{"type":"http","scheme":"basic"},"bearer":{"type":"http","scheme":"bearer"},"key":{"type":"apiKey","name":"X-Key","in":"header"},` +
+				`"oidc":{"type":"openIdConnect","openIdConnectUrl":"https://api.io/.well-known"},"oauth":{"type":"oauth2","flows":{"clientCredentials":{"tokenUrl":"https://api.io/token","scopes":{"read":"read pets"}}}}}}`,
+			Expected: `{"info":{"description":"","title":"pets","version":"1"},"paths":{},"security":[{"basic":[]}],"securityDefinitions":{"basic":{"type":"basic"},"bearer":{"in":"header","name":"Authorization","type":"apiKey"},` +
+				`"key":{"in":"header","name":"X-Key","type":"apiKey"},"oauth":{"flow":"application","scopes":{"read":"read pets"},"tokenUrl":"https://api.io/token","type":"oauth2"}},"swagger":"2.0"}`,
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+
+	// a converted document reads back into the same document
+	doc, err := NewFromSwagger2(cases["body param"].Expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := doc.Swagger2()
+	if eq, diff := trial.Equal(string(b), cases["body param"].Expected); err != nil || !eq {
+		t.Error(err, diff)
+	}
+}
@@ -0,0 +1,159 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// parseTag parses the openapi struct tag into its keys and values.
+// Values containing commas are quoted with single quotes and keys without a value,
+// such as required, are set to "true".
+//
+//	`openapi:"desc='id of the account, unique',format=uuid,required,readonly,example=123"`
+func parseTag(tag string) map[string]string {
+	m := make(map[string]string)
+	for tag != "" {
+		key, value := tag, "true"
+		if i := strings.IndexAny(tag, "=,"); i == -1 {
+			tag = ""
+		} else if tag[i] == ',' {
+			key, tag = tag[:i], tag[i+1:]
+		} else if quoted, found := strings.CutPrefix(tag[i+1:], "'"); found {
+			key = tag[:i]
+			value, tag, _ = strings.Cut(quoted, "'")
+			tag = strings.TrimPrefix(tag, ",")
+		} else {
+			key = tag[:i]
+			value, tag, _ = strings.Cut(tag[i+1:], ",")
+		}
+		if key = strings.TrimSpace(key); key != "" {
+			m[key] = value
+		}
+	}
+	return m
+}
+
+// constraintTags are the struct tags of the validation of a property,
+// they can be set in the openapi tag as well.
+//
+//	Name string `json:"name" minLength:"1" maxLength:"20" pattern:"^[a-z]+$"`
+//	Age  int    `json:"age" min:"18" max:"130"`
+var constraintTags = []string{"min", "max", "minLength", "maxLength", "pattern"}
+
+// apply sets the values of the openapi struct tag on the property schema
+// and returns if the property is required.
+func (s *Schema) apply(tag map[string]string) (required bool) {
+	if v, found := tag["desc"]; found {
+		s.Desc = v
+	}
+	if v, found := tag["title"]; found {
+		s.Title = v
+	}
+	if v, found := tag["format"]; found {
+		s.Format = v
+	}
+	if v, found := tag["example"]; found {
+		s.Example = s.parseValue(v)
+	}
+	s.applyConstraints(tag)
+	// the flags of the schema, such as those of an override, are kept when the tag does not set them
+	if v, found := tag["readonly"]; found {
+		s.ReadOnly, _ = strconv.ParseBool(v)
+	}
+	if v, found := tag["writeonly"]; found {
+		s.WriteOnly, _ = strconv.ParseBool(v)
+	}
+	if v, found := tag["deprecated"]; found {
+		s.Deprecated, _ = strconv.ParseBool(v)
+	}
+	required, _ = strconv.ParseBool(tag["required"])
+	return required
+}
+
+// applyConstraints sets the validation keywords of the tag, min and max are the length
+// of a string and the number of items of an array.
+func (s *Schema) applyConstraints(tag map[string]string) {
+	limit := func(key string) *int {
+		if i, err := strconv.Atoi(tag[key]); err == nil {
+			return &i
+		}
+		return nil
+	}
+	for _, k := range []string{"min", "max"} {
+		if _, found := tag[k]; !found {
+			continue
+		}
+		switch s.Type {
+		case String:
+			if k == "min" {
+				s.MinLength = limit(k)
+			} else {
+				s.MaxLength = limit(k)
+			}
+		case Array:
+			if k == "min" {
+				s.MinItems = limit(k)
+			} else {
+				s.MaxItems = limit(k)
+			}
+		default:
+			if f, err := strconv.ParseFloat(tag[k], 64); err == nil && k == "min" {
+				s.Minimum = &f
+			} else if err == nil {
+				s.Maximum = &f
+			}
+		}
+	}
+	if _, found := tag["minLength"]; found {
+		s.MinLength = limit("minLength")
+	}
+	if _, found := tag["maxLength"]; found {
+		s.MaxLength = limit("maxLength")
+	}
+	if v, found := tag["pattern"]; found {
+		s.Pattern = v
+	}
+}
+
+// applyEnum sets the comma separated values of the enum struct tag as the allowed values of the property,
+// the values of an array are set on its items.
+//
+//	Status string `json:"status" enum:"active,closed"`
+func (s *Schema) applyEnum(values string) {
+	if s.Type == Array && s.Items != nil {
+		items := *s.Items
+		items.applyEnum(values)
+		s.Items = &items
+		return
+	}
+	s.Enum = nil
+	for _, v := range strings.Split(values, ",") {
+		s.Enum = append(s.Enum, s.parseValue(strings.TrimSpace(v)))
+	}
+}
+
+// parseValue converts the text of a tag to a value of the schema type,
+// the text is used as is if it is not a valid value of the type.
+func (s Schema) parseValue(v string) any {
+	switch s.Type {
+	case Integer:
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i
+		}
+	case Number:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	case Boolean:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	case Object, Array:
+		var val any
+		if err := json.Unmarshal([]byte(v), &val); err == nil {
+			return val
+		}
+	}
+	return v
+}
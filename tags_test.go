@@ -0,0 +1,109 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestParseTag(t *testing.T) {
+	fn := func(tag string) (map[string]string, error) {
+		return parseTag(tag), nil
+	}
+	cases := trial.Cases[string, map[string]string]{
+		"values": {
+			Input:    "desc=Account ID,format=uuid,required,example=123",
+			Expected: map[string]string{"desc": "Account ID", "format": "uuid", "required": "true", "example": "123"},
+		},
+		"quoted": {
+			Input:    "desc='id, unique',example='a=b'",
+			Expected: map[string]string{"desc": "id, unique", "example": "a=b"},
+		},
+		"flag last": {
+			Input:    "format=email, required",
+			Expected: map[string]string{"format": "email", "required": "true"},
+		},
+		"empty": {
+			Input:    "",
+			Expected: map[string]string{},
+		},
+	}
+	trial.New(fn, cases).SubTest(t)
+}
+
+func TestOpenAPITag(t *testing.T) {
+	type account struct {
+		ID      string   `json:"id" openapi:"desc=Account ID,format=uuid,required,example=8f2c1e"`
+		Balance float64  `json:"balance" openapi:"example=12.5"`
+		Count   int      `json:"count" desc:"number of orders" openapi:"required,example=3"`
+		Active  bool     `json:"active" openapi:"example=true"`
+		Tags    []string `json:"tags" openapi:"example='[\"a\",\"b\"]',desc='labels, sorted'"`
+		Note    string   `json:"note" openapi:"required=false"`
+		Created string   `json:"created" openapi:"readonly"`
+		Secret  string   `json:"secret" openapi:"writeonly,required"`
+		Legacy  string   `json:"legacy" deprecated:"true"`
+		Old     int      `json:"old" openapi:"deprecated"`
+	}
+	s := buildSchema(account{})
+	eq, diff := trial.Equal(s, Schema{
+		Title: "openapi.account",
+		Type:  Object,
+		Properties: map[string]Schema{
+			"id":      {Type: String, Format: "uuid", Desc: "Account ID", Example: "8f2c1e"},
+			"balance": {Type: Number, Example: 12.5},
+			"count":   {Type: Integer, Desc: "number of orders", Example: int64(3)},
+			"active":  {Type: Boolean, Example: true},
+			"tags":    {Type: Array, Items: &Schema{Type: String}, Desc: "labels, sorted", Example: []any{"a", "b"}},
+			"note":    {Type: String},
+			"created": {Type: String, ReadOnly: true},
+			"secret":  {Type: String, WriteOnly: true},
+			"legacy":  {Type: String, Deprecated: true},
+			"old":     {Type: Integer, Deprecated: true},
+		},
+		Required: []string{"count", "id", "secret"},
+		Order:    []string{"id", "balance", "count", "active", "tags", "note", "created", "secret", "legacy", "old"},
+	})
+	if !eq {
+		t.Error(diff)
+	}
+}
+
+func TestEnumTag(t *testing.T) {
+	type order struct {
+		Status   string   `json:"status" enum:"pending, shipped,delivered"`
+		Priority int      `json:"priority" enum:"1,2,3"`
+		Labels   []string `json:"labels" enum:"gift,fragile"`
+	}
+	s := buildSchema(order{})
+	eq, diff := trial.Equal(s.Properties, map[string]Schema{
+		"status":   {Type: String, Enum: []any{"pending", "shipped", "delivered"}},
+		"priority": {Type: Integer, Enum: []any{int64(1), int64(2), int64(3)}},
+		"labels":   {Type: Array, Items: &Schema{Type: String, Enum: []any{"gift", "fragile"}}},
+	})
+	if !eq {
+		t.Error(diff)
+	}
+}
+
+func TestConstraintTags(t *testing.T) {
+	type signup struct {
+		Name  string   `json:"name" minLength:"1" maxLength:"20" pattern:"^[a-z]+$"`
+		Age   int      `json:"age" min:"18" max:"130"`
+		Score float64  `json:"score" openapi:"min=0.5"`
+		Code  string   `json:"code" min:"4" max:"8" openapi:"pattern='^[0-9]{4,8}$'"`
+		Tags  []string `json:"tags" max:"5"`
+	}
+	one, four, five, eight, twenty := 1, 4, 5, 8, 20
+	low, high, half := 18.0, 130.0, 0.5
+	s := buildSchema(signup{})
+	eq, diff := trial.Equal(s.Properties, map[string]Schema{
+		"name":  {Type: String, MinLength: &one, MaxLength: &twenty, Pattern: "^[a-z]+$"},
+		"age":   {Type: Integer, Minimum: &low, Maximum: &high},
+		"score": {Type: Number, Minimum: &half},
+		"code":  {Type: String, MinLength: &four, MaxLength: &eight, Pattern: "^[0-9]{4,8}$"},
+		"tags":  {Type: Array, Items: &Schema{Type: String}, MaxItems: &five},
+	})
+	if !eq {
+		t.Error(diff)
+	}
+}
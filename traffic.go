@@ -0,0 +1,118 @@
+package openapi
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TrafficEntry is a request observed in an access log
+type TrafficEntry struct {
+	Method string
+	Path   string // request path, a query string is ignored
+	Status int
+}
+
+// TrafficReport compares the documented operations with the observed traffic.
+// Operations are written as "method path" and sorted.
+type TrafficReport struct {
+	Unused             []string // documented operations that were never requested
+	Undocumented       []string // requested operations that are not documented
+	UndocumentedStatus []string // documented operations that returned an undocumented status, "get /users/{id} 500"
+}
+
+// TrafficCoverage matches the entries to the documented routes, path params such as
+// /users/{id} match any value of the segment. A path without params is preferred
+// over a path with params when both match.
+func (o *OpenAPI) TrafficCoverage(entries []TrafficEntry) TrafficReport {
+	used := make(map[string]bool)
+	undocumented := make(map[string]bool)
+	statuses := make(map[string]bool)
+	for _, e := range entries {
+		method := strings.ToLower(e.Method)
+		path, _, _ := strings.Cut(e.Path, "?")
+		r := o.matchRoute(path, method)
+		if r == nil {
+			undocumented[method+" "+path] = true
+			continue
+		}
+		used[r.key()] = true
+		if _, found := r.Responses[Code(e.Status)]; !found && e.Status != 0 {
+			if _, found := r.Responses[DefaultStatus]; !found {
+				statuses[fmt.Sprintf("%v %v %d", method, r.path, e.Status)] = true
+			}
+		}
+	}
+
+	var report TrafficReport
+	for _, k := range sortedKeys(o.Paths) {
+		if !used[k] {
+			path, method, _ := strings.Cut(k, "|")
+			report.Unused = append(report.Unused, method+" "+path)
+		}
+	}
+	report.Undocumented = sortedKeys(undocumented)
+	report.UndocumentedStatus = sortedKeys(statuses)
+	return report
+}
+
+// matchRoute finds the route of the request path, nil if no route matches.
+func (o *OpenAPI) matchRoute(path, method string) *Route {
+	if r, found := o.Paths[path+"|"+method]; found {
+		return r
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	var match *Route
+	params := 0
+	for k, r := range o.Paths {
+		p, m, _ := strings.Cut(k, "|")
+		if m != method {
+			continue
+		}
+		route := strings.Split(strings.Trim(p, "/"), "/")
+		if len(route) != len(segments) {
+			continue
+		}
+		n := 0
+		for i, s := range route {
+			if regexPathParam.MatchString(s) && segments[i] != "" {
+				n++
+			} else if s != segments[i] {
+				n = -1
+				break
+			}
+		}
+		if n >= 0 && (match == nil || n < params || (n == params && k < match.key())) {
+			match, params = r, n
+		}
+	}
+	return match
+}
+
+// regexAccessLog matches the request line and status of a common or combined log format line
+// 127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /users/1 HTTP/1.1" 200 2326
+var regexAccessLog = regexp.MustCompile(`"([A-Z]+) (\S+)[^"]*" (\d{3})`)
+
+// ParseAccessLog reads the requests of an access log in the common or combined log format,
+// lines that do not contain a request are skipped.
+func ParseAccessLog(r io.Reader) ([]TrafficEntry, error) {
+	var entries []TrafficEntry
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		m := regexAccessLog.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		path := m[2]
+		if u, err := url.Parse(path); err == nil {
+			path = u.Path
+		}
+		status, _ := strconv.Atoi(m[3])
+		entries = append(entries, TrafficEntry{Method: m[1], Path: path, Status: status})
+	}
+	return entries, scanner.Err()
+}
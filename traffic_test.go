@@ -0,0 +1,47 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestTrafficCoverage(t *testing.T) {
+	doc := New("", "", "")
+	doc.GetRoute("/users", "get").AddResponse(Response{Status: 200})
+	doc.GetRoute("/users/{id}", "get").AddResponse(Response{Status: 200}).AddResponse(Response{Status: 404})
+	doc.GetRoute("/users/me", "get").AddResponse(Response{Status: 200})
+	doc.GetRoute("/users/{id}", "delete").AddResponse(Response{Status: 204})
+	doc.GetRoute("/health", "get").AddResponse(Response{Status: DefaultStatus})
+
+	log := `127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /users?limit=10 HTTP/1.1" 200 2326
+127.0.0.1 - - [10/Oct/2000:13:55:37 -0700] "GET /users/42 HTTP/1.1" 404 12
+127.0.0.1 - - [10/Oct/2000:13:55:38 -0700] "GET /users/me HTTP/1.1" 500 12 "-" "curl/8.0"
+127.0.0.1 - - [10/Oct/2000:13:55:39 -0700] "POST /users HTTP/1.1" 201 12
+not a request
+127.0.0.1 - - [10/Oct/2000:13:55:40 -0700] "GET /health HTTP/1.1" 503 0`
+	entries, err := ParseAccessLog(strings.NewReader(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq, diff := trial.Equal(entries, []TrafficEntry{
+		{Method: "GET", Path: "/users", Status: 200},
+		{Method: "GET", Path: "/users/42", Status: 404},
+		{Method: "GET", Path: "/users/me", Status: 500},
+		{Method: "POST", Path: "/users", Status: 201},
+		{Method: "GET", Path: "/health", Status: 503},
+	})
+	if !eq {
+		t.Fatal(diff)
+	}
+
+	eq, diff = trial.Equal(doc.TrafficCoverage(entries), TrafficReport{
+		Unused:             []string{"delete /users/{id}"},
+		Undocumented:       []string{"post /users"},
+		UndocumentedStatus: []string{"get /users/me 500"},
+	})
+	if !eq {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,32 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Validate checks the document for references that can not be resolved
+// such as security requirements of undeclared schemes or scopes
+// and any lint rules enabled on the document (see RequireSecurity).
+// All issues found are returned as a joined error.
+func (o *OpenAPI) Validate() error {
+	errs := make([]error, 0)
+	if len(o.Webhooks) > 0 && !o.is31() {
+		errs = append(errs, fmt.Errorf("webhooks require OpenAPI 3.1, the document is %v", o.Version))
+	}
+	for _, req := range o.Security {
+		errs = append(errs, o.validateSecurity(req, "security")...)
+	}
+
+	for _, k := range sortedKeys(o.Paths) {
+		r := o.Paths[k]
+		for _, req := range r.Security {
+			errs = append(errs, o.validateSecurity(req, r.method+" "+r.path)...)
+		}
+		if err := o.validateCoverage(r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	errs = append(errs, o.validateOperationIDs()...)
+	return errors.Join(errs...)
+}
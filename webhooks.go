@@ -0,0 +1,29 @@
+package openapi
+
+// GetWebhook returns the operation of the webhook with the name and method,
+// a new Route is created if the webhook was not found.
+// The Route describes the request the API sends to its consumers and the responses it expects,
+// it is built the same way as a Route of the Paths.
+// Webhooks are written in OpenAPI 3.1 documents, see SetSpecVersion.
+//
+//	doc.GetWebhook("orderShipped", "post").
+//		AddRequest(openapi.RequestBody{}.WithExample(Order{})).
+//		AddResponse(openapi.Response{Status: 200, Desc: "received"})
+func (o *OpenAPI) GetWebhook(name, method string) *Route {
+	if o.Webhooks == nil {
+		o.Webhooks = make(Router)
+	}
+	key := name + "|" + method
+	r, found := o.Webhooks[key]
+	if !found {
+		r = &Route{
+			path:   name,
+			method: method,
+			Params: make(Params),
+			doc:    o,
+			seq:    len(o.Webhooks) + 1,
+		}
+		o.Webhooks[key] = r
+	}
+	return r
+}
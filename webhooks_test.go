@@ -0,0 +1,62 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestGetWebhook(t *testing.T) {
+	type order struct {
+		ID string `json:"id"`
+	}
+	doc := New("", "", "")
+	if err := doc.SetSpecVersion("3.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	doc.GetWebhook("orderShipped", "post").
+		AddRequest(RequestBody{}.WithExample(order{ID: "1"})).
+		AddResponse(Response{Status: 200, Desc: "received"})
+	if r := doc.GetWebhook("orderShipped", "post"); r.Requests == nil {
+		t.Fatal("expected the existing webhook")
+	}
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	var out struct {
+		Webhooks map[string]map[string]json.RawMessage `json:"webhooks"`
+	}
+	b, err := doc.JSONWith(MarshalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	if eq, diff := trial.Equal(string(out.Webhooks["orderShipped"]["post"]),
+		`{"responses":{"200":{"description":"received"}},"requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/openapi.order"},"examples":{"openapi.order":{"value":{"id":"1"}}}}}}}`); !eq {
+		t.Error(diff)
+	}
+
+	// the webhooks are read back
+	doc2, err := NewFromJson(doc.JSON())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := doc2.Webhooks["orderShipped|post"]; r == nil || r.path != "orderShipped" || r.Responses[200].Desc != "received" {
+		t.Errorf("webhook not unmarshaled %+v", r)
+	}
+
+	// webhooks are not part of 3.0
+	if err := doc.SetSpecVersion("3.0.3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Validate(); err == nil {
+		t.Error("expected an error for webhooks in a 3.0 document")
+	}
+}
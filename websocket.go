@@ -0,0 +1,68 @@
+package openapi
+
+// wsExtension is the x-websocket extension of a websocket route, the messages are keyed by name.
+type wsExtension struct {
+	Client map[string]wsMessage `json:"client,omitempty"` // messages sent by the client
+	Server map[string]wsMessage `json:"server,omitempty"` // messages sent by the server
+}
+
+type wsMessage struct {
+	Schema  Schema `json:"schema"`
+	Example any    `json:"example,omitempty"`
+}
+
+// AddWebSocketRoute adds a GET operation that upgrades the connection to a websocket.
+// The operation has the upgrade request headers and a 101 Switching Protocols response,
+// the messages are documented with ClientMessage and ServerMessage in the x-websocket extension.
+//
+//	doc.AddWebSocketRoute("/ws/orders").
+//		ClientMessage("subscribe", Subscribe{Topic: "orders"}).
+//		ServerMessage("order", Order{ID: 1})
+func (o *OpenAPI) AddWebSocketRoute(path string) *Route {
+	r := o.GetRoute(path, "get").
+		HeaderParam("Upgrade", "websocket", "must be websocket").
+		HeaderParam("Connection", "Upgrade", "must be Upgrade").
+		HeaderParam("Sec-WebSocket-Version", "13", "the websocket protocol version").
+		AddResponse(Response{
+			Status: 101,
+			Desc:   "Switching Protocols",
+			Headers: map[string]Header{
+				"Upgrade":              {Schema: &Schema{Type: String, Enum: []any{"websocket"}}},
+				"Connection":           {Schema: &Schema{Type: String, Enum: []any{"Upgrade"}}},
+				"Sec-WebSocket-Accept": {Desc: "the accepted Sec-WebSocket-Key", Schema: &Schema{Type: String}},
+			},
+		})
+	if _, ok := r.Extensions["x-websocket"].(wsExtension); !ok {
+		r.SetExtension("websocket", wsExtension{})
+	}
+	return r
+}
+
+// ClientMessage documents a message sent by the client on a websocket route
+func (r *Route) ClientMessage(name string, example any) *Route {
+	return r.wsMessage(name, example, false)
+}
+
+// ServerMessage documents a message sent by the server on a websocket route
+func (r *Route) ServerMessage(name string, example any) *Route {
+	return r.wsMessage(name, example, true)
+}
+
+func (r *Route) wsMessage(name string, example any, server bool) *Route {
+	ext, _ := r.Extensions["x-websocket"].(wsExtension)
+	msgs := ext.Client
+	if server {
+		msgs = ext.Server
+	}
+	m := make(map[string]wsMessage, len(msgs)+1)
+	for k, v := range msgs {
+		m[k] = v
+	}
+	m[name] = wsMessage{Schema: buildSchema(example), Example: example}
+	if server {
+		ext.Server = m
+	} else {
+		ext.Client = m
+	}
+	return r.SetExtension("websocket", ext)
+}
@@ -0,0 +1,56 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hydronica/trial"
+)
+
+func TestAddWebSocketRoute(t *testing.T) {
+	type subscribe struct {
+		Topic string `json:"topic"`
+	}
+	doc := New("", "", "")
+	doc.AddWebSocketRoute("/ws").
+		ClientMessage("subscribe", subscribe{Topic: "orders"}).
+		ServerMessage("order", map[string]int{"id": 1}).
+		ServerMessage("ping", "ping")
+	if err := doc.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	r := doc.Paths["/ws|get"]
+	if eq, diff := trial.Equal(sortedKeys(r.Params), []string{"header|Connection", "header|Sec-WebSocket-Version", "header|Upgrade"}); !eq {
+		t.Error(diff)
+	}
+	if r.Responses[101].Desc != "Switching Protocols" {
+		t.Errorf("missing 101 response: %v", r.Responses)
+	}
+
+	b, err := json.Marshal(r.Extensions["x-websocket"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got any
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	eq, diff := trial.Equal(got, map[string]any{
+		"client": map[string]any{
+			"subscribe": map[string]any{
+				"schema":  map[string]any{"title": "openapi.subscribe", "type": "object", "properties": map[string]any{"topic": map[string]any{"type": "string"}}},
+				"example": map[string]any{"topic": "orders"},
+			},
+		},
+		"server": map[string]any{
+			"order": map[string]any{
+				"schema":  map[string]any{"title": "3369a00000000000", "type": "object", "properties": map[string]any{"id": map[string]any{"type": "integer"}}},
+				"example": map[string]any{"id": 1.0},
+			},
+			"ping": map[string]any{"schema": map[string]any{"type": "string"}, "example": "ping"},
+		},
+	})
+	if !eq {
+		t.Error(diff)
+	}
+}